@@ -0,0 +1,71 @@
+// The command line tool to compare two experiments saved by the executor and print the statistical comparison of
+// them: Mann-Whitney U and Welch's t-tests, bootstrap confidence intervals and effect sizes of the number of evaluations
+// to solve, the complexity of winners and the success rate.
+//
+// Usage:
+//	go run cmd/compare/main.go [-resamples 10000] [-confidence 0.95] [-seed 1] first_experiment second_experiment
+//
+// The experiment files are the ones saved by the executor in GOB format (e.g. ./out/xor/XOR.dat).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"log"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/experiments"
+)
+
+func main() {
+	var resamples = flag.Int("resamples", 10000, "The number of bootstrap resamples to estimate confidence intervals.")
+	var confidence = flag.Float64("confidence", 0.95, "The confidence level of bootstrap intervals.")
+	var seed = flag.Int64("seed", 1, "The seed of random numbers generator used by bootstrap.")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] first_experiment second_experiment\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *confidence <= 0 || *confidence >= 1 {
+		log.Fatalf("Wrong confidence level: %f", *confidence)
+	}
+	// do not print genome comments
+	neat.LogLevel = neat.LogLevelWarning
+
+	first, err := loadExperiment(flag.Arg(0))
+	if err != nil {
+		log.Fatal("Failed to read first experiment: ", err)
+	}
+	second, err := loadExperiment(flag.Arg(1))
+	if err != nil {
+		log.Fatal("Failed to read second experiment: ", err)
+	}
+
+	bootstrap := experiments.Bootstrap{Resamples:*resamples, Confidence:*confidence, Seed:*seed}
+	comparison := experiments.CompareExperiments(first, second, bootstrap)
+	if err = comparison.WriteText(os.Stdout); err != nil {
+		log.Fatal("Failed to write comparison: ", err)
+	}
+}
+
+// Loads experiment from the file, the path of file is used as experiment name if it was not saved
+func loadExperiment(path string) (*experiments.Experiment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	ex := experiments.Experiment{}
+	if err = ex.Read(file); err != nil {
+		return nil, err
+	}
+	if len(ex.Name) == 0 {
+		ex.Name = path
+	}
+	return &ex, nil
+}
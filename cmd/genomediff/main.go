@@ -0,0 +1,105 @@
+// The command line tool to compare two genomes and print the structured difference between them: matching, disjoint
+// and excess genes, added nodes and different traits. If context configuration provided, the compatibility distance
+// used for speciation will be printed as well.
+//
+// Usage:
+//	go run cmd/genomediff/main.go [-context ./data/xor.neat] [-id1 N] [-id2 M] [-json] first_genome second_genome
+//
+// The genome files may hold either single genome (e.g. winner genome dump) or whole population dump, in later case
+// the ID of genome to compare should be provided with -id1/-id2 flags.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"log"
+	"bufio"
+	"bytes"
+	"strings"
+	"errors"
+	"io/ioutil"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+func main() {
+	var context_path = flag.String("context", "", "The execution context configuration file to calculate compatibility.")
+	var id1 = flag.Int("id1", -1, "The ID of genome to read from the first file. The first genome found if not set.")
+	var id2 = flag.Int("id2", -1, "The ID of genome to read from the second file. The first genome found if not set.")
+	var as_json = flag.Bool("json", false, "The flag to print difference as JSON.")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] first_genome second_genome\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	// do not print genome comments
+	neat.LogLevel = neat.LogLevelWarning
+
+	var context *neat.NeatContext
+	if *context_path != "" {
+		configFile, err := os.Open(*context_path)
+		if err != nil {
+			log.Fatal("Failed to open context configuration file: ", err)
+		}
+		context = neat.LoadContext(configFile)
+	}
+
+	first, err := loadGenome(flag.Arg(0), *id1)
+	if err != nil {
+		log.Fatal("Failed to read first genome: ", err)
+	}
+	second, err := loadGenome(flag.Arg(1), *id2)
+	if err != nil {
+		log.Fatal("Failed to read second genome: ", err)
+	}
+
+	diff := first.Diff(second, context)
+	if *as_json {
+		err = diff.WriteJSON(os.Stdout)
+	} else {
+		err = diff.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Fatal("Failed to write genome difference: ", err)
+	}
+}
+
+// Loads genome with given ID from the file. If ID is negative the first genome found in the file will be loaded.
+func loadGenome(path string, id int) (*genetics.Genome, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// extract lines of requested genome
+	genome_buf := bytes.NewBufferString("")
+	found := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !found && strings.HasPrefix(line, "genomestart ") {
+			var g_id int
+			if _, err := fmt.Sscanf(line, "genomestart %d", &g_id); err != nil {
+				return nil, err
+			}
+			if id < 0 || id == g_id {
+				found, id = true, g_id
+			}
+		}
+		if found {
+			fmt.Fprintln(genome_buf, line)
+			if strings.HasPrefix(line, "genomeend ") {
+				break
+			}
+		}
+	}
+	if !found {
+		return nil, errors.New(fmt.Sprintf("Genome not found in file: %s", path))
+	}
+	return genetics.ReadGenome(genome_buf, id)
+}
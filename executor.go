@@ -2,16 +2,21 @@ package main
 
 import (
 	"os"
+	"io"
 	"time"
 	"fmt"
 	"log"
 	"flag"
+	"net"
+	"net/http"
 	"math/rand"
 	"github.com/yaricom/goNEAT/experiments"
 	"github.com/yaricom/goNEAT/neat"
 	"github.com/yaricom/goNEAT/neat/genetics"
+	"github.com/yaricom/goNEAT/neat/esp"
 	"github.com/yaricom/goNEAT/experiments/xor"
 	"github.com/yaricom/goNEAT/experiments/pole"
+	"github.com/yaricom/goNEAT/experiments/plot"
 )
 
 // The experiment runner boilerplate code
@@ -19,12 +24,32 @@ func main() {
 	var out_dir_path = flag.String("out", "./out", "The output directory to store results.")
 	var context_path = flag.String("context", "./data/xor.neat", "The execution context configuration file.")
 	var genome_path = flag.String("genome", "./data/xorstartgenes", "The seed genome to start with.")
-	var experiment_name = flag.String("experiment", "XOR", "The name of experiment to run. [XOR, cart_pole, cart_2pole_markov, cart_2pole_non-markov]")
+	var experiment_name = flag.String("experiment", "XOR", "The name of experiment to run. [XOR, cart_pole, cart_2pole_markov, cart_2pole_markov_ctrnn, cart_2pole_non-markov]")
 	var trials_count = flag.Int("trials", 0, "The numbar of trials for experiment. Overrides the one set in configuration.")
 	var log_level = flag.Int("log_level", -1, "The logger level to be used. Overrides the one set in configuration.")
+	var islands = flag.Int("islands", 1, "The number of islands (sub-populations) to evolve with migration between them. The population size divided among islands.")
+	var migration_interval = flag.Int("migration_interval", 10, "The number of generations between migrations of the best organisms among islands.")
+	var migrants = flag.Int("migrants", 5, "The number of the best organisms migrating from each island.")
+	var topology = flag.String("topology", "ring", "The topology of migration between islands. [ring, full]")
+	var esp_neurons = flag.Int("esp", 0, "The number of hidden neurons of networks evolved by Enforced Sub-Populations (ESP) method instead of NEAT, NEAT is used if zero.")
+	var esp_size = flag.Int("esp_size", 40, "The number of neurons in each sub-population of ESP.")
+	var esp_trials = flag.Int("esp_trials", 10, "The number of networks each neuron participates in per generation of ESP.")
+	var format = flag.String("format", "gob", "The format of saved experiment data. [gob, csv, json]")
+	var genealogy = flag.Bool("genealogy", false, "The flag to record genealogy of organisms and save it as GraphViz DOT file per trial.")
+	var species = flag.Bool("species", false, "The flag to record history of species and save it as CSV (or JSON with -format json) file per trial.")
+	var metrics_addr = flag.String("metrics_addr", "", "The address (e.g. ':9090') to serve live metrics of experiment at '/metrics' (Prometheus) and '/debug/vars' (expvar), no metrics if empty.")
+	var plot_format = flag.String("plot", "", "The image format of fitness, complexity and species charts to save into output directory, no charts if empty. [svg, png]")
 
 	flag.Parse()
 
+	if *format != "gob" && *format != "csv" && *format != "json" {
+		log.Fatalf("Unsupported format of experiment data: %s", *format)
+	}
+	chart_format, err := plot.FormatByName(*plot_format)
+	if *plot_format != "" && err != nil {
+		log.Fatal(err)
+	}
+
 	// Seed the random-number generator with current time so that
 	// the numbers will be different every time we run.
 	rand.Seed(time.Now().Unix())
@@ -77,11 +102,27 @@ func main() {
 	experiment := experiments.Experiment{
 		Id:0,
 		Trials:make(experiments.Trials, context.NumRuns),
+		TrackGenealogy:*genealogy,
+		TrackSpecies:*species,
+	}
+	if *metrics_addr != "" {
+		listener, err := net.Listen("tcp", *metrics_addr)
+		if err != nil {
+			log.Fatal("Failed to start metrics server: ", err)
+		}
+		monitor := experiments.NewMonitor()
+		experiment.AddObserver(monitor)
+		go http.Serve(listener, monitor.Handler())
+		fmt.Printf(">>> Serving metrics at: http://%s/metrics\n", listener.Addr())
 	}
 	var generationEvaluator experiments.GenerationEvaluator
+	// the double pole-balancing winners are named with fitness as they were before
+	winner_name, winner_fitness := "pole2_winner", true
 	if *experiment_name == "XOR" {
 		generationEvaluator = xor.XORGenerationEvaluator{OutputPath:out_dir}
+		winner_name, winner_fitness = "xor_winner", false
 	} else if *experiment_name == "cart_pole" {
+		winner_name, winner_fitness = "pole1_winner", false
 		generationEvaluator = pole.CartPoleGenerationEvaluator{
 			OutputPath:out_dir,
 			WinBalancingSteps:500000,
@@ -93,6 +134,13 @@ func main() {
 			Markov:true,
 			ActionType:experiments.ContinuousAction,
 		}
+	} else if *experiment_name == "cart_2pole_markov_ctrnn" {
+		generationEvaluator = pole.CartDoublePoleGenerationEvaluator{
+			OutputPath:out_dir,
+			Markov:true,
+			ActionType:experiments.ContinuousAction,
+			CTRNNTimeStep:0.01,
+		}
 	} else if *experiment_name == "cart_2pole_non-markov" {
 		generationEvaluator = pole.CartDoublePoleGenerationEvaluator{
 			OutputPath:out_dir,
@@ -100,8 +148,39 @@ func main() {
 			ActionType:experiments.ContinuousAction,
 		}
 	}
+	experiment.AddObserver(experiments.PopulationDumper{
+		OutputPath:out_dir,
+		PrintEvery:context.PrintEvery,
+		WinnerName:winner_name,
+		WinnerFitness:winner_fitness,
+	})
 
-	err = experiment.Execute(context, start_genome, generationEvaluator)
+	if *esp_neurons > 0 {
+		esp_options := esp.Options{
+			HiddenNeurons:*esp_neurons,
+			SubPopSize:*esp_size,
+			TrialsPerNeuron:*esp_trials,
+			MutationRate:0.4,
+			MutationPower:0.3,
+			BurstStagnation:20,
+			Recurrent:true,
+		}
+		err = experiment.ExecuteESP(context, start_genome, generationEvaluator, esp_options)
+	} else if *islands > 1 {
+		migration_topology, topology_err := experiments.MigrationTopologyByName(*topology)
+		if topology_err != nil {
+			log.Fatal("Failed to create island model: ", topology_err)
+		}
+		island_model := experiments.IslandModel{
+			Islands:*islands,
+			MigrationInterval:*migration_interval,
+			Migrants:*migrants,
+			Topology:migration_topology,
+		}
+		err = experiment.ExecuteIslands(context, start_genome, generationEvaluator, island_model)
+	} else {
+		err = experiment.Execute(context, start_genome, generationEvaluator)
+	}
 	if err != nil {
 		log.Fatal("Failed to perform XOR experiment: ", err)
 	}
@@ -113,12 +192,76 @@ func main() {
 	fmt.Printf(">>> Configuration file: %s\n", *context_path)
 
 	// Save experiment data
-	expResPath := fmt.Sprintf("%s/%s.dat", out_dir, *experiment_name)
-	expResFile, err := os.Create(expResPath)
-	if err == nil {
-		err = experiment.Write(expResFile)
-	}
+	err = writeExperiment(&experiment, out_dir, *experiment_name, *format)
 	if err != nil {
 		log.Fatal("Failed to save experiment results", err)
 	}
+
+	if *genealogy {
+		for _, t := range experiment.Trials {
+			trial_dir := experiments.OutDirForTrial(out_dir, t.Id)
+			err = writeFile(fmt.Sprintf("%s/genealogy.dot", trial_dir), t.WriteGenealogyDOT)
+			if err == nil && t.Solved() {
+				err = writeFile(fmt.Sprintf("%s/winner_ancestry.dot", trial_dir), t.WriteWinnerAncestryDOT)
+			}
+			if err != nil {
+				log.Fatal("Failed to save genealogy", err)
+			}
+		}
+	}
+
+	if *species {
+		for _, t := range experiment.Trials {
+			if t.SpeciesHistory == nil {
+				// not tracked by island model and ESP
+				continue
+			}
+			trial_dir := experiments.OutDirForTrial(out_dir, t.Id)
+			if *format == "json" {
+				err = writeFile(fmt.Sprintf("%s/species_history.json", trial_dir), t.SpeciesHistory.WriteJSON)
+			} else {
+				err = writeFile(fmt.Sprintf("%s/species_history.csv", trial_dir), t.SpeciesHistory.WriteCSV)
+			}
+			if err != nil {
+				log.Fatal("Failed to save species history", err)
+			}
+		}
+	}
+
+	if *plot_format != "" {
+		if err = plot.WriteExperiment(&experiment, out_dir, chart_format); err != nil {
+			log.Fatal("Failed to save charts", err)
+		}
+	}
+}
+
+// Creates file at given path and writes data into it with provided writer function
+func writeFile(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return write(file)
+}
+
+// Writes experiment data in given format (gob, csv or json) into the file named after experiment in output directory
+func writeExperiment(experiment *experiments.Experiment, out_dir, name, format string) error {
+	ext := format
+	if format == "gob" {
+		ext = "dat"
+	}
+	file, err := os.Create(fmt.Sprintf("%s/%s.%s", out_dir, name, ext))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	switch format {
+	case "csv":
+		return experiment.WriteCSV(file)
+	case "json":
+		return experiment.WriteJSON(file)
+	default:
+		return experiment.Write(file)
+	}
 }
\ No newline at end of file
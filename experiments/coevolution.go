@@ -0,0 +1,339 @@
+package experiments
+
+import (
+	"fmt"
+	"time"
+	"sort"
+	"errors"
+	"math/rand"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The evaluator of competition between organisms of two coevolving populations, e.g. the game between two bots
+type CompetitionEvaluator interface {
+	// Plays the game between host and parasite organisms and returns the score of host in range [0, 1], where 1 means
+	// that host won, 0 that parasite won and 0.5 a draw. The score of parasite is one minus the score of host. The
+	// same organism competes with several opponents, thus its network should be flushed before each game.
+	Compete(host, parasite *genetics.Organism) (float64, error)
+}
+
+// The method to sample opponents from the previous generation of the opposite population
+type OpponentSampling byte
+
+// The supported opponents sampling methods
+const (
+	// The opponents are sampled randomly
+	RandomSampling OpponentSampling = iota
+	// The most fit organisms are sampled
+	ChampionSampling
+	// The opponents are sampled one by one preferring the ones which beat organisms beaten by few opponents sampled
+	// before, thus the sample covers diverse weaknesses of the population
+	SharedSampling
+)
+
+// Returns opponents sampling method by its name: "random", "champions" or "shared"
+func OpponentSamplingByName(name string) (OpponentSampling, error) {
+	switch name {
+	case "random":
+		return RandomSampling, nil
+	case "champions":
+		return ChampionSampling, nil
+	case "shared":
+		return SharedSampling, nil
+	default:
+		return 0, errors.New(fmt.Sprintf("Unknown opponents sampling: %s", name))
+	}
+}
+
+// The model of competitive coevolution where organisms of host and parasite populations are evaluated in competition
+// with opponents sampled from each other.
+type CoevolutionModel struct {
+	// The number of opponents sampled from the previous generation of the opposite population
+	SampleSize        int
+	// The method to sample opponents from the previous generation
+	Sampling          OpponentSampling
+	// The number of the last generations which champions of each population are kept in its hall of fame, zero to
+	// not keep
+	HallOfFameSize    int
+	// The number of opponents sampled from the hall of fame of the opposite population in addition to SampleSize
+	HallOfFameSamples int
+	// If set, the competitive fitness sharing is used: the score against each opponent is divided by the total score
+	// of all organisms against it, thus beating opponents which few others beat is rewarded more. Otherwise, the
+	// fitness is the average score against all opponents.
+	FitnessSharing    bool
+}
+
+// Checks that coevolution model parameters are valid for given context
+func (m CoevolutionModel) validate(context *neat.NeatContext) error {
+	if m.SampleSize <= 0 || m.SampleSize > context.PopSize {
+		return errors.New(fmt.Sprintf("Wrong opponents sample size: %d, population size: %d",
+			m.SampleSize, context.PopSize))
+	}
+	if m.Sampling > SharedSampling {
+		return errors.New(fmt.Sprintf("Unsupported opponents sampling: %d", m.Sampling))
+	}
+	if m.HallOfFameSize < 0 || m.HallOfFameSamples < 0 || m.HallOfFameSamples > m.HallOfFameSize {
+		return errors.New(fmt.Sprintf("Wrong hall of fame size: %d or samples: %d",
+			m.HallOfFameSize, m.HallOfFameSamples))
+	}
+	return nil
+}
+
+// One of two coevolving populations with the results of its last evaluated generation
+type coevolutionSide struct {
+	// The population of this side
+	pop        *genetics.Population
+	// The context of population
+	context    *neat.NeatContext
+	// The hall of fame of population, nil if not kept
+	hallOfFame *championsArchive
+	// The organisms of the last evaluated generation
+	evaluated  []*genetics.Organism
+	// The scores of evaluated organisms against each opponent they competed with
+	scores     [][]float64
+}
+
+// Creates new side of coevolution with population spawned from the start genome
+func newCoevolutionSide(start_genome *genetics.Genome, context *neat.NeatContext, model CoevolutionModel) (*coevolutionSide, error) {
+	side := &coevolutionSide{context:copyContext(context)}
+	var err error
+	if side.pop, err = spawnPopulation(start_genome, side.context); err != nil {
+		return nil, err
+	}
+	if model.HallOfFameSize > 0 {
+		side.hallOfFame = &championsArchive{size:model.HallOfFameSize}
+	}
+	return side, nil
+}
+
+// Samples organisms of this side to be opponents of the opposite population in the next generation. The opponents are
+// sampled from the last evaluated generation, or randomly from the current population if nothing evaluated yet.
+func (s *coevolutionSide) sample(model CoevolutionModel) []*genetics.Organism {
+	var opponents []*genetics.Organism
+	if s.evaluated == nil {
+		opponents = sampleRandom(s.pop.Organisms, model.SampleSize)
+	} else {
+		switch model.Sampling {
+		case RandomSampling:
+			opponents = sampleRandom(s.evaluated, model.SampleSize)
+		case ChampionSampling:
+			opponents = make([]*genetics.Organism, len(s.evaluated))
+			copy(opponents, s.evaluated)
+			sort.SliceStable(opponents, func(i, j int) bool {
+				return opponents[i].Fitness > opponents[j].Fitness
+			})
+			if len(opponents) > model.SampleSize {
+				opponents = opponents[:model.SampleSize]
+			}
+		case SharedSampling:
+			opponents = s.sampleShared(model.SampleSize)
+		}
+	}
+
+	// add famous organisms of previous generations
+	if s.hallOfFame != nil && model.HallOfFameSamples > 0 {
+		for _, i := range rand.Perm(len(s.hallOfFame.members)) {
+			if len(opponents) >= model.SampleSize + model.HallOfFameSamples {
+				break
+			}
+			m := s.hallOfFame.members[i]
+			opponents = append(opponents, genetics.NewOrganism(m.Fitness, m.Genome, m.Generation))
+		}
+	}
+	return opponents
+}
+
+// Samples evaluated organisms one by one choosing each time the one which beat the most of opponents, where the victory
+// over opponent is weighted down by the number of organisms sampled before which beat it as well
+func (s *coevolutionSide) sampleShared(size int) []*genetics.Organism {
+	beaten := make([]float64, len(s.scores[0]))
+	sampled := make([]bool, len(s.evaluated))
+	opponents := make([]*genetics.Organism, 0, size)
+	for len(opponents) < size && len(opponents) < len(s.evaluated) {
+		best, best_value := -1, -1.0
+		for i, scores := range s.scores {
+			if sampled[i] {
+				continue
+			}
+			value := 0.0
+			for j, score := range scores {
+				value += score / (1.0 + beaten[j])
+			}
+			if value > best_value {
+				best, best_value = i, value
+			}
+		}
+		sampled[best] = true
+		for j, score := range s.scores[best] {
+			beaten[j] += score
+		}
+		opponents = append(opponents, s.evaluated[best])
+	}
+	return opponents
+}
+
+// The hall of fame of one side keeping the champions of its last generations. The competitive fitness is relative to
+// the opponents of generation, thus the fitness of champions of different generations is not comparable and the
+// oldest champion is evicted when archive is full, instead of the least fit one.
+type championsArchive struct {
+	// The maximal number of champions kept
+	size    int
+	// The champions ordered by generation, the oldest first
+	members []*genetics.HallOfFameMember
+}
+
+// Adds the champion of evaluated population to the archive evicting the oldest one if full
+func (a *championsArchive) add(pop *genetics.Population, generation int) {
+	var champion *genetics.Organism
+	for _, org := range pop.Organisms {
+		if champion == nil || org.Fitness > champion.Fitness {
+			champion = org
+		}
+	}
+	if champion == nil || a.size <= 0 {
+		return
+	}
+	if len(a.members) >= a.size {
+		a.members = a.members[1:]
+	}
+	// the genome of organism is not changed by reproduction, thus can be kept as is
+	member := &genetics.HallOfFameMember{
+		Genome:champion.Genotype,
+		Fitness:champion.Fitness,
+		Generation:generation,
+	}
+	if champion.Species != nil {
+		member.SpeciesId = champion.Species.Id
+	}
+	a.members = append(a.members, member)
+}
+
+// Returns given number of organisms randomly chosen from provided ones
+func sampleRandom(organisms []*genetics.Organism, size int) []*genetics.Organism {
+	if size > len(organisms) {
+		size = len(organisms)
+	}
+	sample := make([]*genetics.Organism, size)
+	for i, j := range rand.Perm(len(organisms))[:size] {
+		sample[i] = organisms[j]
+	}
+	return sample
+}
+
+// Evaluates organisms of this side in competition with given opponents and assigns their fitness. The as_host flag
+// tells whether organisms of this side play as hosts or as parasites.
+func (s *coevolutionSide) evaluate(opponents []*genetics.Organism, as_host bool, evaluator CompetitionEvaluator, model CoevolutionModel) error {
+	s.scores = make([][]float64, len(s.pop.Organisms))
+	totals := make([]float64, len(opponents))
+	for i, org := range s.pop.Organisms {
+		s.scores[i] = make([]float64, len(opponents))
+		for j, opponent := range opponents {
+			var score float64
+			var err error
+			if as_host {
+				score, err = evaluator.Compete(org, opponent)
+			} else {
+				score, err = evaluator.Compete(opponent, org)
+				score = 1.0 - score
+			}
+			if err != nil {
+				return err
+			}
+			s.scores[i][j] = score
+			totals[j] += score
+		}
+	}
+
+	for i, org := range s.pop.Organisms {
+		org.Fitness = 0.0
+		for j, score := range s.scores[i] {
+			if !model.FitnessSharing {
+				org.Fitness += score / float64(len(opponents))
+			} else if totals[j] > 0 {
+				org.Fitness += score / totals[j]
+			}
+		}
+	}
+	s.evaluated = make([]*genetics.Organism, len(s.pop.Organisms))
+	copy(s.evaluated, s.pop.Organisms)
+	return nil
+}
+
+// Collects statistics of the evaluated generation of this side and updates its hall of fame
+func (s *coevolutionSide) generation(generation_id, trial_id int) Generation {
+	epoch := Generation{
+		Id:generation_id,
+		TrialId:trial_id,
+	}
+	epoch.FillPopulationStatistics(s.pop)
+	epoch.Executed = time.Now()
+	if s.hallOfFame != nil {
+		s.hallOfFame.add(s.pop, generation_id)
+	}
+	return epoch
+}
+
+// Executes competitive coevolution of host and parasite populations spawned from given start genomes with population
+// size of the context each. In every generation hosts compete with opponents sampled from the previous generation and
+// the hall of fame of parasites and vice versa. The statistics of hosts are stored into trial's Generations while the
+// ones of parasites into trial's Parasites. As there is no absolute measure of success, each trial runs for
+// NumGenerations generations, the observers and other termination criteria are not supported.
+func (ex *Experiment) ExecuteCoevolution(context *neat.NeatContext, host_genome, parasite_genome *genetics.Genome, evaluator CompetitionEvaluator, model CoevolutionModel) (err error) {
+	if err = model.validate(context); err != nil {
+		return err
+	}
+	if ex.Trials == nil {
+		ex.Trials = make(Trials, context.NumRuns)
+	}
+
+	for run := 0; run < context.NumRuns; run++ {
+		var hosts, parasites *coevolutionSide
+		neat.InfoLog("\n>>>>> Hosts")
+		if hosts, err = newCoevolutionSide(host_genome, context, model); err != nil {
+			return err
+		}
+		neat.InfoLog("\n>>>>> Parasites")
+		if parasites, err = newCoevolutionSide(parasite_genome, context, model); err != nil {
+			return err
+		}
+
+		// start new trial
+		trial := Trial{
+			Id:run,
+			Termination:TerminationGenerations,
+		}
+		for generation_id := 0; generation_id < context.NumGenerations; generation_id++ {
+			neat.InfoLog(fmt.Sprintf(">>>>> Generation:%3d\tRun: %d\n", generation_id, run))
+			// sample opponents before evaluation, thus both sides compete with the previous generation
+			host_opponents := parasites.sample(model)
+			parasite_opponents := hosts.sample(model)
+			if err = hosts.evaluate(host_opponents, true, evaluator, model); err != nil {
+				neat.InfoLog(fmt.Sprintf("!!!!! Generation [%d] evaluation of hosts failed !!!!!\n", generation_id))
+				return err
+			}
+			if err = parasites.evaluate(parasite_opponents, false, evaluator, model); err != nil {
+				neat.InfoLog(fmt.Sprintf("!!!!! Generation [%d] evaluation of parasites failed !!!!!\n", generation_id))
+				return err
+			}
+			host_generation := hosts.generation(generation_id, run)
+			parasite_generation := parasites.generation(generation_id, run)
+			trial.Generations = append(trial.Generations, host_generation)
+			trial.Parasites = append(trial.Parasites, parasite_generation)
+			neat.InfoLog(fmt.Sprintf(">>>>> Best fitness of hosts: %f, parasites: %f\n",
+				host_generation.Best.Fitness, parasite_generation.Best.Fitness))
+
+			// Move both populations to the next epoch
+			neat.DebugLog(">>>>> start next generation")
+			for _, side := range []*coevolutionSide{hosts, parasites} {
+				if _, err = side.pop.Epoch(generation_id + 1, side.context); err != nil {
+					neat.InfoLog(fmt.Sprintf("!!!!! Generation [%d] reproduction failed !!!!!\n", generation_id))
+					return err
+				}
+			}
+		}
+		// store trial into experiment
+		ex.Trials[run] = trial
+	}
+	return nil
+}
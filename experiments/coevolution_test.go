@@ -0,0 +1,212 @@
+package experiments
+
+import (
+	"bytes"
+	"testing"
+	"math/rand"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The game where organism with greater total weight of genes wins
+type weightGame struct {
+	// The number of games played
+	played int
+}
+
+func (g *weightGame) Compete(host, parasite *genetics.Organism) (float64, error) {
+	g.played++
+	host_weight, parasite_weight := totalWeight(host), totalWeight(parasite)
+	if host_weight > parasite_weight {
+		return 1.0, nil
+	} else if host_weight < parasite_weight {
+		return 0.0, nil
+	}
+	return 0.5, nil
+}
+
+func totalWeight(org *genetics.Organism) float64 {
+	weight := 0.0
+	for _, gene := range org.Genotype.Genes {
+		weight += gene.Link.Weight
+	}
+	return weight
+}
+
+// The game with predefined scores of hosts against parasites by their genome IDs
+type scoresGame map[[2]int]float64
+
+func (g scoresGame) Compete(host, parasite *genetics.Organism) (float64, error) {
+	return g[[2]int{host.Genotype.Id, parasite.Genotype.Id}], nil
+}
+
+func buildTestOrganisms(ids ...int) []*genetics.Organism {
+	orgs := make([]*genetics.Organism, len(ids))
+	for i, id := range ids {
+		orgs[i] = genetics.NewOrganism(0.0, buildTestGenome(id), 0)
+	}
+	return orgs
+}
+
+func TestExperiment_ExecuteCoevolution(t *testing.T) {
+	models := []CoevolutionModel{
+		{SampleSize:4, Sampling:RandomSampling},
+		{SampleSize:4, Sampling:ChampionSampling, HallOfFameSize:3, HallOfFameSamples:2},
+		{SampleSize:4, Sampling:SharedSampling, HallOfFameSize:3, HallOfFameSamples:2, FitnessSharing:true},
+	}
+	for _, model := range models {
+		rand.Seed(42)
+		context := observerTestContext()
+		neat.LogLevel = neat.LogLevelWarning
+
+		game := weightGame{}
+		ex := Experiment{}
+		err := ex.ExecuteCoevolution(context, buildTestGenome(1), buildTestGenome(2), &game, model)
+		if err != nil {
+			t.Fatal(model, err)
+		}
+		if len(ex.Trials) != context.NumRuns {
+			t.Fatal("Wrong number of trials", model, len(ex.Trials))
+		}
+		for _, trial := range ex.Trials {
+			if len(trial.Generations) != context.NumGenerations || len(trial.Parasites) != context.NumGenerations {
+				t.Error("Wrong number of generations", model, len(trial.Generations), len(trial.Parasites))
+			}
+			if trial.Termination != TerminationGenerations {
+				t.Error("Wrong termination", model, trial.Termination)
+			}
+			for _, epoch := range trial.Parasites {
+				if epoch.Metrics.Organisms != context.PopSize || epoch.Best == nil {
+					t.Error("Wrong parasites generation", model, epoch.Id, epoch.Metrics.Organisms)
+				}
+			}
+		}
+		// both populations played with all sampled opponents in each generation
+		min_played := context.NumRuns * context.NumGenerations * 2 * context.PopSize * model.SampleSize
+		if game.played < min_played {
+			t.Error("Wrong number of games", model, game.played)
+		}
+
+		// the statistics of parasites are persisted
+		var buff bytes.Buffer
+		if err = ex.Write(&buff); err != nil {
+			t.Fatal(err)
+		}
+		read := Experiment{}
+		if err = read.Read(&buff); err != nil {
+			t.Fatal(err)
+		}
+		if len(read.Trials[0].Parasites) != context.NumGenerations {
+			t.Error("Wrong restored parasites generations", model, len(read.Trials[0].Parasites))
+		}
+	}
+}
+
+func TestExperiment_ExecuteCoevolutionInvalid(t *testing.T) {
+	models := []CoevolutionModel{
+		{SampleSize:0},
+		{SampleSize:41},
+		{SampleSize:4, Sampling:OpponentSampling(10)},
+		{SampleSize:4, HallOfFameSize:2, HallOfFameSamples:3},
+	}
+	for i, model := range models {
+		ex := Experiment{}
+		err := ex.ExecuteCoevolution(observerTestContext(), buildTestGenome(1), buildTestGenome(2), &weightGame{}, model)
+		if err == nil {
+			t.Error("Invalid coevolution model accepted", i)
+		}
+	}
+}
+
+func TestCoevolutionSide_evaluate(t *testing.T) {
+	// the first host beats both parasites, the second one only the first parasite
+	game := scoresGame{{1, 10}:1.0, {1, 11}:1.0, {2, 10}:1.0}
+	parasites := buildTestOrganisms(10, 11)
+	hosts := &coevolutionSide{pop:&genetics.Population{Organisms:buildTestOrganisms(1, 2, 3)}}
+
+	err := hosts.evaluate(parasites, true, game, CoevolutionModel{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, fitness := range []float64{1.0, 0.5, 0.0} {
+		if org := hosts.pop.Organisms[i]; org.Fitness != fitness {
+			t.Error("Wrong average score", i, org.Fitness)
+		}
+	}
+	// the victory over the first parasite is shared among two hosts
+	err = hosts.evaluate(parasites, true, game, CoevolutionModel{FitnessSharing:true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, fitness := range []float64{1.5, 0.5, 0.0} {
+		if org := hosts.pop.Organisms[i]; org.Fitness != fitness {
+			t.Error("Wrong shared fitness", i, org.Fitness)
+		}
+	}
+
+	// the parasites score is the opposite to the hosts one
+	parasite_side := &coevolutionSide{pop:&genetics.Population{Organisms:parasites}}
+	err = parasite_side.evaluate(buildTestOrganisms(1, 2), false, game, CoevolutionModel{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, fitness := range []float64{0.0, 0.5} {
+		if org := parasite_side.pop.Organisms[i]; org.Fitness != fitness {
+			t.Error("Wrong parasite score", i, org.Fitness)
+		}
+	}
+}
+
+func TestCoevolutionSide_sample(t *testing.T) {
+	side := &coevolutionSide{
+		evaluated:buildTestOrganisms(1, 2, 3),
+		scores:[][]float64{
+			{1.0, 1.0, 1.0, 0.0},
+			{1.0, 1.0, 0.0, 0.0},
+			{0.0, 0.0, 1.0, 1.0},
+		},
+	}
+	for i, org := range side.evaluated {
+		org.Fitness = float64(3 - i)
+	}
+
+	// the shared sample prefers organism beating opponent which nobody else beats
+	sample := side.sample(CoevolutionModel{SampleSize:2, Sampling:SharedSampling})
+	if len(sample) != 2 || sample[0].Genotype.Id != 1 || sample[1].Genotype.Id != 3 {
+		t.Error("Wrong shared sample", sample)
+	}
+	sample = side.sample(CoevolutionModel{SampleSize:2, Sampling:ChampionSampling})
+	if len(sample) != 2 || sample[0].Genotype.Id != 1 || sample[1].Genotype.Id != 2 {
+		t.Error("Wrong champions sample", sample)
+	}
+
+	// the famous organisms are sampled in addition
+	side.hallOfFame = &championsArchive{size:2}
+	side.hallOfFame.members = append(side.hallOfFame.members, &genetics.HallOfFameMember{Genome:buildTestGenome(7)})
+	sample = side.sample(CoevolutionModel{SampleSize:2, Sampling:RandomSampling, HallOfFameSamples:2})
+	if len(sample) != 3 || sample[2].Genotype.Id != 7 {
+		t.Error("Wrong sample with hall of fame", sample)
+	}
+}
+
+func TestChampionsArchive_add(t *testing.T) {
+	archive := &championsArchive{size:2}
+	for generation, fitness := range []float64{1.0, 0.5, 0.25} {
+		orgs := buildTestOrganisms(generation * 2 + 1, generation * 2 + 2)
+		sp := genetics.NewSpecies(generation + 1)
+		for _, org := range orgs {
+			org.Species = sp
+		}
+		orgs[1].Fitness = fitness
+		archive.add(&genetics.Population{Organisms:orgs}, generation)
+	}
+	// the champions of the last generations are kept, even if less fit than the evicted one
+	if len(archive.members) != 2 {
+		t.Fatal("Wrong number of champions", len(archive.members))
+	}
+	for i, m := range archive.members {
+		if m.Generation != i + 1 || m.Genome.Id != (i + 1) * 2 + 2 {
+			t.Error("Wrong champion", i, m.Generation, m.Genome.Id)
+		}
+	}
+}
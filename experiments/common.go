@@ -2,6 +2,7 @@
 package experiments
 
 import (
+	"errors"
 	"github.com/yaricom/goNEAT/neat/genetics"
 	"github.com/yaricom/goNEAT/neat"
 	"fmt"
@@ -24,10 +25,64 @@ const (
 // The interface describing evaluator for one generation of evolution.
 type GenerationEvaluator interface {
 	// Invoked to evaluate one generation of population of organisms within given
-	// execution context.
+	// execution context. The evaluator advances population to the next epoch if winner was not found.
 	GenerationEvaluate(pop *genetics.Population, epoch *Generation, context *neat.NeatContext) (err error)
 }
 
+// The interface describing evaluator for one generation of evolution, which leaves advancing of population to the next
+// epoch to the experiment execution. It is used by the experiment execution instead of GenerationEvaluator when
+// implemented, and it is required by the island model and ESP, where the epochs are driven by the execution.
+type EpochEvaluator interface {
+	// Invoked to evaluate organisms of population and to fill statistics of given generation within given
+	// execution context. The evaluator must not advance population to the next epoch.
+	EpochEvaluate(pop *genetics.Population, epoch *Generation, context *neat.NeatContext) (err error)
+}
+
+// Advances population to the next epoch if evaluated generation was not solved. It helps to implement
+// GenerationEvaluator by EpochEvaluator.
+func AdvanceEpoch(pop *genetics.Population, epoch *Generation, context *neat.NeatContext) (err error) {
+	if !epoch.Solved {
+		// Move to the next epoch if failed to find winner
+		neat.DebugLog(">>>>> start next generation")
+		_, err = pop.Epoch(epoch.Id + 1, context)
+	}
+	return err
+}
+
+// The function evaluating given organisms by setting fitness, error and winner flag of each organism. It adapts the
+// evaluation of organisms (e.g. by remote workers or external processes) to the EpochEvaluator and GenerationEvaluator
+// which can be used with experiment execution.
+type OrganismsEvaluator func(organisms []*genetics.Organism) error
+
+// Evaluates organisms of given population by this function and collects statistics of the epoch
+func (f OrganismsEvaluator) EpochEvaluate(pop *genetics.Population, epoch *Generation, context *neat.NeatContext) error {
+	if err := f(pop.Organisms); err != nil {
+		return err
+	}
+
+	// Fill statistics about current epoch
+	epoch.FillWinnerStatistics(pop, context.PopSize)
+	epoch.FillPopulationStatistics(pop)
+	return nil
+}
+
+// Evaluates organisms of given population by this function, collects statistics of the epoch and advances population
+// to the next epoch if winner not found
+func (f OrganismsEvaluator) GenerationEvaluate(pop *genetics.Population, epoch *Generation, context *neat.NeatContext) error {
+	if err := f.EpochEvaluate(pop, epoch, context); err != nil {
+		return err
+	}
+	return AdvanceEpoch(pop, epoch, context)
+}
+
+// Returns epoch evaluator implemented by given executor or error if it is not implemented
+func epochEvaluator(executor interface{}) (EpochEvaluator, error) {
+	if evaluator, ok := executor.(EpochEvaluator); ok {
+		return evaluator, nil
+	}
+	return nil, errors.New(fmt.Sprintf("The executor %T does not implement EpochEvaluator", executor))
+}
+
 // The interface to describe trial lifecycle observer interested to receive lifecycle notifications
 type TrialRunObserver interface {
 	// Invoked to notify that new trial run just started before any epoch evaluation in that trial run
@@ -35,59 +90,126 @@ type TrialRunObserver interface {
 }
 
 
-// The Experiment execution entry point
+// The Experiment execution entry point. The executor must implement EpochEvaluator or GenerationEvaluator. With the
+// latter the population is advanced to the next epoch by the evaluator, thus the observers receive population of the
+// next epoch when generation evaluated and not solved.
 func (ex *Experiment) Execute(context *neat.NeatContext, start_genome *genetics.Genome, executor interface{}) (err error) {
+	if err = validateTermination(context); err != nil {
+		return err
+	}
 	if ex.Trials == nil {
 		ex.Trials = make(Trials, context.NumRuns)
 	}
 
 	var pop *genetics.Population
 	for run := 0; run < context.NumRuns; run++ {
-		neat.InfoLog("\n>>>>> Spawning new population ")
-		pop, err = genetics.NewPopulation(start_genome, context)
-		if err != nil {
-			neat.InfoLog("Failed to spawn new population from start genome")
-			return err
-		} else {
-			neat.InfoLog("OK <<<<<")
-		}
-		neat.InfoLog(">>>>> Verifying spawned population ")
-		_, err = pop.Verify()
+		// the context can be changed during trial run (e.g. adaptation of operators probabilities), thus each
+		// trial starts with its own copy of it
+		run_context := copyContext(context)
+
+		pop, err = spawnPopulation(start_genome, run_context)
 		if err != nil {
-			neat.ErrorLog("\n!!!!! Population verification failed !!!!!")
 			return err
-		} else {
-			neat.InfoLog("OK <<<<<")
 		}
 
 		// start new trial
 		trial := Trial {
 			Id:run,
+			RestartPolicy:run_context.RestartPolicy,
+		}
+		if ex.TrackGenealogy {
+			pop.Genealogy = genetics.NewGenealogy()
+			trial.Genealogy = pop.Genealogy
+		}
+		if ex.TrackSpecies {
+			pop.SpeciesHistory = genetics.NewSpeciesHistory()
+			trial.SpeciesHistory = pop.SpeciesHistory
 		}
 
 		if trial_observer, ok := executor.(TrialRunObserver); ok {
 			trial_observer.TrialRunStarted(&trial) // optional
 		}
+		lifecycle := newTrialLifecycle(ex.observers, &trial, true)
+		if err = lifecycle.trialStarted(pop); err != nil {
+			return err
+		}
 
-		epoch_evaluator := executor.(GenerationEvaluator) // mandatory
+		// the epochs are driven by the execution if supported by evaluator, otherwise evaluator advances population
+		epoch_evaluator, epoch_driven := executor.(EpochEvaluator)
+		var generation_evaluator GenerationEvaluator
+		if !epoch_driven {
+			generation_evaluator = executor.(GenerationEvaluator) // mandatory
+		}
+		criteria := newTerminationCriteria(run_context)
 
-		for generation_id := 0; generation_id < context.NumGenerations; generation_id++ {
+		for generation_id := 0; generation_id < run_context.NumGenerations && !lifecycle.stopped; generation_id++ {
 			neat.InfoLog(fmt.Sprintf(">>>>> Generation:%3d\tRun: %d\n", generation_id, run))
 			generation := Generation{
 				Id:generation_id,
 				TrialId:run,
 			}
-			err = epoch_evaluator.GenerationEvaluate(pop, &generation, context)
+			if epoch_driven {
+				err = epoch_evaluator.EpochEvaluate(pop, &generation, run_context)
+			} else {
+				err = generation_evaluator.GenerationEvaluate(pop, &generation, run_context)
+			}
 			if err != nil {
 				neat.InfoLog(fmt.Sprintf("!!!!! Generation [%d] evaluation failed !!!!!\n", generation_id))
 				return err
 			}
 			generation.Executed = time.Now()
 			trial.Generations = append(trial.Generations, generation)
-			if generation.Solved {
-				neat.InfoLog(fmt.Sprintf(">>>>> The winner organism found in [%d] generation! <<<<<\n", generation_id))
+			evaluated := &trial.Generations[len(trial.Generations) - 1]
+			if err = lifecycle.generationEvaluated(evaluated, pop); err != nil {
+				return err
+			}
+			reason := terminationReason(evaluated, pop, criteria, lifecycle)
+			restart := reason == TerminationStagnation && run_context.RestartPolicy != neat.NoRestart
+			if reason != TerminationNone && !restart {
+				if generation.Solved {
+					neat.InfoLog(fmt.Sprintf(">>>>> The winner organism found in [%d] generation! <<<<<\n", generation_id))
+				} else {
+					neat.InfoLog(fmt.Sprintf(">>>>> The trial terminated in [%d] generation, reason: %s <<<<<\n",
+						generation_id, reason))
+				}
+				trial.Termination = reason
+				if epoch_driven || generation.Solved {
+					recordFinalGeneration(pop, generation_id)
+				}
 				break
 			}
+
+			if restart {
+				// Reseed stagnated population instead of reproduction
+				neat.InfoLog(fmt.Sprintf(">>>>> The population stagnated in [%d] generation, restart from: %s\n",
+					generation_id, run_context.RestartPolicy))
+				if epoch_driven {
+					recordFinalGeneration(pop, generation_id)
+				}
+				err = pop.Reseed(criteria.seeds(start_genome), run_context)
+				criteria.restarted(generation_id + 1)
+				trial.Restarts = append(trial.Restarts, generation_id + 1)
+			} else if epoch_driven {
+				// Move to the next epoch if failed to find winner
+				neat.DebugLog(">>>>> start next generation")
+				_, err = pop.Epoch(generation_id + 1, run_context)
+			}
+			if err != nil {
+				neat.InfoLog(fmt.Sprintf("!!!!! Generation [%d] reproduction failed !!!!!\n", generation_id))
+				return err
+			}
+			if err = lifecycle.epochReproduced(generation_id + 1, pop); err != nil {
+				return err
+			}
+		}
+		if trial.Termination == TerminationNone {
+			trial.Termination = TerminationGenerations
+			if lifecycle.stopped {
+				trial.Termination = TerminationObserver
+			}
+		}
+		if err = lifecycle.trialEnded(); err != nil {
+			return err
 		}
 		// store trial into experiment
 		ex.Trials[run] = trial
@@ -96,6 +218,55 @@ func (ex *Experiment) Execute(context *neat.NeatContext, start_genome *genetics.
 	return nil
 }
 
+// Returns the reason to terminate trial after evaluation of given generation of population or TerminationNone if
+// trial should continue
+func terminationReason(epoch *Generation, pop *genetics.Population, criteria *terminationCriteria, lifecycle *trialLifecycle) TerminationReason {
+	if epoch.Solved {
+		return TerminationSolved
+	} else if lifecycle.stopped {
+		return TerminationObserver
+	}
+	return criteria.check(epoch, pop)
+}
+
+// Records the last evaluated generation of population into its genealogy and species history if tracked, since it is
+// not recorded by population epoch
+func recordFinalGeneration(pop *genetics.Population, generation int) {
+	if pop.Genealogy != nil {
+		pop.Genealogy.RecordGeneration(pop.Organisms, generation)
+	}
+	if pop.SpeciesHistory != nil {
+		pop.SpeciesHistory.RecordGeneration(pop.Species, generation)
+	}
+}
+
+// Spawns new population from the start genome and verifies it
+func spawnPopulation(start_genome *genetics.Genome, context *neat.NeatContext) (*genetics.Population, error) {
+	neat.InfoLog("\n>>>>> Spawning new population ")
+	pop, err := genetics.NewPopulation(start_genome, context)
+	if err != nil {
+		neat.InfoLog("Failed to spawn new population from start genome")
+		return nil, err
+	} else {
+		neat.InfoLog("OK <<<<<")
+	}
+	neat.InfoLog(">>>>> Verifying spawned population ")
+	_, err = pop.Verify()
+	if err != nil {
+		neat.ErrorLog("\n!!!!! Population verification failed !!!!!")
+		return nil, err
+	} else {
+		neat.InfoLog("OK <<<<<")
+	}
+	return pop, nil
+}
+
+// Returns shallow copy of the context to be used by one trial run
+func copyContext(context *neat.NeatContext) *neat.NeatContext {
+	c := *context
+	return &c
+}
+
 // To provide standard output directory syntax based on current trial
 // Method checks if directory should be created
 func OutDirForTrial(outDir string, trialID int) string {
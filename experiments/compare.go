@@ -0,0 +1,377 @@
+package experiments
+
+import (
+	"io"
+	"fmt"
+	"math"
+	"sort"
+	"bytes"
+	"math/rand"
+)
+
+// The statistical comparison of two samples of some measure (e.g. evaluations to solve) obtained from two experiments.
+// The differences and effect sizes are for the first sample relative to the second one, i.e. positive values mean
+// that measure is greater in the first sample.
+type SampleComparison struct {
+	// The name of compared measure
+	Name        string
+	// The sizes of samples
+	N1, N2      int
+	// The means of samples
+	Mean1       float64
+	Mean2       float64
+	// The medians of samples
+	Median1     float64
+	Median2     float64
+
+	// The Mann-Whitney U statistic of the first sample
+	U           float64
+	// The two-sided p-value of the Mann-Whitney U test (normal approximation with ties correction)
+	UPValue     float64
+	// The t statistic of the Welch's t-test
+	T           float64
+	// The degrees of freedom of the Welch's t-test
+	DF          float64
+	// The two-sided p-value of the Welch's t-test
+	TPValue     float64
+
+	// The difference of means
+	MeanDiff    float64
+	// The bootstrap confidence interval of the difference of means
+	CILow       float64
+	CIHigh      float64
+
+	// The Cohen's d effect size: the difference of means in units of pooled standard deviation
+	CohensD     float64
+	// The Cliff's delta effect size: P(x > y) - P(x < y) for x and y drawn from the first and second samples
+	CliffsDelta float64
+}
+
+// The parameters of bootstrap estimation of confidence intervals
+type Bootstrap struct {
+	// The number of bootstrap resamples
+	Resamples  int
+	// The confidence level of intervals, e.g. 0.95
+	Confidence float64
+	// The seed of random numbers generator to make estimation reproducible
+	Seed       int64
+}
+
+// The statistical comparison of two experiments by evaluations to solve, winner complexity and success rate
+type ExperimentsComparison struct {
+	// The names of compared experiments
+	Name1, Name2 string
+	// The number of trials in each experiment
+	Trials1      int
+	Trials2      int
+	// The confidence level of bootstrap intervals
+	Confidence   float64
+	// The comparisons of measures
+	Samples      []SampleComparison
+}
+
+// Compares two experiments by the number of evaluations to solve and the complexity of winners among solved trials,
+// and by the success rate of trials.
+func CompareExperiments(first, second *Experiment, bootstrap Bootstrap) *ExperimentsComparison {
+	return &ExperimentsComparison{
+		Name1:first.Name,
+		Name2:second.Name,
+		Trials1:len(first.Trials),
+		Trials2:len(second.Trials),
+		Confidence:bootstrap.Confidence,
+		Samples:[]SampleComparison{
+			CompareSamples("evaluations", first.WinnerEvals(), second.WinnerEvals(), bootstrap),
+			CompareSamples("complexity", first.WinnerComplexity(), second.WinnerComplexity(), bootstrap),
+			CompareSamples("success", first.Successes(), second.Successes(), bootstrap),
+		},
+	}
+}
+
+// Compares two samples with statistical tests, bootstrap confidence interval and effect sizes. The statistics which
+// can not be estimated for given samples (e.g. too small ones) are set to NaN.
+func CompareSamples(name string, x, y Floats, bootstrap Bootstrap) SampleComparison {
+	c := SampleComparison{
+		Name:name,
+		N1:len(x),
+		N2:len(y),
+		Mean1:math.NaN(),
+		Mean2:math.NaN(),
+		Median1:math.NaN(),
+		Median2:math.NaN(),
+		MeanDiff:math.NaN(),
+		CohensD:math.NaN(),
+	}
+	if len(x) > 0 {
+		c.Mean1, c.Median1 = x.Mean(), copyFloats(x).Median()
+	}
+	if len(y) > 0 {
+		c.Mean2, c.Median2 = y.Mean(), copyFloats(y).Median()
+	}
+	if len(x) > 0 && len(y) > 0 {
+		c.MeanDiff = c.Mean1 - c.Mean2
+	}
+	c.U, c.UPValue, c.CliffsDelta = MannWhitneyU(x, y)
+	c.T, c.DF, c.TPValue = WelchTTest(x, y)
+	c.CILow, c.CIHigh = bootstrap.MeanDiffInterval(x, y)
+	if len(x) > 1 && len(y) > 1 {
+		pooled := math.Sqrt((float64(len(x) - 1) * sampleVariance(x) + float64(len(y) - 1) * sampleVariance(y)) /
+			float64(len(x) + len(y) - 2))
+		c.CohensD = effectSize(c.MeanDiff, pooled)
+	}
+	return c
+}
+
+// Performs the Mann-Whitney U test of two samples. Returns the U statistic of the first sample, the two-sided p-value
+// estimated by normal approximation with continuity and ties corrections, and the Cliff's delta effect size.
+func MannWhitneyU(x, y Floats) (u, p, delta float64) {
+	n1, n2 := float64(len(x)), float64(len(y))
+	if n1 == 0 || n2 == 0 {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	// rank the pooled samples assigning average ranks to ties
+	type value struct {
+		v     float64
+		first bool
+	}
+	pooled := make([]value, 0, len(x) + len(y))
+	for _, v := range x {
+		pooled = append(pooled, value{v, true})
+	}
+	for _, v := range y {
+		pooled = append(pooled, value{v, false})
+	}
+	sort.Slice(pooled, func(i, j int) bool {
+		return pooled[i].v < pooled[j].v
+	})
+	rank_sum, ties := 0.0, 0.0
+	for i := 0; i < len(pooled); {
+		j := i
+		for j < len(pooled) && pooled[j].v == pooled[i].v {
+			j++
+		}
+		rank := float64(i + j + 1) / 2.0
+		for k := i; k < j; k++ {
+			if pooled[k].first {
+				rank_sum += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t * t * t - t
+		i = j
+	}
+
+	u = rank_sum - n1 * (n1 + 1) / 2
+	delta = 2 * u / (n1 * n2) - 1
+
+	n := n1 + n2
+	sd := math.Sqrt(n1 * n2 / 12 * ((n + 1) - ties / (n * (n - 1))))
+	if sd == 0 {
+		// all values are the same
+		return u, 1.0, delta
+	}
+	diff := u - n1 * n2 / 2
+	// continuity correction
+	diff = math.Copysign(math.Max(math.Abs(diff) - 0.5, 0), diff)
+	p = math.Erfc(math.Abs(diff / sd) / math.Sqrt2)
+	return u, p, delta
+}
+
+// Performs the Welch's t-test of two samples with unequal variances. Returns t statistic, degrees of freedom and the
+// two-sided p-value. Each sample should have at least two values.
+func WelchTTest(x, y Floats) (t, df, p float64) {
+	n1, n2 := float64(len(x)), float64(len(y))
+	if n1 < 2 || n2 < 2 {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	se1, se2 := sampleVariance(x) / n1, sampleVariance(y) / n2
+	se := se1 + se2
+	diff := x.Mean() - y.Mean()
+	if se == 0 {
+		// no variance in samples
+		if diff == 0 {
+			return 0, math.NaN(), 1.0
+		}
+		return math.Copysign(math.Inf(1), diff), math.NaN(), 0.0
+	}
+	t = diff / math.Sqrt(se)
+	df = se * se / (se1 * se1 / (n1 - 1) + se2 * se2 / (n2 - 1))
+	p = incompleteBeta(df / 2, 0.5, df / (df + t * t))
+	return t, df, p
+}
+
+// Estimates the confidence interval of the difference of means of two samples by percentile bootstrap
+func (b Bootstrap) MeanDiffInterval(x, y Floats) (low, high float64) {
+	if len(x) == 0 || len(y) == 0 || b.Resamples <= 0 {
+		return math.NaN(), math.NaN()
+	}
+	rnd := rand.New(rand.NewSource(b.Seed))
+	diffs := make(Floats, b.Resamples)
+	for i := range diffs {
+		diffs[i] = resampleMean(x, rnd) - resampleMean(y, rnd)
+	}
+	sort.Float64s(diffs)
+	alpha := (1 - b.Confidence) / 2
+	return percentile(diffs, alpha), percentile(diffs, 1 - alpha)
+}
+
+// Returns evaluations to solve of each solved trial
+func (e Experiment) WinnerEvals() Floats {
+	x := make(Floats, 0, len(e.Trials))
+	for i := range e.Trials {
+		if e.Trials[i].Solved() {
+			_, _, evals, _ := e.Trials[i].Winner()
+			x = append(x, float64(evals))
+		}
+	}
+	return x
+}
+
+// Returns complexity (the number of nodes and genes) of the winner genome of each solved trial
+func (e Experiment) WinnerComplexity() Floats {
+	x := make(Floats, 0, len(e.Trials))
+	for i := range e.Trials {
+		if e.Trials[i].Solved() {
+			nodes, genes, _, _ := e.Trials[i].Winner()
+			x = append(x, float64(nodes + genes))
+		}
+	}
+	return x
+}
+
+// Returns the success of each trial: one if trial was solved and zero otherwise
+func (e Experiment) Successes() Floats {
+	x := make(Floats, len(e.Trials))
+	for i, t := range e.Trials {
+		if t.Solved() {
+			x[i] = 1.0
+		}
+	}
+	return x
+}
+
+// Writes comparison of experiments as text table
+func (c *ExperimentsComparison) WriteText(w io.Writer) error {
+	b := bytes.NewBufferString("")
+	fmt.Fprintf(b, "EXPERIMENTS COMPARISON: [%s] (%d trials) vs [%s] (%d trials)\n",
+		c.Name1, c.Trials1, c.Name2, c.Trials2)
+	for _, s := range c.Samples {
+		fmt.Fprintf(b, "\n%s:\n", s.Name)
+		fmt.Fprintf(b, "\tN:\t\t%d vs %d\n", s.N1, s.N2)
+		fmt.Fprintf(b, "\tMean:\t\t%.3f vs %.3f (difference: %.3f, %.0f%% CI: [%.3f, %.3f])\n",
+			s.Mean1, s.Mean2, s.MeanDiff, c.Confidence * 100, s.CILow, s.CIHigh)
+		fmt.Fprintf(b, "\tMedian:\t\t%.3f vs %.3f\n", s.Median1, s.Median2)
+		fmt.Fprintf(b, "\tMann-Whitney:\tU = %.1f, p = %.4f\n", s.U, s.UPValue)
+		fmt.Fprintf(b, "\tWelch's t-test:\tt = %.3f, df = %.1f, p = %.4f\n", s.T, s.DF, s.TPValue)
+		fmt.Fprintf(b, "\tEffect size:\tCohen's d = %.3f, Cliff's delta = %.3f\n", s.CohensD, s.CliffsDelta)
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// Returns the unbiased variance of the sample
+func sampleVariance(x Floats) float64 {
+	// the Floats.Variance returns the sum of squared deviations
+	return x.Variance() / float64(len(x) - 1)
+}
+
+// Returns the difference of means in units of standard deviation
+func effectSize(diff, sd float64) float64 {
+	if sd == 0 {
+		if diff == 0 {
+			return 0
+		}
+		return math.Copysign(math.Inf(1), diff)
+	}
+	return diff / sd
+}
+
+// Returns the mean of random resample of the sample with replacement
+func resampleMean(x Floats, rnd *rand.Rand) float64 {
+	sum := 0.0
+	for range x {
+		sum += x[rnd.Intn(len(x))]
+	}
+	return sum / float64(len(x))
+}
+
+// Returns the percentile of the sorted values with linear interpolation
+func percentile(sorted Floats, q float64) float64 {
+	pos := q * float64(len(sorted) - 1)
+	i := int(math.Floor(pos))
+	if i >= len(sorted) - 1 {
+		return sorted[len(sorted) - 1]
+	}
+	if i < 0 {
+		return sorted[0]
+	}
+	return sorted[i] + (pos - float64(i)) * (sorted[i + 1] - sorted[i])
+}
+
+// Returns copy of the values to be sorted without changing the original order
+func copyFloats(x Floats) Floats {
+	c := make(Floats, len(x))
+	copy(c, x)
+	return c
+}
+
+// Returns the regularized incomplete beta function I_x(a, b)
+func incompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lg_ab, _ := math.Lgamma(a + b)
+	lg_a, _ := math.Lgamma(a)
+	lg_b, _ := math.Lgamma(b)
+	front := math.Exp(lg_ab - lg_a - lg_b + a * math.Log(x) + b * math.Log(1 - x))
+	// the continued fraction converges rapidly for x < (a + 1) / (a + b + 2), use symmetry otherwise
+	if x < (a + 1) / (a + b + 2) {
+		return front * betaContinuedFraction(a, b, x) / a
+	}
+	return 1 - front * betaContinuedFraction(b, a, 1 - x) / b
+}
+
+// Evaluates the continued fraction of the incomplete beta function by the modified Lentz's method
+func betaContinuedFraction(a, b, x float64) float64 {
+	const max_iterations, epsilon, tiny = 300, 1e-14, 1e-300
+	c, d := 1.0, 1.0 - (a + b) * x / (a + 1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= max_iterations; m++ {
+		fm := float64(m)
+		// the even step
+		num := fm * (b - fm) * x / ((a + 2 * fm - 1) * (a + 2 * fm))
+		d = 1 + num * d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num / c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		// the odd step
+		num = -(a + fm) * (a + b + fm) * x / ((a + 2 * fm) * (a + 2 * fm + 1))
+		d = 1 + num * d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num / c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta - 1) < epsilon {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,132 @@
+package experiments
+
+import (
+	"math"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWelchTTest(t *testing.T) {
+	x := Floats{27.5, 21.0, 19.0, 23.6, 17.0, 17.9, 16.9, 20.1, 21.9, 22.6, 23.1, 19.6, 19.0, 21.7, 21.4}
+	y := Floats{27.1, 22.0, 20.8, 23.4, 23.4, 23.5, 25.8, 22.0, 24.8, 20.2, 21.9, 22.1, 22.9, 20.5, 24.4}
+	tt, df, p := WelchTTest(x, y)
+	if math.Abs(tt + 2.46) > 0.01 || math.Abs(df - 24.99) > 0.05 || math.Abs(p - 0.021) > 0.001 {
+		t.Error("Wrong Welch's t-test results", tt, df, p)
+	}
+
+	// the critical value of t-distribution with 10 degrees of freedom at 0.05 significance
+	if p = incompleteBeta(5, 0.5, 10 / (10 + 2.228 * 2.228)); math.Abs(p - 0.05) > 1e-4 {
+		t.Error("Wrong incomplete beta function value", p)
+	}
+	if p = incompleteBeta(3, 3, 0.5); math.Abs(p - 0.5) > 1e-12 {
+		t.Error("Wrong symmetric incomplete beta function value", p)
+	}
+
+	// too small samples
+	if tt, _, _ = WelchTTest(Floats{1}, y); !math.IsNaN(tt) {
+		t.Error("t-test for single value sample", tt)
+	}
+	// samples without variance
+	if tt, _, p = WelchTTest(Floats{1, 1}, Floats{2, 2}); !math.IsInf(tt, -1) || p != 0 {
+		t.Error("Wrong t-test for samples without variance", tt, p)
+	}
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	u, p, delta := MannWhitneyU(Floats{1, 2, 3}, Floats{4, 5, 6})
+	if u != 0 || math.Abs(p - 0.08086) > 1e-4 || delta != -1 {
+		t.Error("Wrong Mann-Whitney test results", u, p, delta)
+	}
+	// the test is symmetric
+	u, p2, delta := MannWhitneyU(Floats{4, 5, 6}, Floats{1, 2, 3})
+	if u != 9 || p2 != p || delta != 1 {
+		t.Error("Wrong Mann-Whitney test results for swapped samples", u, p2, delta)
+	}
+
+	// ties
+	u, p, delta = MannWhitneyU(Floats{1, 1, 2}, Floats{2, 3, 3})
+	// z = (4 - 0.5) / sqrt(9 / 12 * (7 - 18 / 30))
+	if u != 0.5 || math.Abs(delta + 8.0 / 9.0) > 1e-12 || math.Abs(p - 0.11015) > 1e-4 {
+		t.Error("Wrong Mann-Whitney test results with ties", u, p, delta)
+	}
+	if _, p, _ = MannWhitneyU(Floats{1, 1}, Floats{1, 1, 1}); p != 1 {
+		t.Error("Wrong p-value for equal samples", p)
+	}
+	if u, _, _ = MannWhitneyU(Floats{}, Floats{1}); !math.IsNaN(u) {
+		t.Error("Mann-Whitney test for empty sample", u)
+	}
+}
+
+func TestBootstrap_MeanDiffInterval(t *testing.T) {
+	x := Floats{10, 12, 11, 13, 12, 11, 10, 12}
+	y := Floats{5, 6, 4, 5, 7, 6, 5, 4}
+	b := Bootstrap{Resamples:2000, Confidence:0.95, Seed:42}
+	low, high := b.MeanDiffInterval(x, y)
+	diff := x.Mean() - y.Mean()
+	if low > diff || high < diff || low <= 0 {
+		t.Error("Wrong confidence interval", low, high, diff)
+	}
+	// reproducible with the same seed
+	if low2, high2 := b.MeanDiffInterval(x, y); low2 != low || high2 != high {
+		t.Error("Confidence interval not reproducible", low2, high2)
+	}
+	// the wider interval for the higher confidence
+	b.Confidence = 0.99
+	if low2, high2 := b.MeanDiffInterval(x, y); low2 > low || high2 < high {
+		t.Error("Wrong confidence interval for higher confidence", low2, high2)
+	}
+}
+
+func TestCompareSamples(t *testing.T) {
+	x := Floats{3, 1, 2, 5, 4}
+	c := CompareSamples("test", x, Floats{6, 8, 7, 10, 9}, Bootstrap{Resamples:100, Confidence:0.9})
+	if c.N1 != 5 || c.Mean1 != 3 || c.Median2 != 8 || c.MeanDiff != -5 {
+		t.Error("Wrong descriptive statistics", c)
+	}
+	// pooled standard deviation is sqrt(2.5)
+	if math.Abs(c.CohensD + 5 / math.Sqrt(2.5)) > 1e-12 || c.CliffsDelta != -1 {
+		t.Error("Wrong effect sizes", c.CohensD, c.CliffsDelta)
+	}
+	if c.UPValue >= 0.05 || c.TPValue >= 0.01 || c.CIHigh >= 0 {
+		t.Error("Difference should be significant", c)
+	}
+	// the order of sample values kept
+	if x[0] != 3 {
+		t.Error("Sample values reordered", x)
+	}
+}
+
+func TestCompareExperiments(t *testing.T) {
+	first := Experiment{Name:"first", Trials:Trials{*buildTestTrial(0, 2), *buildTestTrial(1, 2), *buildTestTrial(2, 2)}}
+	second := Experiment{Name:"second", Trials:Trials{*buildTestTrial(0, 2), *buildTestTrial(1, 2)}}
+	for i := range second.Trials[1].Generations {
+		second.Trials[1].Generations[i].Solved = false
+	}
+	second.Trials[0].Generations[0].WinnerEvals = 100
+
+	comparison := CompareExperiments(&first, &second, Bootstrap{Resamples:100, Confidence:0.95})
+	if len(comparison.Samples) != 3 || comparison.Trials1 != 3 || comparison.Trials2 != 2 {
+		t.Fatal("Wrong comparison", comparison)
+	}
+	evals, complexity, success := comparison.Samples[0], comparison.Samples[1], comparison.Samples[2]
+	if evals.N1 != 3 || evals.N2 != 1 || evals.Mean2 != 100 {
+		t.Error("Wrong evaluations comparison", evals)
+	}
+	if complexity.Mean1 != 12 || complexity.MeanDiff != 0 {
+		t.Error("Wrong complexity comparison", complexity)
+	}
+	if success.N1 != 3 || success.Mean1 != 1 || success.Mean2 != 0.5 {
+		t.Error("Wrong success comparison", success)
+	}
+
+	var buf bytes.Buffer
+	if err := comparison.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"[first] (3 trials) vs [second] (2 trials)", "evaluations:", "Mann-Whitney", "95% CI"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Error("Missing in comparison text", s, buf.String())
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package experiments
+
+import (
+	"fmt"
+	"time"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/esp"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// Executes experiment with cooperative coevolution of hidden neurons by Enforced Sub-Populations (ESP) method instead
+// of NEAT. The networks with sensors and outputs of the start genome are assembled from neurons of sub-populations in
+// each generation and evaluated by the executor implementing EpochEvaluator, as the population of one species. Thus, the
+// statistics and the winner of ESP can be compared with the ones of NEAT. The population size of the context is
+// replaced with the number of networks assembled per generation. The observers are notified about trial lifecycle as
+// with Execute, except species events, while the termination criteria other than solution found or NumGenerations
+// evaluated are not supported.
+func (ex *Experiment) ExecuteESP(context *neat.NeatContext, start_genome *genetics.Genome, executor interface{}, options esp.Options) (err error) {
+	if ex.Trials == nil {
+		ex.Trials = make(Trials, context.NumRuns)
+	}
+	if ex.TrackGenealogy {
+		neat.WarnLog("Genealogy tracking is not supported by ESP")
+	}
+	if ex.TrackSpecies {
+		neat.WarnLog("Species history tracking is not supported by ESP")
+	}
+	epoch_evaluator, err := epochEvaluator(executor)
+	if err != nil {
+		return err
+	}
+
+	for run := 0; run < context.NumRuns; run++ {
+		var evolution *esp.ESP
+		neat.InfoLog("\n>>>>> Creating sub-populations of neurons")
+		if evolution, err = esp.New(start_genome, options); err != nil {
+			return err
+		}
+		run_context := copyContext(context)
+		run_context.PopSize = evolution.NetworksPerGeneration()
+
+		// start new trial
+		trial := Trial{
+			Id:run,
+		}
+		if trial_observer, ok := executor.(TrialRunObserver); ok {
+			trial_observer.TrialRunStarted(&trial) // optional
+		}
+		organisms := evolution.Assemble(0)
+		pop := assemblePopulation(organisms)
+		lifecycle := newTrialLifecycle(ex.observers, &trial, false)
+		if err = lifecycle.trialStarted(pop); err != nil {
+			return err
+		}
+
+		for generation_id := 0; generation_id < context.NumGenerations && !lifecycle.stopped; generation_id++ {
+			neat.InfoLog(fmt.Sprintf(">>>>> Generation:%3d\tRun: %d\n", generation_id, run))
+			generation := Generation{
+				Id:generation_id,
+				TrialId:run,
+			}
+			err = epoch_evaluator.EpochEvaluate(pop, &generation, run_context)
+			if err != nil {
+				neat.InfoLog(fmt.Sprintf("!!!!! Generation [%d] evaluation failed !!!!!\n", generation_id))
+				return err
+			}
+			generation.Executed = time.Now()
+			trial.Generations = append(trial.Generations, generation)
+			evaluated := &trial.Generations[len(trial.Generations) - 1]
+			if err = lifecycle.generationEvaluated(evaluated, pop); err != nil {
+				return err
+			}
+
+			if generation.Solved {
+				neat.InfoLog(fmt.Sprintf(">>>>> The winner organism found in [%d] generation! <<<<<\n", generation_id))
+				trial.Termination = TerminationSolved
+				break
+			} else if lifecycle.stopped {
+				trial.Termination = TerminationObserver
+				break
+			}
+			// Credit neurons and assemble networks of the next epoch if failed to find winner
+			evolution.Credit(organisms)
+			evolution.Epoch(generation_id + 1)
+			organisms = evolution.Assemble(generation_id + 1)
+			pop = assemblePopulation(organisms)
+			if err = lifecycle.epochReproduced(generation_id + 1, pop); err != nil {
+				return err
+			}
+		}
+		if trial.Termination == TerminationNone {
+			trial.Termination = TerminationGenerations
+			if lifecycle.stopped {
+				trial.Termination = TerminationObserver
+			}
+		}
+		if err = lifecycle.trialEnded(); err != nil {
+			return err
+		}
+		// store trial into experiment
+		ex.Trials[run] = trial
+	}
+	return nil
+}
+
+// Returns population of one species holding given organisms
+func assemblePopulation(organisms []*genetics.Organism) *genetics.Population {
+	sp := genetics.NewSpecies(1)
+	for _, org := range organisms {
+		org.Species = sp
+		sp.Organisms = append(sp.Organisms, org)
+	}
+	return &genetics.Population{
+		Species:[]*genetics.Species{sp},
+		Organisms:organisms,
+		LastSpecies:1,
+	}
+}
@@ -0,0 +1,88 @@
+package experiments
+
+import (
+	"testing"
+	"math/rand"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/esp"
+)
+
+func espTestOptions() esp.Options {
+	return esp.Options{
+		HiddenNeurons:3,
+		SubPopSize:8,
+		TrialsPerNeuron:5,
+		MutationRate:0.4,
+		MutationPower:0.3,
+		BurstStagnation:2,
+		Recurrent:true,
+	}
+}
+
+func TestExperiment_ExecuteESP(t *testing.T) {
+	rand.Seed(42)
+	context := observerTestContext()
+	neat.LogLevel = neat.LogLevelWarning
+
+	evaluator := randomFitnessEvaluator{}
+	ex := Experiment{}
+	if err := ex.ExecuteESP(context, buildTestGenome(1), &evaluator, espTestOptions()); err != nil {
+		t.Fatal(err)
+	}
+	if len(ex.Trials) != context.NumRuns || evaluator.evaluated != context.NumRuns * context.NumGenerations {
+		t.Fatal("Wrong number of trials or evaluations", len(ex.Trials), evaluator.evaluated)
+	}
+	for _, trial := range ex.Trials {
+		if len(trial.Generations) != context.NumGenerations || trial.Termination != TerminationGenerations {
+			t.Error("Wrong trial", trial.Id, len(trial.Generations), trial.Termination)
+		}
+		for _, epoch := range trial.Generations {
+			// the networks assembled from neurons form population of one species
+			if epoch.Diversity != 1 || epoch.Metrics.Organisms != 40 || epoch.Best == nil {
+				t.Error("Wrong generation", epoch.Id, epoch.Diversity, epoch.Metrics.Organisms)
+			}
+		}
+	}
+}
+
+func TestExperiment_ExecuteESPSolved(t *testing.T) {
+	rand.Seed(42)
+	context := observerTestContext()
+	neat.LogLevel = neat.LogLevelWarning
+
+	observer := newRecordingObserver()
+	ex := Experiment{}
+	ex.AddObserver(observer)
+	if err := ex.ExecuteESP(context, buildTestGenome(1), solvingEvaluator{solveAt:2}, espTestOptions()); err != nil {
+		t.Fatal(err)
+	}
+	for _, trial := range ex.Trials {
+		if len(trial.Generations) != 3 || trial.Termination != TerminationSolved || !trial.Solved() {
+			t.Error("Trial should be solved", trial.Id, len(trial.Generations), trial.Termination)
+		}
+	}
+	// the observers are notified as by NEAT execution except species events
+	for event, n := range map[string]int{"started":2, "evaluated":6, "reproduced":4, "solved":2, "ended":2, "created":0} {
+		if observer.count(event) != n {
+			t.Error("Wrong number of events", event, observer.count(event))
+		}
+	}
+
+	// the observer can stop trial
+	observer = newRecordingObserver()
+	observer.stop, observer.stopAt = ErrStopTrial, 1
+	ex = Experiment{}
+	ex.AddObserver(observer)
+	if err := ex.ExecuteESP(context, buildTestGenome(1), &randomFitnessEvaluator{}, espTestOptions()); err != nil {
+		t.Fatal(err)
+	}
+	if trial := ex.Trials[0]; len(trial.Generations) != 2 || trial.Termination != TerminationObserver {
+		t.Error("Trial should be stopped by observer", len(trial.Generations), trial.Termination)
+	}
+
+	options := espTestOptions()
+	options.HiddenNeurons = 0
+	if err := ex.ExecuteESP(context, buildTestGenome(1), solvingEvaluator{solveAt:2}, options); err == nil {
+		t.Error("Invalid options accepted")
+	}
+}
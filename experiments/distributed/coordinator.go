@@ -0,0 +1,184 @@
+package distributed
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"bytes"
+	"errors"
+	"strings"
+	"net/http"
+	"encoding/json"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The default number of consecutive failures after which worker excluded from evaluation of organisms
+const defaultMaxWorkerFailures = 3
+
+// The coordinator distributing evaluation of organisms among remote workers. The organisms are sent to the workers
+// concurrently, the failed evaluations (network errors, timeouts or evaluator errors) are retried, possibly by other
+// workers. The worker failed several times in a row is excluded from the rest of evaluation, but it will be tried again
+// with the next call to Evaluate.
+type Coordinator struct {
+	// The base URLs of the workers, e.g. http://localhost:8080
+	Workers           []string
+	// The name of evaluator registered at the workers
+	Evaluator         string
+	// The number of retries of failed organism evaluation
+	Retries           int
+	// The number of consecutive failures after which worker excluded from evaluation, zero to never exclude
+	MaxWorkerFailures int
+	// The number of organisms evaluated by each worker concurrently
+	Concurrency       int
+
+	// The HTTP client to send requests to the workers
+	client            *http.Client
+}
+
+// The organism to be evaluated and the number of failed attempts to evaluate it
+type job struct {
+	org      *genetics.Organism
+	attempts int
+}
+
+// Creates new coordinator for given workers with named evaluator. The timeout limits the time to evaluate one
+// organism by worker, the failed evaluation will be retried given number of times.
+func NewCoordinator(workers []string, evaluator string, timeout time.Duration, retries int) *Coordinator {
+	return &Coordinator{
+		Workers:workers,
+		Evaluator:evaluator,
+		Retries:retries,
+		MaxWorkerFailures:defaultMaxWorkerFailures,
+		Concurrency:1,
+		client:&http.Client{Timeout:timeout},
+	}
+}
+
+// Evaluates given organisms by the workers and sets fitness, error and winner flag of each organism. Returns error if
+// evaluation of any organism failed after all retries or if all workers were excluded due to failures.
+func (c *Coordinator) Evaluate(organisms []*genetics.Organism) error {
+	if len(c.Workers) == 0 {
+		return errors.New("COORDINATOR: No workers to evaluate organisms")
+	}
+	if len(organisms) == 0 {
+		return nil
+	}
+
+	// each job is either in the queue or being evaluated, thus queue never blocks and each job produces one result
+	jobs := make(chan *job, len(organisms))
+	results := make(chan error, len(organisms))
+	for _, org := range organisms {
+		jobs <- &job{org:org}
+	}
+
+	// the serving goroutines are stopped and waited for before return, since they set results of evaluation into
+	// organisms
+	quit := make(chan struct{})
+	var wg sync.WaitGroup
+	defer func() {
+		close(quit)
+		wg.Wait()
+	}()
+	concurrency := c.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	for _, url := range c.Workers {
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go c.serve(url, jobs, results, quit, &wg)
+		}
+	}
+	all_excluded := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(all_excluded)
+	}()
+
+	for done := 0; done < len(organisms); done++ {
+		select {
+		case err := <-results:
+			if err != nil {
+				return err
+			}
+		case <-all_excluded:
+			// collect results sent before the last worker exited
+			for ; done < len(organisms) && len(results) > 0; done++ {
+				if err := <-results; err != nil {
+					return err
+				}
+			}
+			if done < len(organisms) {
+				return errors.New(fmt.Sprintf("COORDINATOR: All workers failed, %d organisms left not evaluated",
+					len(organisms) - done))
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// Evaluates organisms from the jobs queue by the worker at given URL until quit or worker excluded due to failures
+func (c *Coordinator) serve(url string, jobs chan *job, results chan<- error, quit <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+	failures := 0
+	for {
+		select {
+		case <-quit:
+			return
+		case j := <-jobs:
+			err := c.evaluate(url, j.org)
+			if err == nil {
+				failures = 0
+				results <- nil
+				continue
+			}
+
+			j.attempts++
+			neat.WarnLog(fmt.Sprintf("COORDINATOR: Evaluation of organism [%d] by worker [%s] failed, attempt: %d, reason: %s",
+				j.org.Genotype.Id, url, j.attempts, err))
+			if j.attempts > c.Retries {
+				results <- errors.New(fmt.Sprintf("COORDINATOR: Failed to evaluate organism [%d] after %d attempts, reason: %s",
+					j.org.Genotype.Id, j.attempts, err))
+			} else {
+				jobs <- j
+			}
+
+			failures++
+			if c.MaxWorkerFailures > 0 && failures >= c.MaxWorkerFailures {
+				neat.WarnLog(fmt.Sprintf("COORDINATOR: Worker [%s] excluded after %d failures", url, failures))
+				return
+			}
+		}
+	}
+}
+
+// Sends organism to the worker at given URL and sets results of its evaluation
+func (c *Coordinator) evaluate(url string, org *genetics.Organism) error {
+	body, err := json.Marshal(NewEvaluationRequest(c.Evaluator, org))
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Post(strings.TrimSuffix(url, "/") + EvaluatePath, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	res := EvaluationResult{}
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return errors.New(fmt.Sprintf("Failed to decode response with status [%s], reason: %s", resp.Status, err))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(fmt.Sprintf("Worker responded with status [%s]: %s", resp.Status, res.Message))
+	}
+	if res.GenomeId != org.Genotype.Id {
+		return errors.New(fmt.Sprintf("Genome ID mismatch in response. Found: %d, expected: %d",
+			res.GenomeId, org.Genotype.Id))
+	}
+	org.Fitness = res.Fitness
+	org.Error = res.Error
+	org.IsWinner = res.IsWinner
+	return nil
+}
@@ -0,0 +1,180 @@
+package distributed
+
+import (
+	"testing"
+	"time"
+	"sync/atomic"
+	"net/http"
+	"net/http/httptest"
+	"math/rand"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+	"github.com/yaricom/goNEAT/experiments"
+)
+
+// Creates test organisms with random genomes
+func buildTestOrganisms(count int) []*genetics.Organism {
+	orgs := make([]*genetics.Organism, count)
+	for i := range orgs {
+		orgs[i] = genetics.NewOrganism(0.0, genetics.NewGenomeRand(i + 1, 3, 2, 2, 5, false, 0.5), 0)
+	}
+	return orgs
+}
+
+// Starts local worker with structure evaluator registered
+func startTestWorker() *httptest.Server {
+	worker := NewWorker()
+	worker.Register("structure", structureEvaluator{})
+	return httptest.NewServer(worker)
+}
+
+// Checks that organisms evaluated by structure evaluator
+func checkEvaluated(orgs []*genetics.Organism, t *testing.T) {
+	for _, org := range orgs {
+		if org.Fitness != float64(org.Phenotype.LinkCount()) || org.Error != float64(org.Phenotype.NodeCount()) {
+			t.Error("Organism not evaluated", org.Genotype.Id, org.Fitness, org.Error)
+		}
+	}
+}
+
+func TestCoordinator_Evaluate(t *testing.T) {
+	rand.Seed(42)
+	neat.LogLevel = neat.LogLevelError
+	w1, w2 := startTestWorker(), startTestWorker()
+	defer w1.Close()
+	defer w2.Close()
+
+	coordinator := NewCoordinator([]string{w1.URL, w2.URL}, "structure", time.Second, 1)
+	coordinator.Concurrency = 2
+	orgs := buildTestOrganisms(20)
+	if err := coordinator.Evaluate(orgs); err != nil {
+		t.Fatal(err)
+	}
+	checkEvaluated(orgs, t)
+}
+
+func TestCoordinator_EvaluateRetries(t *testing.T) {
+	rand.Seed(42)
+	neat.LogLevel = neat.LogLevelError
+	good := startTestWorker()
+	defer good.Close()
+
+	// the worker failing every other request
+	var requests int32
+	worker := NewWorker()
+	worker.Register("structure", structureEvaluator{})
+	flaky := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) % 2 == 0 {
+			http.Error(rw, "{\"message\":\"failure\"}", http.StatusInternalServerError)
+			return
+		}
+		worker.ServeHTTP(rw, r)
+	}))
+	defer flaky.Close()
+
+	// the worker exceeding timeout
+	slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		worker.ServeHTTP(rw, r)
+	}))
+	defer slow.Close()
+
+	coordinator := NewCoordinator([]string{flaky.URL, slow.URL, good.URL}, "structure", 100 * time.Millisecond, 5)
+	orgs := buildTestOrganisms(20)
+	if err := coordinator.Evaluate(orgs); err != nil {
+		t.Fatal(err)
+	}
+	checkEvaluated(orgs, t)
+	if atomic.LoadInt32(&requests) < 2 {
+		t.Error("Flaky worker not used", requests)
+	}
+}
+
+func TestCoordinator_EvaluateFailed(t *testing.T) {
+	rand.Seed(42)
+	neat.LogLevel = neat.LogLevelError
+	worker := startTestWorker()
+	defer worker.Close()
+
+	// unknown evaluator fails after all retries
+	coordinator := NewCoordinator([]string{worker.URL}, "unknown", time.Second, 2)
+	coordinator.MaxWorkerFailures = 0
+	if err := coordinator.Evaluate(buildTestOrganisms(5)); err == nil {
+		t.Error("Evaluation with unknown evaluator succeeded")
+	}
+
+	// all workers excluded
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	coordinator = NewCoordinator([]string{down.URL}, "structure", time.Second, 10)
+	if err := coordinator.Evaluate(buildTestOrganisms(5)); err == nil {
+		t.Error("Evaluation by unavailable worker succeeded")
+	}
+
+	// no workers
+	coordinator = NewCoordinator(nil, "structure", time.Second, 1)
+	if err := coordinator.Evaluate(buildTestOrganisms(1)); err == nil {
+		t.Error("Evaluation without workers succeeded")
+	}
+}
+
+func TestCoordinator_EvaluateFailedStopsWorkers(t *testing.T) {
+	rand.Seed(42)
+	neat.LogLevel = neat.LogLevelError
+	worker := NewWorker()
+	worker.Register("structure", structureEvaluator{})
+	slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		worker.ServeHTTP(rw, r)
+	}))
+	defer slow.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		http.Error(rw, "{\"message\":\"failure\"}", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	coordinator := NewCoordinator([]string{slow.URL, failing.URL}, "structure", time.Second, 0)
+	orgs := buildTestOrganisms(10)
+	if err := coordinator.Evaluate(orgs); err == nil {
+		t.Fatal("Evaluation with failing worker succeeded")
+	}
+	// the organisms are not changed after evaluation returned
+	fitness := make([]float64, len(orgs))
+	for i, org := range orgs {
+		fitness[i] = org.Fitness
+	}
+	time.Sleep(300 * time.Millisecond)
+	for i, org := range orgs {
+		if org.Fitness != fitness[i] {
+			t.Error("Organism evaluated after evaluation returned", org.Genotype.Id)
+		}
+	}
+}
+
+func TestGenerationEvaluator_EpochEvaluate(t *testing.T) {
+	rand.Seed(42)
+	neat.LogLevel = neat.LogLevelError
+	w := startTestWorker()
+	defer w.Close()
+
+	context := &neat.NeatContext{CompatThreshold:0.5, PopSize:20}
+	pop, err := genetics.NewPopulation(genetics.NewGenomeRand(1, 3, 2, 2, 5, false, 0.5), context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evaluator := GenerationEvaluator{Coordinator:NewCoordinator([]string{w.URL}, "structure", time.Second, 1)}
+	epoch := experiments.Generation{Id:1}
+	if err = evaluator.EpochEvaluate(pop, &epoch, context); err != nil {
+		t.Fatal(err)
+	}
+	checkEvaluated(pop.Organisms, t)
+	if epoch.Best == nil || epoch.Best.Fitness != float64(epoch.Best.Phenotype.LinkCount()) {
+		t.Error("Wrong best organism of generation", epoch.Best)
+	}
+
+	// the evaluation by failed workers is reported
+	w.Close()
+	if err = evaluator.EpochEvaluate(pop, &experiments.Generation{Id:2}, context); err == nil {
+		t.Error("Failed evaluation not reported")
+	}
+}
@@ -0,0 +1,26 @@
+package distributed
+
+import (
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+	"github.com/yaricom/goNEAT/experiments"
+)
+
+// The generation evaluator which evaluates organisms of population by remote workers. It can be used with experiment
+// execution as any other generation evaluator. The winner genome is not stored by evaluator, register
+// experiments.PopulationDumper observer with experiment to store it.
+type GenerationEvaluator struct {
+	// The coordinator to distribute organisms among workers
+	Coordinator *Coordinator
+}
+
+// Evaluates one epoch of given population by remote workers and advances population to the next epoch if winner not
+// found
+func (e GenerationEvaluator) GenerationEvaluate(pop *genetics.Population, epoch *experiments.Generation, context *neat.NeatContext) error {
+	return experiments.OrganismsEvaluator(e.Coordinator.Evaluate).GenerationEvaluate(pop, epoch, context)
+}
+
+// Evaluates one epoch of given population by remote workers and collects statistics of the epoch
+func (e GenerationEvaluator) EpochEvaluate(pop *genetics.Population, epoch *experiments.Generation, context *neat.NeatContext) error {
+	return experiments.OrganismsEvaluator(e.Coordinator.Evaluate).EpochEvaluate(pop, epoch, context)
+}
@@ -0,0 +1,57 @@
+// The distributed package provides evaluation of organisms by remote workers. The coordinator sends genomes of
+// organisms to the workers over HTTP using JSON messages, the workers build phenotypes, evaluate them with registered
+// evaluators and send back fitness, error and winner flag of each organism.
+package distributed
+
+import (
+	"bytes"
+	"strings"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The path of the worker's endpoint accepting evaluation requests
+const EvaluatePath = "/evaluate"
+
+// The request to evaluate one organism sent by coordinator to the worker
+type EvaluationRequest struct {
+	// The name of evaluator registered at the worker
+	Evaluator string `json:"evaluator"`
+	// The ID of organism's genome
+	GenomeId  int    `json:"genome_id"`
+	// The organism's genome serialized in the plain text format (see Genome.Write)
+	Genome    string `json:"genome"`
+}
+
+// The results of organism evaluation sent back by the worker
+type EvaluationResult struct {
+	// The ID of evaluated organism's genome
+	GenomeId int     `json:"genome_id"`
+	// The fitness of organism
+	Fitness  float64 `json:"fitness"`
+	// The error value of organism
+	Error    float64 `json:"error"`
+	// The flag to indicate whether organism is a winner
+	IsWinner bool    `json:"is_winner"`
+	// The error message if evaluation failed
+	Message  string  `json:"message,omitempty"`
+}
+
+// Creates request to evaluate given organism with named evaluator
+func NewEvaluationRequest(evaluator string, org *genetics.Organism) *EvaluationRequest {
+	var buf bytes.Buffer
+	org.Genotype.Write(&buf)
+	return &EvaluationRequest{
+		Evaluator:evaluator,
+		GenomeId:org.Genotype.Id,
+		Genome:buf.String(),
+	}
+}
+
+// Creates organism from the genome of this request
+func (r *EvaluationRequest) Organism() (*genetics.Organism, error) {
+	genome, err := genetics.ReadGenome(strings.NewReader(r.Genome), r.GenomeId)
+	if err != nil {
+		return nil, err
+	}
+	return genetics.NewOrganism(0.0, genome, 0), nil
+}
@@ -0,0 +1,97 @@
+package distributed
+
+import (
+	"fmt"
+	"sync"
+	"errors"
+	"net/http"
+	"encoding/json"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The evaluator of single organism to be registered at the worker
+type OrganismEvaluator interface {
+	// Evaluates given organism and sets its Fitness, Error and IsWinner fields
+	Evaluate(org *genetics.Organism) error
+}
+
+// The worker evaluating organisms received from coordinator with registered evaluators. The worker implements
+// http.Handler and can be started with any HTTP server, e.g. http.ListenAndServe(addr, worker).
+type Worker struct {
+	// The registered evaluators by name
+	evaluators map[string]OrganismEvaluator
+	// The lock to guard evaluators registry
+	mutex      sync.RWMutex
+}
+
+// Creates new worker without registered evaluators
+func NewWorker() *Worker {
+	return &Worker{
+		evaluators:make(map[string]OrganismEvaluator),
+	}
+}
+
+// Registers evaluator under given name. The coordinator should use the same name to evaluate organisms with it.
+func (w *Worker) Register(name string, evaluator OrganismEvaluator) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if _, ok := w.evaluators[name]; ok {
+		return errors.New(fmt.Sprintf("Evaluator already registered: %s", name))
+	}
+	w.evaluators[name] = evaluator
+	return nil
+}
+
+// Evaluates organism described by request with the registered evaluator
+func (w *Worker) Evaluate(req *EvaluationRequest) (*EvaluationResult, error) {
+	w.mutex.RLock()
+	evaluator, ok := w.evaluators[req.Evaluator]
+	w.mutex.RUnlock()
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("Unknown evaluator: %s", req.Evaluator))
+	}
+
+	org, err := req.Organism()
+	if err != nil {
+		return nil, err
+	}
+	if err = evaluator.Evaluate(org); err != nil {
+		return nil, err
+	}
+	return &EvaluationResult{
+		GenomeId:req.GenomeId,
+		Fitness:org.Fitness,
+		Error:org.Error,
+		IsWinner:org.IsWinner,
+	}, nil
+}
+
+// Handles evaluation requests sent by coordinator
+func (w *Worker) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != EvaluatePath {
+		http.NotFound(rw, r)
+		return
+	}
+	req := EvaluationRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResult(rw, http.StatusBadRequest, &EvaluationResult{Message:err.Error()})
+		return
+	}
+	res, err := w.Evaluate(&req)
+	if err != nil {
+		neat.ErrorLog(fmt.Sprintf("WORKER: Failed to evaluate organism [%d], reason: %s", req.GenomeId, err))
+		writeResult(rw, http.StatusInternalServerError, &EvaluationResult{GenomeId:req.GenomeId, Message:err.Error()})
+		return
+	}
+	writeResult(rw, http.StatusOK, res)
+}
+
+// Writes evaluation result as JSON response with given status code
+func writeResult(rw http.ResponseWriter, status int, res *EvaluationResult) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	if err := json.NewEncoder(rw).Encode(res); err != nil {
+		neat.ErrorLog(fmt.Sprintf("WORKER: Failed to write evaluation result, reason: %s", err))
+	}
+}
@@ -0,0 +1,94 @@
+package distributed
+
+import (
+	"testing"
+	"strings"
+	"net/http"
+	"net/http/httptest"
+	"encoding/json"
+	"math/rand"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The evaluator assigning fitness and error of organism based on the structure of its phenotype
+type structureEvaluator struct {
+}
+
+func (e structureEvaluator) Evaluate(org *genetics.Organism) error {
+	org.Fitness = float64(org.Phenotype.LinkCount())
+	org.Error = float64(org.Phenotype.NodeCount())
+	org.IsWinner = org.Phenotype.LinkCount() > 4
+	return nil
+}
+
+func TestWorker_Evaluate(t *testing.T) {
+	rand.Seed(42)
+	worker := NewWorker()
+	if err := worker.Register("structure", structureEvaluator{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := worker.Register("structure", structureEvaluator{}); err == nil {
+		t.Error("Duplicate evaluator registered")
+	}
+
+	genome := genetics.NewGenomeRand(1, 3, 2, 2, 5, false, 0.5)
+	org := genetics.NewOrganism(0.0, genome, 0)
+	res, err := worker.Evaluate(NewEvaluationRequest("structure", org))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the phenotype built by worker should be the same as local one
+	expected := *org
+	structureEvaluator{}.Evaluate(&expected)
+	if res.GenomeId != genome.Id || res.Fitness != expected.Fitness || res.Error != expected.Error ||
+		res.IsWinner != expected.IsWinner {
+		t.Error("Wrong evaluation result", res)
+	}
+
+	if _, err = worker.Evaluate(NewEvaluationRequest("unknown", org)); err == nil {
+		t.Error("Organism evaluated with unknown evaluator")
+	}
+}
+
+func TestWorker_ServeHTTP(t *testing.T) {
+	rand.Seed(42)
+	worker := NewWorker()
+	worker.Register("structure", structureEvaluator{})
+
+	org := genetics.NewOrganism(0.0, genetics.NewGenomeRand(7, 3, 2, 2, 5, false, 0.5), 0)
+	body, _ := json.Marshal(NewEvaluationRequest("structure", org))
+	rec := httptest.NewRecorder()
+	worker.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, EvaluatePath, strings.NewReader(string(body))))
+	if rec.Code != http.StatusOK {
+		t.Fatal("Wrong status code", rec.Code, rec.Body.String())
+	}
+	res := EvaluationResult{}
+	if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if res.GenomeId != 7 || res.Fitness != float64(org.Phenotype.LinkCount()) {
+		t.Error("Wrong evaluation result", res)
+	}
+
+	// malformed request
+	rec = httptest.NewRecorder()
+	worker.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, EvaluatePath, strings.NewReader("{")))
+	if rec.Code != http.StatusBadRequest {
+		t.Error("Wrong status code for malformed request", rec.Code)
+	}
+
+	// failed evaluation
+	body, _ = json.Marshal(NewEvaluationRequest("unknown", org))
+	rec = httptest.NewRecorder()
+	worker.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, EvaluatePath, strings.NewReader(string(body))))
+	if rec.Code != http.StatusInternalServerError {
+		t.Error("Wrong status code for failed evaluation", rec.Code)
+	}
+
+	// wrong path
+	rec = httptest.NewRecorder()
+	worker.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Error("Wrong status code for wrong path", rec.Code)
+	}
+}
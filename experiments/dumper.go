@@ -0,0 +1,77 @@
+package experiments
+
+import (
+	"os"
+	"io"
+	"fmt"
+	"bufio"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The observer which dumps the population by species into the "gen_N" files every PrintEvery generations and when
+// solved, and the genome of the winner organism when population solved. The files are placed into the output
+// directory of the trial, the failures to write them are logged without stopping experiment.
+type PopulationDumper struct {
+	ObserverAdapter
+
+	// The output path to store dumped files under the trial's directories
+	OutputPath string
+	// The population dumped every PrintEvery generations, if zero it is dumped only when solved
+	PrintEvery int
+	// The prefix of the winner genome file name, which followed by the number of nodes and links of winner's network
+	WinnerName    string
+	// If true the fitness of winner is placed into the winner genome file name between the prefix and the number of
+	// nodes, e.g. "pole2_winner_1.0_7-12"
+	WinnerFitness bool
+}
+
+// Dumps population of evaluated generation if it solved or due to be printed
+func (d PopulationDumper) GenerationEvaluated(trial *Trial, epoch *Generation, pop *genetics.Population) error {
+	if !epoch.Solved && (d.PrintEvery <= 0 || epoch.Id % d.PrintEvery != 0) {
+		return nil
+	}
+	pop_path := fmt.Sprintf("%s/gen_%d", OutDirForTrial(d.OutputPath, trial.Id), epoch.Id)
+	if err := dumpToFile(pop_path, pop.WriteBySpecies); err != nil {
+		neat.ErrorLog(fmt.Sprintf("Failed to dump population, reason: %s\n", err))
+	}
+	return nil
+}
+
+// Dumps genome of the first winner organism found in population
+func (d PopulationDumper) PopulationSolved(trial *Trial, epoch *Generation, pop *genetics.Population) error {
+	for _, org := range pop.Organisms {
+		if !org.IsWinner {
+			continue
+		}
+		name := d.WinnerName
+		if d.WinnerFitness {
+			name = fmt.Sprintf("%s_%.1f", name, org.Fitness)
+		}
+		org_path := fmt.Sprintf("%s/%s_%d-%d", OutDirForTrial(d.OutputPath, trial.Id), name,
+			org.Phenotype.NodeCount(), org.Phenotype.LinkCount())
+		if err := dumpToFile(org_path, org.Genotype.Write); err != nil {
+			neat.ErrorLog(fmt.Sprintf("Failed to dump winner organism genome, reason: %s\n", err))
+			return nil
+		}
+		neat.InfoLog(fmt.Sprintf("Generation #%d winner dumped to: %s\n", epoch.Id, org_path))
+		break
+	}
+	return nil
+}
+
+// Creates file at given path and writes into it by provided function. The errors of writing are collected by buffered
+// writer, since the writing functions of population and genome do not return them.
+func dumpToFile(path string, write func(w io.Writer)) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriter(file)
+	write(buf)
+	if err = buf.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
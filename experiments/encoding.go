@@ -0,0 +1,113 @@
+package experiments
+
+import (
+	"io"
+	"io/ioutil"
+	"fmt"
+	"bytes"
+	"bufio"
+	"errors"
+	"encoding/gob"
+)
+
+// The magic bytes starting the experiment data written by Experiment.Write
+var formatMagic = []byte("GONEATEX")
+
+// The versions of experiment data format. The formats without header were written before the header was introduced,
+// their versions are never stored and numbered below the first version stored in the header.
+const (
+	// The format of early releases without header
+	formatLegacy = iota - 2
+	// The format without header extended with population metrics of generation
+	formatLegacyMetrics
+	// The format without header extended with reproduction operators statistics of generation
+	formatLegacyOperators
+	// The format without header extended with islands of trial
+	formatLegacyIslands
+	// The format with header, optional best organism and species of the best organism
+	formatVersion2
+	// The format with IDs and sizes of species per generation
+	formatVersion3
+	// The format with termination reason, restart policy and restarts of trial
+	formatVersion4
+	// The format with offspring reallocation events per generation
+	formatVersion5
+	// The format with generations of parasite population of competitive coevolution
+	formatVersion6
+)
+
+// The current version of experiment data format
+const FormatVersion = formatVersion6
+
+// Writes the header of experiment data: the magic bytes followed by the format version
+func writeHeader(w io.Writer, enc *gob.Encoder) error {
+	if _, err := w.Write(formatMagic); err != nil {
+		return err
+	}
+	return enc.Encode(FormatVersion)
+}
+
+// Checks whether data in the reader starts with the header
+func hasHeader(br *bufio.Reader) bool {
+	magic, err := br.Peek(len(formatMagic))
+	return err == nil && bytes.Equal(magic, formatMagic)
+}
+
+// Reads the header of experiment data and returns the format version
+func readHeader(br *bufio.Reader, dec *gob.Decoder) (int, error) {
+	if _, err := br.Discard(len(formatMagic)); err != nil {
+		return 0, err
+	}
+	var version int
+	if err := dec.Decode(&version); err != nil {
+		return 0, err
+	}
+	if version < formatVersion2 || version > FormatVersion {
+		return 0, errors.New(fmt.Sprintf("Unsupported version of experiment data format: %d", version))
+	}
+	return version, nil
+}
+
+// Decodes experiment data in the legacy format without header. The version of legacy format is detected by decoding
+// data with each of them starting from the latest, the data should be consumed completely by the right one.
+func (ex *Experiment) readLegacy(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	for _, version := range []int{formatLegacyIslands, formatLegacyOperators, formatLegacyMetrics, formatLegacy} {
+		legacy := Experiment{}
+		dec := gob.NewDecoder(bytes.NewReader(data))
+		if err = legacy.decode(dec, version); err != nil {
+			continue
+		}
+		var extra interface{}
+		if dec.Decode(&extra) != io.EOF {
+			err = errors.New("unexpected data after the end of experiment")
+			continue
+		}
+		ex.Id, ex.Name, ex.Trials = legacy.Id, legacy.Name, legacy.Trials
+		return nil
+	}
+	return errors.New(fmt.Sprintf("Failed to decode legacy experiment data, reason: %s", err))
+}
+
+// Encodes given values one after another, stops at the first error
+func encodeValues(enc *gob.Encoder, values ...interface{}) error {
+	for _, v := range values {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decodes values into given pointers one after another, stops at the first error
+func decodeValues(dec *gob.Decoder, pointers ...interface{}) error {
+	for _, p := range pointers {
+		if err := dec.Decode(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"github.com/yaricom/goNEAT/neat/genetics"
 	"io"
+	"bufio"
 	"encoding/gob"
 	"fmt"
 )
@@ -12,9 +13,16 @@ import (
 // An Experiment is a collection of trials for one experiment. It's useful for statistical analysis of a series of
 // experiments
 type Experiment struct {
-	Id   int
-	Name string
+	Id             int
+	Name           string
 	Trials
+	// If true the genealogy of evaluated organisms will be recorded for each trial
+	TrackGenealogy bool
+	// If true the history of species will be recorded for each trial
+	TrackSpecies   bool
+
+	// The observers of experiment execution lifecycle
+	observers      []ExperimentObserver
 }
 
 func (e Experiment) LastExecuted() time.Time {
@@ -70,12 +78,12 @@ func (e Experiment) BestFitness() Floats {
 	return x
 }
 
-// The age values of the organisms for each trial
+// The age values of the organisms for each trial. The age is zero if species of the organism is unknown.
 func (e Experiment) BestAge() Floats {
 	var x Floats = make([]float64, len(e.Trials))
 	for i, t := range e.Trials {
 		if org, ok := t.BestOrganism(false); ok {
-			x[i] = float64(org.Species.Age)
+			x[i] = float64(organismAge(org))
 		}
 	}
 	return x
@@ -145,6 +153,17 @@ func (e *Experiment) AvgWinner() (avg_nodes, avg_genes, avg_evals, avg_diversity
 	return avg_nodes, avg_genes, avg_evals, avg_diversity
 }
 
+// Returns the reproduction operators statistics accumulated over all generations of all trials
+func (ex Experiment) OperatorStatistics() *genetics.OperatorStatistics {
+	stats := genetics.NewOperatorStatistics()
+	for _, t := range ex.Trials {
+		for _, e := range t.Generations {
+			stats.Merge(e.Operators)
+		}
+	}
+	return stats
+}
+
 // Prints experiment statistics
 func (ex Experiment) PrintStatistics() {
 	fmt.Printf("\n+++ Solved %d trials from %d +++\n", ex.TrialsSolved(), len(ex.Trials))
@@ -154,7 +173,7 @@ func (ex Experiment) PrintStatistics() {
 		fmt.Printf("\nChampion found in %d trial run\n\tWinner Nodes:\t%d\n\tWinner Genes:\t%d\n\tWinner Evals:\t%d\n\n\tDiversity:\t%d",
 			trid, nodes, genes, evals, divers)
 		fmt.Printf("\n\tComplexity:\t%d\n\tAge:\t\t%d\n\tFitness:\t%.1f\n",
-			org.Phenotype.Complexity(), org.Species.Age, org.Fitness)
+			org.Phenotype.Complexity(), organismAge(org), org.Fitness)
 	} else {
 		fmt.Println("\nNo winner found in the experiment!!!")
 	}
@@ -163,7 +182,7 @@ func (ex Experiment) PrintStatistics() {
 	mean_complexity, mean_diversity, mean_age, mean_fitness := 0.0, 0.0, 0.0, 0.0
 	if len(ex.Trials) > 1 {
 		avg_nodes, avg_genes, avg_evals, avg_divers := 0.0, 0.0, 0.0, 0.0
-		count := 0.0
+		count, best_count := 0.0, 0.0
 		for i := 0; i < len(ex.Trials); i++ {
 			t := ex.Trials[i]
 			if t.Solved() {
@@ -173,11 +192,16 @@ func (ex Experiment) PrintStatistics() {
 				avg_evals += float64(evals)
 				avg_divers += float64(diversity)
 
-				mean_complexity += float64(t.WinnerGeneration.Best.Phenotype.Complexity())
-				mean_age += float64(t.WinnerGeneration.Best.Species.Age)
-				mean_fitness += t.WinnerGeneration.Best.Fitness
-
 				count++
+
+				// the winner generation may have no best organism stored
+				if best := t.WinnerGeneration.Best; best != nil {
+					mean_complexity += float64(best.Phenotype.Complexity())
+					mean_age += float64(organismAge(best))
+					mean_fitness += best.Fitness
+
+					best_count++
+				}
 			}
 		}
 		avg_nodes /= count
@@ -187,9 +211,11 @@ func (ex Experiment) PrintStatistics() {
 		fmt.Printf("\nAverage among winners\n\tWinner Nodes:\t%.1f\n\tWinner Genes:\t%.1f\n\tWinner Evals:\t%.1f\n\n\tDiversity:\t%.1f\n",
 			avg_nodes, avg_genes, avg_evals, avg_divers)
 
-		mean_complexity /= count
-		mean_age /= count
-		mean_fitness /=count
+		if best_count > 0 {
+			mean_complexity /= best_count
+			mean_age /= best_count
+			mean_fitness /= best_count
+		}
 		fmt.Printf("\tComplexity:\t%.1f\n\tAge:\t\t%.1f\n\tFitness:\t%.1f\n",
 			mean_complexity, mean_age, mean_fitness)
 	}
@@ -211,55 +237,95 @@ func (ex Experiment) PrintStatistics() {
 	fmt.Printf("\nAverages for all organisms evaluated during experiment\n\tDiversity:\t%.1f\n\tComplexity:\t%.1f\n\tAge:\t\t%.1f\n\tFitness:\t%.1f\n\n",
 		mean_diversity, mean_complexity, mean_age, mean_fitness)
 
+	// Print the reproduction operators statistics
+	if op_stats := ex.OperatorStatistics(); op_stats.Offspring() > 0 {
+		fmt.Printf("Reproduction operators among all organisms evaluated during experiment\n%s\n", op_stats)
+	}
+
+	// Print the reasons of trials termination along with restarts of stagnated populations
+	reasons, restarts := make(map[TerminationReason]int), 0
+	for _, t := range ex.Trials {
+		if t.Termination != TerminationNone {
+			reasons[t.Termination]++
+		}
+		restarts += len(t.Restarts)
+	}
+	if len(reasons) > 0 {
+		fmt.Println("Trials terminated by")
+		for _, reason := range []TerminationReason{TerminationSolved, TerminationGenerations, TerminationFitnessTarget,
+			TerminationTimeBudget, TerminationEvaluationsBudget, TerminationStagnation, TerminationObserver} {
+			if reasons[reason] > 0 {
+				fmt.Printf("\t%s:\t%d\n", reason, reasons[reason])
+			}
+		}
+		fmt.Printf("Populations restarted:\t%d\n\n", restarts)
+	}
 }
 
-// Encodes experiment and writes to provided writer
+// Encodes experiment and writes to provided writer. The data starts with the header holding the format version.
 func (ex Experiment) Write(w io.Writer) error {
 	enc := gob.NewEncoder(w)
+	if err := writeHeader(w, enc); err != nil {
+		return err
+	}
 	return ex.Encode(enc)
 }
 
 // Encodes experiment with GOB encoding
 func (ex Experiment) Encode(enc *gob.Encoder) error {
-	err := enc.Encode(ex.Id)
-	err = enc.Encode(ex.Name)
-
-	// encode trials
-	err = enc.Encode(len(ex.Trials))
+	if err := encodeValues(enc, ex.Id, ex.Name, len(ex.Trials)); err != nil {
+		return err
+	}
 	for _, t := range ex.Trials {
-		err = t.Encode(enc)
-		if err != nil {
+		if err := t.Encode(enc); err != nil {
 			return err
 		}
 	}
-	return err
+	return nil
 }
 
-// Reads experiment data from provided reader and decodes it
+// Reads experiment data from provided reader and decodes it. The data written by the older versions without header
+// is also supported.
 func (ex *Experiment) Read(r io.Reader) error {
-	dec := gob.NewDecoder(r)
-	return ex.Decode(dec)
+	br := bufio.NewReader(r)
+	if !hasHeader(br) {
+		return ex.readLegacy(br)
+	}
+	dec := gob.NewDecoder(br)
+	version, err := readHeader(br, dec)
+	if err != nil {
+		return err
+	}
+	return ex.decode(dec, version)
 }
 
-// Decodes experiment data
+// Decodes experiment data encoded with the current format version
 func (ex *Experiment) Decode(dec *gob.Decoder) error {
-	err := dec.Decode(&ex.Id)
-	err = dec.Decode(&ex.Name)
+	return ex.decode(dec, FormatVersion)
+}
 
-	// decode Trials
+// Decodes experiment data encoded with given format version
+func (ex *Experiment) decode(dec *gob.Decoder, version int) error {
 	var t_num int
-	err = dec.Decode(&t_num)
-	if err != nil {
+	if err := decodeValues(dec, &ex.Id, &ex.Name, &t_num); err != nil {
 		return err
 	}
-
 	ex.Trials = make([]Trial, t_num)
-	for i := 0; i < t_num; i++ {
-		trial := Trial{}
-		err = trial.Decode(dec)
-		ex.Trials[i] = trial
+	for i := range ex.Trials {
+		if err := ex.Trials[i].decode(dec, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the age of species of the given organism or zero if species is unknown, e.g. when organism was loaded
+// from the legacy data.
+func organismAge(org *genetics.Organism) int {
+	if org.Species == nil {
+		return 0
 	}
-	return err
+	return org.Species.Age
 }
 
 // Experiments is a sortable list of experiments by execution time and Id
@@ -1,8 +1,14 @@
 package experiments
 
 import (
+	"os"
 	"testing"
 	"bytes"
+	"errors"
+	"math/rand"
+	"encoding/gob"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
 )
 
 func TestExperiment_Write_Read(t *testing.T) {
@@ -44,3 +50,366 @@ func TestExperiment_Write_Read(t *testing.T) {
 		deepCompareTrials(&ex.Trials[i], &new_ex.Trials[i], t)
 	}
 }
+
+func TestExperiment_ReadLegacy(t *testing.T) {
+	for _, version := range []int{formatLegacy, formatLegacyMetrics, formatLegacyOperators, formatLegacyIslands} {
+		ex := Experiment{Id:2, Name:"Test Legacy", Trials:make(Trials, 2)}
+		for i := range ex.Trials {
+			ex.Trials[i] = *buildTestTrial(i + 1, 3)
+			// no termination and restarts in the legacy formats
+			ex.Trials[i].Termination, ex.Trials[i].RestartPolicy, ex.Trials[i].Restarts = TerminationNone, neat.NoRestart, nil
+			for j := range ex.Trials[i].Generations {
+				// no species IDs, sizes and offspring events in the legacy formats
+				ex.Trials[i].Generations[j].SpeciesIds = nil
+				ex.Trials[i].Generations[j].SpeciesSizes = nil
+				ex.Trials[i].Generations[j].OffspringEvents = nil
+				if version < formatLegacyMetrics {
+					// no metrics in the original format
+					ex.Trials[i].Generations[j].Metrics = nil
+				}
+				if version < formatLegacyOperators {
+					// no operators statistics before they were collected
+					ex.Trials[i].Generations[j].Operators = nil
+				}
+			}
+		}
+		if version == formatLegacyIslands {
+			islands := []Generations{buildTestTrial(0, 2).Generations, buildTestTrial(0, 2).Generations}
+			for _, island := range islands {
+				for j := range island {
+					island[j].SpeciesIds, island[j].SpeciesSizes, island[j].OffspringEvents = nil, nil, nil
+				}
+			}
+			ex.Trials[1].Islands = islands
+		}
+
+		var buff bytes.Buffer
+		if err := encodeLegacyExperiment(&ex, version, gob.NewEncoder(&buff)); err != nil {
+			t.Fatal(err)
+		}
+		new_ex := Experiment{}
+		if err := new_ex.Read(&buff); err != nil {
+			t.Fatal("failed to read legacy experiment", version, err)
+		}
+		if ex.Id != new_ex.Id || ex.Name != new_ex.Name || len(ex.Trials) != len(new_ex.Trials) {
+			t.Fatal("Wrong legacy experiment", version, new_ex.Id, new_ex.Name, len(new_ex.Trials))
+		}
+		for i := range ex.Trials {
+			deepCompareTrials(&ex.Trials[i], &new_ex.Trials[i], t)
+		}
+		if new_ex.Trials[0].Generations[0].Best.Phenotype == nil {
+			t.Error("Phenotype of the best organism not restored", version)
+		}
+	}
+}
+
+// Reads the XOR experiment data of two trials of three generations written by the older releases
+func TestExperiment_ReadLegacyFiles(t *testing.T) {
+	files := []struct {
+		path      string
+		metrics   bool
+		operators bool
+		islands   int
+	}{
+		// written by the original release
+		{"../data/xor_legacy.dat", false, false, 0},
+		// written after population metrics were added to generation
+		{"../data/xor_legacy_metrics.dat", true, false, 0},
+		// written after reproduction operators statistics were added to generation
+		{"../data/xor_legacy_operators.dat", true, true, 0},
+		// written after islands were added to trial, by the island model of two islands
+		{"../data/xor_legacy_islands.dat", true, true, 2},
+	}
+	for _, f := range files {
+		file, err := os.Open(f.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ex := Experiment{}
+		err = ex.Read(file)
+		file.Close()
+		if err != nil {
+			t.Fatal("Failed to read legacy experiment", f.path, err)
+		}
+		if len(ex.Trials) != 2 {
+			t.Fatal("Wrong number of trials", f.path, len(ex.Trials))
+		}
+		for _, trial := range ex.Trials {
+			if len(trial.Generations) != 3 {
+				t.Fatal("Wrong number of generations", f.path, len(trial.Generations))
+			}
+			if len(trial.Islands) != f.islands {
+				t.Fatal("Wrong number of islands", f.path, len(trial.Islands))
+			}
+			for _, island := range trial.Islands {
+				if len(island) != 3 {
+					t.Error("Wrong number of island generations", f.path, len(island))
+				}
+			}
+			for _, epoch := range trial.Generations {
+				if epoch.Best == nil || epoch.Best.Phenotype == nil || len(epoch.Best.Genotype.Genes) == 0 {
+					t.Error("The best organism not restored", f.path, epoch.Id)
+				}
+				if (epoch.Metrics != nil) != f.metrics {
+					t.Error("Wrong population metrics", f.path, epoch.Id, epoch.Metrics)
+				}
+				// the initial generation has no offspring
+				if epoch.Id > 0 && (epoch.Operators != nil) != f.operators {
+					t.Error("Wrong operators statistics", f.path, epoch.Id, epoch.Operators)
+				}
+			}
+		}
+
+		// species of organisms are not stored in legacy data
+		for _, trial := range ex.Trials {
+			if fitness := trial.BestFitness(); len(fitness) != 3 {
+				t.Error("Wrong best fitness", f.path, fitness)
+			}
+			if complexity := trial.BestComplexity(); len(complexity) != 3 {
+				t.Error("Wrong best complexity", f.path, complexity)
+			}
+			if age := trial.BestAge(); len(age) != 0 {
+				t.Error("Wrong best age", f.path, age)
+			}
+		}
+		if age := ex.BestAge(); len(age) != 2 {
+			t.Error("Wrong experiment best age", f.path, age)
+		}
+		ex.Trials[0].Generations[2].Solved = true
+		ex.PrintStatistics()
+	}
+}
+
+func TestExperiment_ReadErrors(t *testing.T) {
+	ex := Experiment{Id:1, Name:"Test Errors", Trials:Trials{*buildTestTrial(1, 3)}}
+	var buff bytes.Buffer
+	if err := ex.Write(&buff); err != nil {
+		t.Fatal(err)
+	}
+	data := buff.Bytes()
+	if !bytes.HasPrefix(data, formatMagic) {
+		t.Error("No header in experiment data")
+	}
+
+	// truncated data
+	if err := (&Experiment{}).Read(bytes.NewReader(data[:len(data) / 2])); err == nil {
+		t.Error("Truncated experiment read")
+	}
+	// legacy data with trailing garbage
+	buff.Reset()
+	enc := gob.NewEncoder(&buff)
+	encodeLegacyExperiment(&ex, formatLegacy, enc)
+	enc.Encode("garbage")
+	if err := (&Experiment{}).Read(&buff); err == nil {
+		t.Error("Legacy experiment with trailing data read")
+	}
+	// unsupported version
+	buff.Reset()
+	buff.Write(formatMagic)
+	gob.NewEncoder(&buff).Encode(FormatVersion + 1)
+	if err := (&Experiment{}).Read(&buff); err == nil {
+		t.Error("Experiment with unsupported version read")
+	}
+}
+
+// Encodes experiment in the legacy format of given version as it was written by the older releases
+func encodeLegacyExperiment(ex *Experiment, version int, enc *gob.Encoder) error {
+	if err := encodeValues(enc, ex.Id, ex.Name, len(ex.Trials)); err != nil {
+		return err
+	}
+	for _, trial := range ex.Trials {
+		if err := enc.Encode(trial.Id); err != nil {
+			return err
+		}
+		if err := encodeLegacyGenerations(trial.Generations, version, enc); err != nil {
+			return err
+		}
+		if version < formatLegacyIslands {
+			continue
+		}
+		if err := enc.Encode(len(trial.Islands)); err != nil {
+			return err
+		}
+		for _, island := range trial.Islands {
+			if err := encodeLegacyGenerations(island, version, enc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Encodes generations in the legacy format of given version, the best organism is always present
+func encodeLegacyGenerations(generations Generations, version int, enc *gob.Encoder) error {
+	if err := enc.Encode(len(generations)); err != nil {
+		return err
+	}
+	for _, e := range generations {
+		err := encodeValues(enc, e.Id, e.Executed, e.Solved, e.Fitness, e.Age, e.Compexity, e.Diversity,
+			e.WinnerEvals, e.WinnerNodes, e.WinnerGenes)
+		if err == nil && version >= formatLegacyMetrics {
+			err = enc.Encode(e.Metrics)
+		}
+		if err == nil && version >= formatLegacyOperators {
+			err = enc.Encode(e.Operators)
+		}
+		if err != nil {
+			return err
+		}
+		var genome bytes.Buffer
+		e.Best.Genotype.Write(&genome)
+		err = encodeValues(enc, e.Best.Fitness, e.Best.OriginalFitness, e.Best.IsWinner, e.Best.Generation,
+			e.Best.ExpectedOffspring, e.Best.Error, e.Best.Genotype.Id, genome.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestExperiment_ExecuteTrackSpecies(t *testing.T) {
+	rand.Seed(42)
+	context := &neat.NeatContext{
+		CompatThreshold:0.5,
+		DropOffAge:15,
+		PopSize:40,
+		MutateOnlyProb:0.5,
+		MutateAddNodeProb:0.2,
+		MutateAddLinkProb:0.2,
+		MateMultipointProb:0.5,
+		MateMultipointAvgProb:0.5,
+		NewLinkTries:20,
+		WeightMutPower:1.0,
+		SurvivalThresh:0.2,
+		AgeSignificance:1.0,
+		NumRuns:1,
+		NumGenerations:5,
+	}
+	neat.LogLevel = neat.LogLevelWarning
+
+	ex := Experiment{TrackSpecies:true}
+	if err := ex.Execute(context, buildTestGenome(1), &randomFitnessEvaluator{}); err != nil {
+		t.Fatal(err)
+	}
+	history := ex.Trials[0].SpeciesHistory
+	if history == nil {
+		t.Fatal("Species history was not tracked")
+	}
+	// the species recorded per generation are the ones of the generation statistics
+	for _, epoch := range ex.Trials[0].Generations {
+		records := history.Generation(epoch.Id)
+		if len(records) != len(epoch.SpeciesIds) {
+			t.Fatal("Wrong number of species recorded", epoch.Id, len(records), len(epoch.SpeciesIds))
+		}
+		for i, r := range records {
+			if r.SpeciesId != epoch.SpeciesIds[i] || r.Size != epoch.SpeciesSizes[i] {
+				t.Error("Wrong species record", epoch.Id, r)
+			}
+		}
+	}
+}
+
+// The evaluator which advances population to the next epoch by itself, it records the latest generation of organisms
+// evaluated in each epoch
+type advancingEvaluator struct {
+	generations []int
+}
+
+func (e *advancingEvaluator) GenerationEvaluate(pop *genetics.Population, epoch *Generation, context *neat.NeatContext) error {
+	latest := 0
+	for _, org := range pop.Organisms {
+		org.Fitness = rand.Float64()
+		if org.Generation > latest {
+			latest = org.Generation
+		}
+	}
+	e.generations = append(e.generations, latest)
+	epoch.FillPopulationStatistics(pop)
+	return AdvanceEpoch(pop, epoch, context)
+}
+
+func TestExperiment_ExecuteAdvancingEvaluator(t *testing.T) {
+	rand.Seed(42)
+	neat.LogLevel = neat.LogLevelWarning
+	context := observerTestContext()
+	context.NumRuns = 1
+	evaluator := &advancingEvaluator{}
+	ex := Experiment{}
+	if err := ex.Execute(context, buildTestGenome(1), evaluator); err != nil {
+		t.Fatal(err)
+	}
+	if len(ex.Trials[0].Generations) != context.NumGenerations {
+		t.Error("Wrong number of generations", len(ex.Trials[0].Generations))
+	}
+	// the population advanced once per generation
+	for i, generation := range evaluator.generations {
+		if i > 0 && generation != i {
+			t.Error("Wrong generation of evaluated organisms", i, generation)
+		}
+	}
+
+	// the epochs of islands are driven by the execution
+	ex = Experiment{}
+	island_model := IslandModel{Islands:2, MigrationInterval:2, Migrants:1}
+	if err := ex.ExecuteIslands(context, buildTestGenome(1), evaluator, island_model); err == nil {
+		t.Error("Evaluator advancing island population should be rejected")
+	}
+}
+
+func TestOrganismsEvaluator(t *testing.T) {
+	rand.Seed(42)
+	neat.LogLevel = neat.LogLevelWarning
+	context := observerTestContext()
+	context.NumRuns = 1
+	evaluated := 0
+	evaluator := OrganismsEvaluator(func(organisms []*genetics.Organism) error {
+		for _, org := range organisms {
+			org.Fitness = float64(len(org.Genotype.Genes))
+			org.IsWinner = len(org.Genotype.Genes) > 12
+			evaluated++
+		}
+		return nil
+	})
+	ex := Experiment{}
+	if err := ex.Execute(context, buildTestGenome(1), evaluator); err != nil {
+		t.Fatal(err)
+	}
+	if evaluated == 0 || len(ex.Trials) != 1 || len(ex.Trials[0].Generations) == 0 {
+		t.Fatal("Experiment not executed", evaluated)
+	}
+	for _, generation := range ex.Trials[0].Generations {
+		if generation.Best == nil || len(generation.Fitness) == 0 {
+			t.Fatal("No statistics of generation", generation.Id)
+		}
+		if generation.Solved && !generation.Best.IsWinner {
+			t.Error("Generation solved without winner", generation.Id)
+		}
+	}
+
+	// the population is advanced by generation evaluation
+	pop, err := genetics.NewPopulation(buildTestGenome(1), context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	epoch := Generation{Id:0}
+	if err = evaluator.GenerationEvaluate(pop, &epoch, context); err != nil {
+		t.Fatal(err)
+	}
+	latest := 0
+	for _, org := range pop.Organisms {
+		if org.Generation > latest {
+			latest = org.Generation
+		}
+	}
+	if !epoch.Solved && latest != 1 {
+		t.Error("Population not advanced", latest)
+	}
+
+	// the evaluation error is reported
+	failing := OrganismsEvaluator(func(organisms []*genetics.Organism) error {
+		return errors.New("evaluation failed")
+	})
+	if err = failing.EpochEvaluate(pop, &Generation{Id:1}, context); err == nil {
+		t.Error("Evaluation error not reported")
+	}
+}
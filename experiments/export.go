@@ -0,0 +1,291 @@
+package experiments
+
+import (
+	"io"
+	"time"
+	"bytes"
+	"strconv"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/yaricom/goNEAT/neat/genetics"
+	"github.com/yaricom/goNEAT/neat/metrics"
+)
+
+// The columns of CSV with one row per trial and generation produced by exporters. The statistics of the best organism
+// left empty when not available, e.g. the age of species is not persisted with experiment data.
+var csvHeader = []string{
+	"trial", "generation", "executed", "solved", "diversity",
+	"best_fitness", "best_error", "best_nodes", "best_genes", "best_species_age",
+	"mean_fitness", "max_fitness", "mean_age", "mean_complexity",
+	"winner_evals", "winner_nodes", "winner_genes",
+}
+
+// Writes statistics of all generations of all trials in this experiment as CSV with header
+func (ex Experiment) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, t := range ex.Trials {
+		if err := t.writeCSVRecords(cw); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes statistics of all generations in this trial as CSV with header
+func (t Trial) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	if err := t.writeCSVRecords(cw); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes statistics of this generation as CSV with header
+func (epoch Generation) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	if err := cw.Write(epoch.csvRecord(epoch.TrialId)); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes CSV records for all generations in this trial, stops on the first write error
+func (t Trial) writeCSVRecords(cw *csv.Writer) error {
+	for _, e := range t.Generations {
+		if err := cw.Write(e.csvRecord(t.Id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns CSV record with statistics of this generation evaluated in given trial. The trial ID is passed explicitly
+// because it is not persisted with generation data.
+func (epoch Generation) csvRecord(trial_id int) []string {
+	fitness, age, complexity := epoch.Average()
+	max_fitness := 0.0
+	for i, f := range epoch.Fitness {
+		if i == 0 || f > max_fitness {
+			max_fitness = f
+		}
+	}
+	best_fitness, best_error, best_nodes, best_genes, best_age := "", "", "", "", ""
+	if epoch.Best != nil {
+		best_fitness = formatFloat(epoch.Best.Fitness)
+		best_error = formatFloat(epoch.Best.Error)
+		if epoch.Best.Genotype != nil {
+			best_nodes = strconv.Itoa(len(epoch.Best.Genotype.Nodes))
+			best_genes = strconv.Itoa(epoch.Best.Genotype.Extrons())
+		}
+		if epoch.Best.Species != nil {
+			best_age = strconv.Itoa(epoch.Best.Species.Age)
+		}
+	}
+	return []string{
+		strconv.Itoa(trial_id),
+		strconv.Itoa(epoch.Id),
+		epoch.Executed.Format(time.RFC3339),
+		strconv.FormatBool(epoch.Solved),
+		strconv.Itoa(epoch.Diversity),
+		best_fitness,
+		best_error,
+		best_nodes,
+		best_genes,
+		best_age,
+		formatFloat(fitness),
+		formatFloat(max_fitness),
+		formatFloat(age),
+		formatFloat(complexity),
+		strconv.Itoa(epoch.WinnerEvals),
+		strconv.Itoa(epoch.WinnerNodes),
+		strconv.Itoa(epoch.WinnerGenes),
+	}
+}
+
+// Formats float value for CSV output with the shortest representation
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// The JSON representation of the organism
+type organismJSON struct {
+	GenomeId   int     `json:"genome_id"`
+	Fitness    float64 `json:"fitness"`
+	Error      float64 `json:"error"`
+	IsWinner   bool    `json:"is_winner"`
+	Generation int     `json:"generation"`
+	Nodes      int     `json:"nodes"`
+	Genes      int     `json:"genes"`
+	// The genome in the plain text format
+	Genome     string  `json:"genome"`
+}
+
+// The JSON representation of reproduction operators statistics with operators keyed by name
+type operatorsJSON struct {
+	Operators    map[string]genetics.OperatorCounts `json:"operators"`
+	Interspecies genetics.OperatorCounts            `json:"interspecies"`
+	MutationOnly genetics.OperatorCounts            `json:"mutation_only"`
+}
+
+// The JSON representation of the generation
+type generationJSON struct {
+	Id              int                        `json:"id"`
+	TrialId         int                        `json:"trial_id"`
+	Executed        time.Time                  `json:"executed"`
+	Solved          bool                       `json:"solved"`
+	Fitness         Floats                     `json:"fitness"`
+	Age             Floats                     `json:"age"`
+	Complexity      Floats                     `json:"complexity"`
+	SpeciesIds      []int                      `json:"species_ids,omitempty"`
+	SpeciesSizes    []int                      `json:"species_sizes,omitempty"`
+	OffspringEvents []*genetics.OffspringEvent `json:"offspring_events,omitempty"`
+	Diversity       int                        `json:"diversity"`
+	Metrics         *metrics.PopulationMetrics `json:"metrics,omitempty"`
+	Operators       *operatorsJSON             `json:"operators,omitempty"`
+	WinnerEvals     int                        `json:"winner_evals"`
+	WinnerNodes     int                        `json:"winner_nodes"`
+	WinnerGenes     int                        `json:"winner_genes"`
+	Best            *organismJSON              `json:"best,omitempty"`
+}
+
+// The JSON representation of the trial
+type trialJSON struct {
+	Id             int                       `json:"id"`
+	Solved         bool                      `json:"solved"`
+	Generations    []generationJSON          `json:"generations"`
+	Islands        [][]generationJSON        `json:"islands,omitempty"`
+	Parasites      []generationJSON          `json:"parasites,omitempty"`
+	Termination    TerminationReason         `json:"termination,omitempty"`
+	RestartPolicy  string                    `json:"restart_policy"`
+	Restarts       []int                     `json:"restarts,omitempty"`
+	SpeciesHistory []*genetics.SpeciesRecord `json:"species_history,omitempty"`
+}
+
+// The JSON representation of the experiment
+type experimentJSON struct {
+	Id     int         `json:"id"`
+	Name   string      `json:"name"`
+	Trials []trialJSON `json:"trials"`
+}
+
+// Writes all data of this experiment as indented JSON including genomes of the best organisms
+func (ex Experiment) WriteJSON(w io.Writer) error {
+	ej := experimentJSON{
+		Id:ex.Id,
+		Name:ex.Name,
+		Trials:make([]trialJSON, len(ex.Trials)),
+	}
+	for i, t := range ex.Trials {
+		ej.Trials[i] = t.toJSON()
+	}
+	return writeJSON(w, ej)
+}
+
+// Writes all data of this trial as indented JSON including genomes of the best organisms
+func (t Trial) WriteJSON(w io.Writer) error {
+	return writeJSON(w, t.toJSON())
+}
+
+// Writes all data of this generation as indented JSON including genome of the best organism
+func (epoch Generation) WriteJSON(w io.Writer) error {
+	return writeJSON(w, epoch.toJSON())
+}
+
+// Encodes value as indented JSON into the writer
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// Returns JSON representation of this trial
+func (t Trial) toJSON() trialJSON {
+	tj := trialJSON{
+		Id:t.Id,
+		Solved:t.Solved(),
+		Generations:generationsToJSON(t.Generations, t.Id),
+		Termination:t.Termination,
+		RestartPolicy:t.RestartPolicy.String(),
+		Restarts:t.Restarts,
+	}
+	for _, island := range t.Islands {
+		tj.Islands = append(tj.Islands, generationsToJSON(island, t.Id))
+	}
+	if t.Parasites != nil {
+		tj.Parasites = generationsToJSON(t.Parasites, t.Id)
+	}
+	if t.SpeciesHistory != nil {
+		tj.SpeciesHistory = t.SpeciesHistory.Records
+	}
+	return tj
+}
+
+// Returns JSON representations of given generations evaluated in the trial with specified ID
+func generationsToJSON(generations Generations, trial_id int) []generationJSON {
+	gjs := make([]generationJSON, len(generations))
+	for i, e := range generations {
+		gjs[i] = e.toJSON()
+		gjs[i].TrialId = trial_id
+	}
+	return gjs
+}
+
+// Returns JSON representation of this generation
+func (epoch Generation) toJSON() generationJSON {
+	gj := generationJSON{
+		Id:epoch.Id,
+		TrialId:epoch.TrialId,
+		Executed:epoch.Executed,
+		Solved:epoch.Solved,
+		Fitness:epoch.Fitness,
+		Age:epoch.Age,
+		Complexity:epoch.Compexity,
+		SpeciesIds:epoch.SpeciesIds,
+		SpeciesSizes:epoch.SpeciesSizes,
+		OffspringEvents:epoch.OffspringEvents,
+		Diversity:epoch.Diversity,
+		Metrics:epoch.Metrics,
+		WinnerEvals:epoch.WinnerEvals,
+		WinnerNodes:epoch.WinnerNodes,
+		WinnerGenes:epoch.WinnerGenes,
+	}
+	if epoch.Operators != nil {
+		gj.Operators = &operatorsJSON{
+			Operators:make(map[string]genetics.OperatorCounts),
+			Interspecies:epoch.Operators.Interspecies,
+			MutationOnly:epoch.Operators.MutationOnly,
+		}
+		for op, counts := range epoch.Operators.Operators {
+			gj.Operators.Operators[genetics.ReproductionOperatorName(op)] = counts
+		}
+	}
+	if epoch.Best != nil {
+		gj.Best = &organismJSON{
+			Fitness:epoch.Best.Fitness,
+			Error:epoch.Best.Error,
+			IsWinner:epoch.Best.IsWinner,
+			Generation:epoch.Best.Generation,
+		}
+		if epoch.Best.Genotype != nil {
+			var buf bytes.Buffer
+			epoch.Best.Genotype.Write(&buf)
+			gj.Best.GenomeId = epoch.Best.Genotype.Id
+			gj.Best.Nodes = len(epoch.Best.Genotype.Nodes)
+			gj.Best.Genes = epoch.Best.Genotype.Extrons()
+			gj.Best.Genome = buf.String()
+		}
+	}
+	return gj
+}
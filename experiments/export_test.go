@@ -0,0 +1,160 @@
+package experiments
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+func TestExperiment_WriteCSV(t *testing.T) {
+	ex := Experiment{Id:1, Trials:Trials{*buildTestTrial(0, 3), *buildTestTrial(1, 2)}}
+	ex.Trials[1].Generations[1].Best = nil
+
+	var buf bytes.Buffer
+	if err := ex.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 6 {
+		t.Fatal("Wrong number of records", len(records))
+	}
+	if strings.Join(records[0], ",") != strings.Join(csvHeader, ",") {
+		t.Error("Wrong header", records[0])
+	}
+	column := make(map[string]int)
+	for i, name := range csvHeader {
+		column[name] = i
+	}
+	row := records[1]
+	if row[column["generation"]] != "1" || row[column["solved"]] != "true" || row[column["diversity"]] != "32" {
+		t.Error("Wrong generation statistics", row)
+	}
+	if row[column["best_nodes"]] != "4" || row[column["best_genes"]] != "3" || row[column["best_species_age"]] != "" {
+		t.Error("Wrong best organism statistics", row)
+	}
+	if row[column["max_fitness"]] != "40" || row[column["mean_age"]] != "4.5" {
+		t.Error("Wrong average statistics", row)
+	}
+	if row[column["winner_evals"]] != "12423" || row[column["winner_nodes"]] != "7" {
+		t.Error("Wrong winner statistics", row)
+	}
+	// the generation without best organism
+	if records[5][column["trial"]] != "1" || records[5][column["best_fitness"]] != "" {
+		t.Error("Wrong record without best organism", records[5])
+	}
+}
+
+func TestTrial_WriteCSV(t *testing.T) {
+	trial := buildTestTrial(2, 4)
+	var buf bytes.Buffer
+	if err := trial.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 5 {
+		t.Error("Wrong number of records", len(records))
+	}
+
+	buf.Reset()
+	if err = trial.Generations[0].WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if records, err = csv.NewReader(&buf).ReadAll(); err != nil || len(records) != 2 {
+		t.Error("Wrong generation records", records, err)
+	}
+}
+
+// The writer failing every write
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("write failed")
+}
+
+func TestExperiment_WriteCSVError(t *testing.T) {
+	ex := Experiment{Id:1, Name:"test", Trials:make(Trials, 10)}
+	for i := range ex.Trials {
+		ex.Trials[i] = *buildTestTrial(i, 20)
+	}
+	w := &failingWriter{}
+	if err := ex.WriteCSV(w); err == nil {
+		t.Error("Write error not reported")
+	}
+	if w.writes != 1 {
+		t.Error("Writing not stopped on the first error", w.writes)
+	}
+	if err := ex.Trials[0].WriteCSV(&failingWriter{}); err == nil {
+		t.Error("Trial write error not reported")
+	}
+	if err := ex.Trials[0].Generations[0].WriteCSV(&failingWriter{}); err == nil {
+		t.Error("Generation write error not reported")
+	}
+}
+
+func TestExperiment_WriteJSON(t *testing.T) {
+	ex := Experiment{Id:1, Name:"test", Trials:Trials{*buildTestTrial(0, 3)}}
+	ex.Trials[0].Islands = []Generations{ex.Trials[0].Generations, ex.Trials[0].Generations}
+	ex.Trials[0].Generations[2].Operators = nil
+	ex.Trials[0].Parasites = buildTestTrial(0, 2).Generations
+
+	var buf bytes.Buffer
+	if err := ex.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	ej := experimentJSON{}
+	if err := json.Unmarshal(buf.Bytes(), &ej); err != nil {
+		t.Fatal(err)
+	}
+	if ej.Id != 1 || ej.Name != "test" || len(ej.Trials) != 1 {
+		t.Fatal("Wrong experiment", ej.Id, ej.Name, len(ej.Trials))
+	}
+	tj := ej.Trials[0]
+	if !tj.Solved || len(tj.Generations) != 3 || len(tj.Islands) != 2 || len(tj.Parasites) != 2 {
+		t.Fatal("Wrong trial", tj.Solved, len(tj.Generations), len(tj.Islands), len(tj.Parasites))
+	}
+	gen, gj := ex.Trials[0].Generations[0], tj.Generations[0]
+	if gj.Id != gen.Id || !gj.Executed.Equal(gen.Executed) || gj.WinnerEvals != gen.WinnerEvals ||
+		len(gj.Fitness) != len(gen.Fitness) || gj.Metrics.Organisms != 1 {
+		t.Error("Wrong generation", gj)
+	}
+	if counts, ok := gj.Operators.Operators["mutate_add_node"]; !ok || counts.Offspring != 1 {
+		t.Error("Wrong operators statistics", gj.Operators)
+	}
+	if tj.Generations[2].Operators != nil {
+		t.Error("Operators statistics should be omitted", tj.Generations[2].Operators)
+	}
+	if len(gj.OffspringEvents) != 1 || gj.OffspringEvents[0].Type != genetics.BabyStealingEvent {
+		t.Error("Wrong offspring events", gj.OffspringEvents)
+	}
+
+	// the genome of the best organism
+	genome, err := genetics.ReadGenome(strings.NewReader(gj.Best.Genome), gj.Best.GenomeId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(genome.Genes) != len(gen.Best.Genotype.Genes) || gj.Best.Fitness != gen.Best.Fitness {
+		t.Error("Wrong best organism", gj.Best)
+	}
+
+	buf.Reset()
+	if err = ex.Trials[0].WriteJSON(&buf); err != nil || !json.Valid(buf.Bytes()) {
+		t.Error("Wrong trial JSON", err)
+	}
+	buf.Reset()
+	if err = gen.WriteJSON(&buf); err != nil || !json.Valid(buf.Bytes()) {
+		t.Error("Wrong generation JSON", err)
+	}
+}
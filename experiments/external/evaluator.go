@@ -0,0 +1,135 @@
+package external
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"errors"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+	"github.com/yaricom/goNEAT/experiments"
+)
+
+// The time to wait for external process to exit after its input closed
+const closeTimeout = 5 * time.Second
+
+// The generation evaluator which evaluates organisms by external processes. The processes are launched once per worker
+// on the first evaluation and reused afterwards. The process which crashed or not responded within timeout is killed
+// and restarted, the organism it failed to evaluate is sent to the new process. The processes should be stopped by Close
+// when evaluator no longer needed. The winner genome is not stored by evaluator, register experiments.PopulationDumper
+// observer with experiment to store it.
+type Evaluator struct {
+	// The command line of the external process: the path to executable followed by arguments
+	Command   []string
+	// The number of external processes evaluating organisms concurrently
+	Workers   int
+	// The maximal time to evaluate one organism, zero for no limit
+	Timeout   time.Duration
+	// The number of retries of failed organism evaluation
+	Retries   int
+
+	// The running processes, one per worker
+	processes []*process
+}
+
+// Creates new evaluator with given command line of external process
+func NewEvaluator(command []string, workers int, timeout time.Duration, retries int) *Evaluator {
+	return &Evaluator{
+		Command:command,
+		Workers:workers,
+		Timeout:timeout,
+		Retries:retries,
+	}
+}
+
+// Evaluates given organisms by external processes and sets fitness, error and winner flag of each organism
+func (e *Evaluator) Evaluate(organisms []*genetics.Organism) error {
+	workers := e.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	for len(e.processes) < workers {
+		e.processes = append(e.processes, nil)
+	}
+
+	jobs := make(chan *genetics.Organism, len(organisms))
+	for _, org := range organisms {
+		jobs <- org
+	}
+	close(jobs)
+
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for org := range jobs {
+				if errs[w] = e.evaluate(w, org); errs[w] != nil {
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Evaluates organism by the process of given worker, restarts the process if it failed
+func (e *Evaluator) evaluate(worker int, org *genetics.Organism) error {
+	req := NewRequest(org)
+	var err error
+	for attempt := 0; attempt <= e.Retries; attempt++ {
+		if e.processes[worker] == nil {
+			if e.processes[worker], err = startProcess(e.Command); err != nil {
+				return err
+			}
+		}
+		var resp *Response
+		if resp, err = e.processes[worker].evaluate(req, e.Timeout); err == nil {
+			org.Fitness = resp.Fitness
+			org.Error = resp.Error
+			org.IsWinner = resp.IsWinner
+			return nil
+		}
+
+		neat.WarnLog(fmt.Sprintf("EXTERNAL: Evaluation of organism [%d] failed, attempt: %d, reason: %s",
+			org.Genotype.Id, attempt + 1, err))
+		e.processes[worker].kill()
+		e.processes[worker] = nil
+	}
+	return errors.New(fmt.Sprintf("EXTERNAL: Failed to evaluate organism [%d] after %d attempts, reason: %s",
+		org.Genotype.Id, e.Retries + 1, err))
+}
+
+// Evaluates one epoch of given population by external processes and advances population to the next epoch if winner
+// not found
+func (e *Evaluator) GenerationEvaluate(pop *genetics.Population, epoch *experiments.Generation, context *neat.NeatContext) error {
+	return experiments.OrganismsEvaluator(e.Evaluate).GenerationEvaluate(pop, epoch, context)
+}
+
+// Evaluates one epoch of given population by external processes and collects statistics of the epoch
+func (e *Evaluator) EpochEvaluate(pop *genetics.Population, epoch *experiments.Generation, context *neat.NeatContext) error {
+	return experiments.OrganismsEvaluator(e.Evaluate).EpochEvaluate(pop, epoch, context)
+}
+
+// Stops all running external processes
+func (e *Evaluator) Close() error {
+	var err error
+	for i, p := range e.processes {
+		if p == nil {
+			continue
+		}
+		if p_err := p.close(closeTimeout); p_err != nil && err == nil {
+			err = p_err
+		}
+		e.processes[i] = nil
+	}
+	return err
+}
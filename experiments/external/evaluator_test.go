@@ -0,0 +1,194 @@
+package external
+
+import (
+	"os"
+	"fmt"
+	"time"
+	"bufio"
+	"strings"
+	"testing"
+	"math/rand"
+	"path/filepath"
+	"encoding/json"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+	"github.com/yaricom/goNEAT/experiments"
+)
+
+// The environment variables to control the helper process
+const (
+	helperProcessEnv = "GO_WANT_HELPER_PROCESS"
+	helperModeEnv = "EXTERNAL_HELPER_MODE"
+	helperMarkerEnv = "EXTERNAL_HELPER_MARKER"
+)
+
+// The external process used by tests. It assigns fitness and error based on the network structure. In "crash" and
+// "hang" modes the process crashes or hangs once on the request received when marker file exists, in "fail" mode it
+// responds with error message to each request and in "deaf" mode it hangs without reading requests.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv(helperProcessEnv) != "1" {
+		return
+	}
+	mode, marker := os.Getenv(helperModeEnv), os.Getenv(helperMarkerEnv)
+	if mode == "deaf" {
+		time.Sleep(time.Hour)
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64 * 1024), maxResponseLength)
+	for scanner.Scan() {
+		req := Request{}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if _, err := os.Stat(marker); err == nil && (mode == "crash" || mode == "hang") {
+			os.Remove(marker)
+			if mode == "crash" {
+				os.Exit(2)
+			}
+			time.Sleep(time.Hour)
+		}
+		resp := Response{
+			GenomeId:req.GenomeId,
+			Fitness:float64(len(req.Network.Links)),
+			Error:float64(len(req.Network.Nodes)),
+			IsWinner:len(req.Network.Links) > 4,
+		}
+		if mode == "fail" {
+			resp.Message = "evaluation failed"
+		}
+		line, _ := json.Marshal(resp)
+		fmt.Println(string(line))
+	}
+	os.Exit(0)
+}
+
+// Returns command line to start helper process in given mode, the marker file is created if requested
+func helperCommand(mode string, marker bool, t *testing.T) []string {
+	t.Setenv(helperProcessEnv, "1")
+	t.Setenv(helperModeEnv, mode)
+	marker_path := filepath.Join(t.TempDir(), "marker")
+	t.Setenv(helperMarkerEnv, marker_path)
+	if marker {
+		if err := os.WriteFile(marker_path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return []string{os.Args[0], "-test.run=TestHelperProcess", "--"}
+}
+
+// Creates test organisms of random population, the networks have hidden nodes and recurrent links to be encoded in
+// requests
+func buildTestOrganisms(count int, t *testing.T) []*genetics.Organism {
+	pop, err := genetics.NewPopulationRandom(3, 2, 3, true, 0.5, &neat.NeatContext{CompatThreshold:0.5, PopSize:count})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pop.Organisms
+}
+
+// Checks that organisms evaluated by helper process
+func checkEvaluated(orgs []*genetics.Organism, t *testing.T) {
+	for _, org := range orgs {
+		links := 0
+		for _, g := range org.Genotype.Genes {
+			if g.IsEnabled {
+				links++
+			}
+		}
+		if org.Fitness != float64(links) || org.Error != float64(len(org.Genotype.Nodes)) || org.IsWinner != (links > 4) {
+			t.Error("Organism not evaluated", org.Genotype.Id, org.Fitness, org.Error)
+		}
+	}
+}
+
+func TestEvaluator_Evaluate(t *testing.T) {
+	rand.Seed(42)
+	neat.LogLevel = neat.LogLevelError
+	evaluator := NewEvaluator(helperCommand("", false, t), 2, 5 * time.Second, 1)
+	defer evaluator.Close()
+
+	// the processes are reused between evaluations
+	for i := 0; i < 2; i++ {
+		orgs := buildTestOrganisms(10, t)
+		if err := evaluator.Evaluate(orgs); err != nil {
+			t.Fatal(err)
+		}
+		checkEvaluated(orgs, t)
+	}
+	if err := evaluator.Close(); err != nil {
+		t.Error("Failed to close evaluator", err)
+	}
+}
+
+func TestEvaluator_EvaluateRecovery(t *testing.T) {
+	rand.Seed(42)
+	neat.LogLevel = neat.LogLevelError
+	for _, mode := range []string{"crash", "hang"} {
+		evaluator := NewEvaluator(helperCommand(mode, true, t), 1, 500 * time.Millisecond, 1)
+		orgs := buildTestOrganisms(5, t)
+		if err := evaluator.Evaluate(orgs); err != nil {
+			t.Error(mode, err)
+		}
+		checkEvaluated(orgs, t)
+		evaluator.Close()
+	}
+}
+
+func TestProcess_evaluateNotReading(t *testing.T) {
+	neat.LogLevel = neat.LogLevelError
+	p, err := startProcess(helperCommand("deaf", false, t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.kill()
+
+	// the request larger than the pipe buffer can not be written until process reads it
+	req := &Request{GenomeId:1, Genome:strings.Repeat("gene ", 1024 * 1024)}
+	start := time.Now()
+	if _, err = p.evaluate(req, 200 * time.Millisecond); err == nil {
+		t.Error("The request should not be written to the process which is not reading")
+	}
+	if elapsed := time.Since(start); elapsed > 5 * time.Second {
+		t.Error("The timeout is not respected while writing request", elapsed)
+	}
+}
+
+func TestEvaluator_EvaluateFailed(t *testing.T) {
+	rand.Seed(42)
+	neat.LogLevel = neat.LogLevelError
+	evaluator := NewEvaluator(helperCommand("fail", false, t), 1, time.Second, 2)
+	if err := evaluator.Evaluate(buildTestOrganisms(3, t)); err == nil {
+		t.Error("Failed evaluation not reported")
+	}
+	evaluator.Close()
+
+	evaluator = NewEvaluator([]string{filepath.Join(t.TempDir(), "nonexistent")}, 1, time.Second, 2)
+	if err := evaluator.Evaluate(buildTestOrganisms(1, t)); err == nil {
+		t.Error("Evaluation by nonexistent command succeeded")
+	}
+}
+
+func TestEvaluator_EpochEvaluate(t *testing.T) {
+	rand.Seed(42)
+	neat.LogLevel = neat.LogLevelError
+	evaluator := NewEvaluator(helperCommand("", false, t), 2, 5 * time.Second, 1)
+	defer evaluator.Close()
+
+	context := &neat.NeatContext{CompatThreshold:0.5, PopSize:20}
+	pop, err := genetics.NewPopulationRandom(3, 2, 3, true, 0.5, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	epoch := experiments.Generation{Id:1}
+	if err = evaluator.EpochEvaluate(pop, &epoch, context); err != nil {
+		t.Fatal(err)
+	}
+	checkEvaluated(pop.Organisms, t)
+	if epoch.Best == nil {
+		t.Fatal("No best organism of generation")
+	}
+	if epoch.Solved && !epoch.Best.IsWinner {
+		t.Error("Generation solved without winner", epoch.Best.Fitness)
+	}
+}
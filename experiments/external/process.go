@@ -0,0 +1,153 @@
+package external
+
+import (
+	"os"
+	"io"
+	"fmt"
+	"time"
+	"bufio"
+	"errors"
+	"os/exec"
+	"encoding/json"
+	"github.com/yaricom/goNEAT/neat"
+)
+
+// The maximal length of response line read from external process
+const maxResponseLength = 1024 * 1024
+
+// The external process evaluating organisms sent to its standard input
+type process struct {
+	// The running command
+	cmd       *exec.Cmd
+	// The standard input of the process
+	stdin     io.WriteCloser
+	// The response lines read from standard output of the process, closed when output ends
+	responses chan []byte
+}
+
+// Starts external process with given command line
+func startProcess(command []string) (*process, error) {
+	if len(command) == 0 {
+		return nil, errors.New("EXTERNAL: The command of external process is empty")
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := process{
+		cmd:cmd,
+		stdin:stdin,
+		responses:make(chan []byte),
+	}
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64 * 1024), maxResponseLength)
+		for scanner.Scan() {
+			line := make([]byte, len(scanner.Bytes()))
+			copy(line, scanner.Bytes())
+			p.responses <- line
+		}
+		close(p.responses)
+	}()
+	neat.DebugLog(fmt.Sprintf("EXTERNAL: Process [%d] started: %v", cmd.Process.Pid, command))
+	return &p, nil
+}
+
+// Sends request to the process and waits for the response no longer than given timeout, the timeout covers both
+// writing of request and reading of response. If timeout is zero waits until process responds or exits.
+func (p *process) evaluate(req *Request, timeout time.Duration) (*Response, error) {
+	line, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	// the request is written in background, thus the process which stopped reading its input can not block the
+	// evaluation beyond the timeout. The pending write fails when the process is killed.
+	written := make(chan error, 1)
+	go func() {
+		_, err := p.stdin.Write(append(line, '\n'))
+		written <- err
+	}()
+	select {
+	case err = <-written:
+		if err != nil {
+			return nil, err
+		}
+	case <-expired:
+		return nil, errors.New(fmt.Sprintf("Process [%d] not read request within %s", p.cmd.Process.Pid, timeout))
+	}
+
+	select {
+	case line, ok := <-p.responses:
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("Process [%d] exited", p.cmd.Process.Pid))
+		}
+		resp := Response{}
+		if err = json.Unmarshal(line, &resp); err != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to decode response: [%s], reason: %s", line, err))
+		}
+		if len(resp.Message) > 0 {
+			return nil, errors.New(fmt.Sprintf("Evaluation failed: %s", resp.Message))
+		}
+		if resp.GenomeId != req.GenomeId {
+			return nil, errors.New(fmt.Sprintf("Genome ID mismatch in response. Found: %d, expected: %d",
+				resp.GenomeId, req.GenomeId))
+		}
+		return &resp, nil
+	case <-expired:
+		return nil, errors.New(fmt.Sprintf("Process [%d] not responded within %s", p.cmd.Process.Pid, timeout))
+	}
+}
+
+// Kills the process and releases its resources
+func (p *process) kill() {
+	p.stdin.Close()
+	p.cmd.Process.Kill()
+	p.wait()
+}
+
+// Closes standard input of the process and waits for it to exit, the process will be killed if not exited within given
+// timeout.
+func (p *process) close(timeout time.Duration) error {
+	p.stdin.Close()
+	select {
+	case <-p.drain():
+	case <-time.After(timeout):
+		neat.WarnLog(fmt.Sprintf("EXTERNAL: Process [%d] not exited, killing it", p.cmd.Process.Pid))
+		p.cmd.Process.Kill()
+	}
+	return p.wait()
+}
+
+// Waits for the process to exit after its output drained
+func (p *process) wait() error {
+	<-p.drain()
+	return p.cmd.Wait()
+}
+
+// Discards the remaining responses of the process, the returned channel is closed when output of the process ends
+func (p *process) drain() <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		for range p.responses {
+		}
+		close(done)
+	}()
+	return done
+}
@@ -0,0 +1,113 @@
+// The external package provides evaluation of organisms by external processes (e.g. simulators written in Python or
+// C++) communicating over standard input and output streams. Each organism is sent to the process as one line of JSON
+// and the process should respond with one line of JSON with the results of evaluation.
+//
+// The request line holds the genome in the plain text format (see Genome.Write) along with the network described by
+// its nodes and enabled links:
+//
+//	{"genome_id":1,"genome":"genomestart 1\n...","network":{"nodes":[{"id":1,"type":"BIAS","bias":0,
+//	"time_constant":1}],"links":[{"in":1,"out":4,"weight":0.5,"recurrent":false}]}}
+//
+// The neurons use the steepened sigmoid activation function: 1 / (1 + exp(-4.924273 * x)), where x is the sum of
+// weighted inputs and bias of the neuron. The response line should hold results of evaluation of the same genome:
+//
+//	{"genome_id":1,"fitness":0.95,"error":0.05,"is_winner":false}
+//
+// The process may respond with non empty "message" field to indicate that evaluation failed.
+package external
+
+import (
+	"bytes"
+	"github.com/yaricom/goNEAT/neat/genetics"
+	"github.com/yaricom/goNEAT/neat/network"
+)
+
+// The node of network sent to the external process
+type Node struct {
+	// The ID of the node
+	Id           int     `json:"id"`
+	// The neuron type of the node: INPUT, BIAS, HIDDEN or OUTPUT
+	Type         string  `json:"type"`
+	// The bias of the neuron
+	Bias         float64 `json:"bias"`
+	// The time constant of the neuron used by CTRNN activation
+	TimeConstant float64 `json:"time_constant"`
+}
+
+// The link of network sent to the external process
+type Link struct {
+	// The ID of the input node
+	In        int     `json:"in"`
+	// The ID of the output node
+	Out       int     `json:"out"`
+	// The weight of the link
+	Weight    float64 `json:"weight"`
+	// The flag to indicate whether link is recurrent
+	Recurrent bool    `json:"recurrent"`
+}
+
+// The network of organism sent to the external process
+type Network struct {
+	// The nodes of the network
+	Nodes []Node `json:"nodes"`
+	// The enabled links of the network
+	Links []Link `json:"links"`
+}
+
+// The request to evaluate one organism sent to the external process
+type Request struct {
+	// The ID of organism's genome
+	GenomeId int     `json:"genome_id"`
+	// The organism's genome serialized in the plain text format
+	Genome   string  `json:"genome"`
+	// The organism's network
+	Network  Network `json:"network"`
+}
+
+// The results of organism evaluation sent back by the external process
+type Response struct {
+	// The ID of evaluated organism's genome
+	GenomeId int     `json:"genome_id"`
+	// The fitness of organism
+	Fitness  float64 `json:"fitness"`
+	// The error value of organism
+	Error    float64 `json:"error"`
+	// The flag to indicate whether organism is a winner
+	IsWinner bool    `json:"is_winner"`
+	// The error message if evaluation failed
+	Message  string  `json:"message,omitempty"`
+}
+
+// Creates request to evaluate given organism
+func NewRequest(org *genetics.Organism) *Request {
+	var buf bytes.Buffer
+	org.Genotype.Write(&buf)
+
+	net := Network{
+		Nodes:make([]Node, len(org.Genotype.Nodes)),
+		Links:make([]Link, 0, len(org.Genotype.Genes)),
+	}
+	for i, n := range org.Genotype.Nodes {
+		net.Nodes[i] = Node{
+			Id:n.Id,
+			Type:network.NeuronTypeName(n.NeuronType),
+			Bias:n.Bias,
+			TimeConstant:n.TimeConstant,
+		}
+	}
+	for _, g := range org.Genotype.Genes {
+		if g.IsEnabled {
+			net.Links = append(net.Links, Link{
+				In:g.Link.InNode.Id,
+				Out:g.Link.OutNode.Id,
+				Weight:g.Link.Weight,
+				Recurrent:g.Link.IsRecurrent,
+			})
+		}
+	}
+	return &Request{
+		GenomeId:org.Genotype.Id,
+		Genome:buf.String(),
+		Network:net,
+	}
+}
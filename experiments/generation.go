@@ -6,40 +6,51 @@ import (
 	"math"
 	"encoding/gob"
 	"bytes"
-	"reflect"
+	"errors"
 	"sort"
+	"github.com/yaricom/goNEAT/neat/metrics"
 )
 
 // The structure to represent execution results of one generation
 type Generation struct {
 	// The generation ID for this epoch
-	Id          int
+	Id           int
 	// The time when epoch was evaluated
-	Executed    time.Time
+	Executed     time.Time
 	// The best organism of best species
-	Best        *genetics.Organism
+	Best         *genetics.Organism
 	// The flag to indicate whether experiment was solved in this epoch
-	Solved      bool
+	Solved       bool
 
 	// The list of organisms fitness values per species in population
-	Fitness     Floats
+	Fitness      Floats
 	// The age of organisms per species in population
-	Age         Floats
+	Age          Floats
 	// The list of organisms complexities per species in population
-	Compexity   Floats
+	Compexity    Floats
+	// The list of IDs of species in population
+	SpeciesIds   []int
+	// The number of organisms per species in population
+	SpeciesSizes []int
 
 	// The number of species in population at the end of this epoch
-	Diversity   int
+	Diversity       int
+	// The average complexity metrics of organisms in population
+	Metrics         *metrics.PopulationMetrics
+	// The statistics of reproduction operators which produced organisms of this generation
+	Operators       *genetics.OperatorStatistics
+	// The offspring reallocation events, e.g. delta coding, happened in epoch which produced this generation
+	OffspringEvents []*genetics.OffspringEvent
 
 	// The number of evaluations done before winner found
-	WinnerEvals int
+	WinnerEvals  int
 	// The number of nodes in winner genome or zero if not solved
-	WinnerNodes int
+	WinnerNodes  int
 	// The numbers of genes (links) in winner genome or zero if not solved
-	WinnerGenes int
+	WinnerGenes  int
 
 	// The ID of Trial this Generation was evaluated in
-	TrialId     int
+	TrialId      int
 }
 
 // Collects statistics about given population
@@ -49,10 +60,17 @@ func (epoch *Generation) FillPopulationStatistics(pop *genetics.Population) {
 	epoch.Age = make(Floats, epoch.Diversity)
 	epoch.Compexity = make(Floats, epoch.Diversity)
 	epoch.Fitness = make(Floats, epoch.Diversity)
+	epoch.SpeciesIds = make([]int, epoch.Diversity)
+	epoch.SpeciesSizes = make([]int, epoch.Diversity)
+	epoch.Metrics = metrics.NewPopulationMetrics(pop.Organisms)
+	epoch.Operators = genetics.CollectOperatorStatistics(pop.Organisms)
+	epoch.OffspringEvents = pop.OffspringEvents
 	for i, curr_species := range pop.Species {
 		epoch.Age[i] = float64(curr_species.Age)
 		epoch.Compexity[i] = float64(curr_species.Organisms[0].Phenotype.Complexity())
 		epoch.Fitness[i] = curr_species.Organisms[0].Fitness
+		epoch.SpeciesIds[i] = curr_species.Id
+		epoch.SpeciesSizes[i] = len(curr_species.Organisms)
 
 		// find best organism in epoch if not solved
 		if !epoch.Solved {
@@ -66,6 +84,21 @@ func (epoch *Generation) FillPopulationStatistics(pop *genetics.Population) {
 	}
 }
 
+// Finds the most fit winner among organisms of evaluated population and stores its statistics into this epoch. The
+// number of evaluations assumes that organisms evaluated in order of their genome IDs. Returns true if winner found.
+func (epoch *Generation) FillWinnerStatistics(pop *genetics.Population, popSize int) bool {
+	for _, org := range pop.Organisms {
+		if org.IsWinner && (!epoch.Solved || org.Fitness > epoch.Best.Fitness) {
+			epoch.Solved = true
+			epoch.WinnerNodes = len(org.Genotype.Nodes)
+			epoch.WinnerGenes = org.Genotype.Extrons()
+			epoch.WinnerEvals = popSize * epoch.Id + org.Genotype.Id
+			epoch.Best = org
+		}
+	}
+	return epoch.Solved
+}
+
 // Returns average fitness, age, and complexity among all organisms from population at the end of this epoch
 func (epoch Generation) Average() (fitness, age, complexity float64) {
 	fitness = epoch.Fitness.Mean()
@@ -76,97 +109,149 @@ func (epoch Generation) Average() (fitness, age, complexity float64) {
 
 // Encodes generation with provided GOB encoder
 func (epoch Generation) Encode(enc *gob.Encoder) error {
-	err := enc.EncodeValue(reflect.ValueOf(epoch.Id))
-	err = enc.EncodeValue(reflect.ValueOf(epoch.Executed))
-	err = enc.EncodeValue(reflect.ValueOf(epoch.Solved))
-	err = enc.EncodeValue(reflect.ValueOf(epoch.Fitness))
-	err = enc.EncodeValue(reflect.ValueOf(epoch.Age))
-	err = enc.EncodeValue(reflect.ValueOf(epoch.Compexity))
-	err = enc.EncodeValue(reflect.ValueOf(epoch.Diversity))
-	err = enc.EncodeValue(reflect.ValueOf(epoch.WinnerEvals))
-	err = enc.EncodeValue(reflect.ValueOf(epoch.WinnerNodes))
-	err = enc.EncodeValue(reflect.ValueOf(epoch.WinnerGenes))
-
+	err := encodeValues(enc, epoch.Id, epoch.Executed, epoch.Solved, epoch.Fitness, epoch.Age, epoch.Compexity,
+		epoch.Diversity, epoch.WinnerEvals, epoch.WinnerNodes, epoch.WinnerGenes)
+	if err != nil {
+		return err
+	}
+	pop_metrics := epoch.Metrics
+	if pop_metrics == nil {
+		pop_metrics = &metrics.PopulationMetrics{}
+	}
+	op_stats := epoch.Operators
+	if op_stats == nil {
+		op_stats = genetics.NewOperatorStatistics()
+	}
+	err = encodeValues(enc, pop_metrics, op_stats, epoch.SpeciesIds, epoch.SpeciesSizes, epoch.OffspringEvents,
+		epoch.Best != nil)
 	if err != nil {
 		return err
 	}
 
 	// encode best organism
 	if epoch.Best != nil {
-		err = encodeOrganism(enc, epoch.Best)
+		return encodeOrganism(enc, epoch.Best)
 	}
-	return err
+	return nil
 }
 
 func encodeOrganism(enc *gob.Encoder, org *genetics.Organism) error {
-	err := enc.Encode(org.Fitness)
-	err = enc.Encode(org.OriginalFitness)
-	err = enc.Encode(org.IsWinner)
-	err = enc.Encode(org.Generation)
-	err = enc.Encode(org.ExpectedOffspring)
-	err = enc.Encode(org.Error)
-
+	if org.Genotype == nil {
+		return errors.New("Organism without genome can not be encoded")
+	}
+	err := encodeValues(enc, org.Fitness, org.OriginalFitness, org.IsWinner, org.Generation, org.ExpectedOffspring,
+		org.Error)
 	if err != nil {
 		return err
 	}
 
 	// encode organism genome
-	if org.Genotype != nil {
-		err = enc.Encode(org.Genotype.Id)
-		out_buf := bytes.NewBufferString("")
-		org.Genotype.Write(out_buf)
-		err = enc.Encode(out_buf.Bytes())
+	out_buf := bytes.NewBufferString("")
+	org.Genotype.Write(out_buf)
+	if err = encodeValues(enc, org.Genotype.Id, out_buf.Bytes()); err != nil {
+		return err
 	}
 
-	return err
+	// encode ID and age of organism's species
+	if org.Species != nil {
+		return encodeValues(enc, true, org.Species.Id, org.Species.Age)
+	}
+	return enc.Encode(false)
 }
 
+// Decodes generation encoded with the current format version
 func (epoch *Generation) Decode(dec *gob.Decoder) error {
-	err := dec.Decode(&epoch.Id)
-	err = dec.Decode(&epoch.Executed)
-	err = dec.Decode(&epoch.Solved)
-	err = dec.Decode(&epoch.Fitness)
-	err = dec.Decode(&epoch.Age)
-	err = dec.Decode(&epoch.Compexity)
-	err = dec.Decode(&epoch.Diversity)
-	err = dec.Decode(&epoch.WinnerEvals)
-	err = dec.Decode(&epoch.WinnerNodes)
-	err = dec.Decode(&epoch.WinnerGenes)
+	return epoch.decode(dec, FormatVersion)
+}
 
+// Decodes generation encoded with given format version
+func (epoch *Generation) decode(dec *gob.Decoder, version int) error {
+	err := decodeValues(dec, &epoch.Id, &epoch.Executed, &epoch.Solved, &epoch.Fitness, &epoch.Age, &epoch.Compexity,
+		&epoch.Diversity, &epoch.WinnerEvals, &epoch.WinnerNodes, &epoch.WinnerGenes)
 	if err != nil {
 		return err
 	}
+	if version >= formatLegacyMetrics {
+		pop_metrics := metrics.PopulationMetrics{}
+		if err = dec.Decode(&pop_metrics); err != nil {
+			return err
+		}
+		if pop_metrics.Organisms > 0 {
+			epoch.Metrics = &pop_metrics
+		}
+	}
+	if version >= formatLegacyOperators {
+		op_stats := genetics.NewOperatorStatistics()
+		if err = dec.Decode(op_stats); err != nil {
+			return err
+		}
+		if op_stats.Offspring() > 0 {
+			epoch.Operators = op_stats
+		}
+	}
+	if version >= formatVersion3 {
+		if err = decodeValues(dec, &epoch.SpeciesIds, &epoch.SpeciesSizes); err != nil {
+			return err
+		}
+	}
+	if version >= formatVersion5 {
+		if err = dec.Decode(&epoch.OffspringEvents); err != nil {
+			return err
+		}
+	}
 
-	// decode organism
-	org, err := decodeOrganism(dec)
-	if err == nil {
-		epoch.Best = org
+	// the best organism is always present in the legacy formats
+	has_best := true
+	if version >= formatVersion2 {
+		if err = dec.Decode(&has_best); err != nil {
+			return err
+		}
+	}
+	if has_best {
+		epoch.Best, err = decodeOrganism(dec, version)
 	}
 	return err
 }
 
-func decodeOrganism(dec *gob.Decoder) (*genetics.Organism, error) {
-	org := genetics.Organism{}
-	err := dec.Decode(&org.Fitness)
-	err = dec.Decode(&org.OriginalFitness)
-	err = dec.Decode(&org.IsWinner)
-	err = dec.Decode(&org.Generation)
-	err = dec.Decode(&org.ExpectedOffspring)
-	err = dec.Decode(&org.Error)
-
+// Decodes organism encoded with given format version. The phenotype of organism is built from decoded genome and
+// the species restored with ID and age of the organism's species, if they were encoded.
+func decodeOrganism(dec *gob.Decoder, version int) (*genetics.Organism, error) {
+	var fitness, original_fitness, expected_offspring, org_error float64
+	var is_winner bool
+	var generation, gen_id int
+	var data []byte
+	err := decodeValues(dec, &fitness, &original_fitness, &is_winner, &generation, &expected_offspring, &org_error,
+		&gen_id, &data)
 	if err != nil {
 		return nil, err
 	}
 
 	// decode organism genome
-	var gen_id int
-	err = dec.Decode(&gen_id)
-	var data []byte
-	err = dec.Decode(&data)
 	gen, err := genetics.ReadGenome(bytes.NewBuffer(data), gen_id)
-	org.Genotype = gen
+	if err != nil {
+		return nil, err
+	}
+	org := genetics.NewOrganism(fitness, gen, generation)
+	org.OriginalFitness = original_fitness
+	org.IsWinner = is_winner
+	org.ExpectedOffspring = expected_offspring
+	org.Error = org_error
 
-	return &org, err
+	if version < formatVersion2 {
+		return org, nil
+	}
+	var has_species bool
+	if err = dec.Decode(&has_species); err != nil || !has_species {
+		return org, err
+	}
+	var species_id, species_age int
+	if err = decodeValues(dec, &species_id, &species_age); err != nil {
+		return nil, err
+	}
+	org.Species = genetics.NewSpecies(species_id)
+	org.Species.Age = species_age
+	org.Species.Organisms = append(org.Species.Organisms, org)
+	return org, nil
 }
 
 // Generations is a sortable collection of generations by execution time and Id
@@ -10,6 +10,8 @@ import (
 	"testing"
 	"time"
 	"reflect"
+	"math/rand"
+	"github.com/yaricom/goNEAT/neat/metrics"
 )
 
 // Tests encoding/decoding of generation
@@ -41,6 +43,99 @@ func TestGeneration_Encode_Decode(t *testing.T) {
 	deepCompareGenerations(gen, dgen, t)
 }
 
+// Tests winner statistics collection
+func TestGeneration_FillWinnerStatistics(t *testing.T) {
+	pop := &genetics.Population{Organisms:make([]*genetics.Organism, 3)}
+	for i := range pop.Organisms {
+		pop.Organisms[i] = genetics.NewOrganism(float64(i), buildTestGenome(i + 1), 1)
+	}
+	gen := Generation{Id:2}
+	if gen.FillWinnerStatistics(pop, 3) {
+		t.Error("Solved without winner")
+	}
+
+	pop.Organisms[0].IsWinner = true
+	pop.Organisms[1].IsWinner = true
+	if !gen.FillWinnerStatistics(pop, 3) {
+		t.Error("Not solved with winner")
+	}
+	// the most fit winner
+	if gen.Best != pop.Organisms[1] || gen.WinnerEvals != 3 * 2 + 2 {
+		t.Error("Wrong winner statistics", gen.Best, gen.WinnerEvals)
+	}
+	if gen.WinnerNodes != len(pop.Organisms[1].Genotype.Nodes) || gen.WinnerGenes != pop.Organisms[1].Genotype.Extrons() {
+		t.Error("Wrong winner structure", gen.WinnerNodes, gen.WinnerGenes)
+	}
+}
+
+// Tests encoding/decoding of generation without the best organism
+func TestGeneration_Encode_DecodeNoBest(t *testing.T) {
+	gen := buildTestGeneration(3, 12.0)
+	gen.Best, gen.Metrics, gen.Operators = nil, nil, nil
+
+	var buff bytes.Buffer
+	if err := gen.Encode(gob.NewEncoder(&buff)); err != nil {
+		t.Fatal("failed to encode generation", err)
+	}
+	dgen := &Generation{}
+	if err := dgen.Decode(gob.NewDecoder(&buff)); err != nil {
+		t.Fatal("failed to decode generation", err)
+	}
+	deepCompareGenerations(gen, dgen, t)
+}
+
+// Tests that phenotype and species of the best organism restored after decoding
+func TestGeneration_Encode_DecodeBestOrganism(t *testing.T) {
+	gen := buildTestGeneration(4, 15.0)
+	species := genetics.NewSpecies(7)
+	species.Age = 12
+	gen.Best.Species = species
+	gen.Best.IsWinner = true
+	gen.Best.Error = 0.1
+
+	var buff bytes.Buffer
+	if err := gen.Encode(gob.NewEncoder(&buff)); err != nil {
+		t.Fatal("failed to encode generation", err)
+	}
+	dgen := &Generation{}
+	if err := dgen.Decode(gob.NewDecoder(&buff)); err != nil {
+		t.Fatal("failed to decode generation", err)
+	}
+	best := dgen.Best
+	if best.Phenotype == nil || best.Phenotype.NodeCount() != len(gen.Best.Genotype.Nodes) ||
+		best.Phenotype.LinkCount() != len(gen.Best.Genotype.Genes) {
+		t.Error("Phenotype of the best organism not restored", best.Phenotype)
+	}
+	if best.Species == nil || best.Species.Id != 7 || best.Species.Age != 12 || len(best.Species.Organisms) != 1 {
+		t.Error("Species of the best organism not restored", best.Species)
+	}
+	if !best.IsWinner || best.Error != 0.1 || best.Generation != gen.Best.Generation {
+		t.Error("Wrong best organism", best)
+	}
+}
+
+// Tests that encoding errors are reported
+func TestGeneration_EncodeErrors(t *testing.T) {
+	gen := buildTestGeneration(5, 1.0)
+	gen.Best.Genotype = nil
+	if err := gen.Encode(gob.NewEncoder(&bytes.Buffer{})); err == nil {
+		t.Error("Organism without genome encoded")
+	}
+
+	// truncated data
+	gen = buildTestGeneration(5, 1.0)
+	var buff bytes.Buffer
+	if err := gen.Encode(gob.NewEncoder(&buff)); err != nil {
+		t.Fatal(err)
+	}
+	data := buff.Bytes()
+	for _, size := range []int{len(data) / 3, len(data) - 10} {
+		if err := (&Generation{}).Decode(gob.NewDecoder(bytes.NewReader(data[:size]))); err == nil {
+			t.Error("Truncated generation decoded", size)
+		}
+	}
+}
+
 func deepCompareGenerations(first, second *Generation, t *testing.T) {
 	if first.Id != second.Id {
 		t.Error("first.Id != second.Id")
@@ -61,6 +156,15 @@ func deepCompareGenerations(first, second *Generation, t *testing.T) {
 	if !reflect.DeepEqual(first.Compexity, second.Compexity) {
 		t.Error("Compexity values mismatch")
 	}
+	if !reflect.DeepEqual(first.SpeciesIds, second.SpeciesIds) {
+		t.Error("Species IDs mismatch", first.SpeciesIds, second.SpeciesIds)
+	}
+	if !reflect.DeepEqual(first.SpeciesSizes, second.SpeciesSizes) {
+		t.Error("Species sizes mismatch", first.SpeciesSizes, second.SpeciesSizes)
+	}
+	if !reflect.DeepEqual(first.OffspringEvents, second.OffspringEvents) {
+		t.Error("Offspring events mismatch", first.OffspringEvents, second.OffspringEvents)
+	}
 
 	if first.Diversity != second.Diversity {
 		t.Error("first.Diversity != second.Diversity")
@@ -74,7 +178,19 @@ func deepCompareGenerations(first, second *Generation, t *testing.T) {
 	if first.WinnerGenes != second.WinnerGenes {
 		t.Error("first.WinnerGenes != second.WinnerGenes")
 	}
+	if !reflect.DeepEqual(first.Metrics, second.Metrics) {
+		t.Error("Metrics mismatch", first.Metrics, second.Metrics)
+	}
+	if !reflect.DeepEqual(first.Operators, second.Operators) {
+		t.Error("Operators statistics mismatch", first.Operators, second.Operators)
+	}
 
+	if first.Best == nil || second.Best == nil {
+		if first.Best != second.Best {
+			t.Error("first.Best != second.Best", first.Best, second.Best)
+		}
+		return
+	}
 	if first.Best.Fitness != second.Best.Fitness {
 		t.Error("first.Best.Fitness != second.Best.Fitness")
 	}
@@ -88,7 +204,8 @@ func deepCompareGenerations(first, second *Generation, t *testing.T) {
 		}
 	}
 	for i, nd := range second.Best.Genotype.Nodes {
-		nd.Duplicate = nil
+		// the references to the phenotype nodes are not restored
+		nd.Duplicate, nd.Analogue, first.Best.Genotype.Nodes[i].Analogue = nil, nil, nil
 		if !reflect.DeepEqual(nd, first.Best.Genotype.Nodes[i]) {
 			t.Error("Wrong node found", nd, first.Best.Genotype.Nodes[i])
 		}
@@ -109,6 +226,11 @@ func buildTestGeneration(gen_id int, fitness float64) *Generation {
 	epoch.Fitness = Floats{10.0, 30.0, 40.0, fitness}
 	epoch.Age = Floats{1.0, 3.0, 4.0, 10.0}
 	epoch.Compexity = Floats{34.0, 21.0, 56.0, 15.0}
+	epoch.SpeciesIds = []int{1, 3, 4, 7}
+	epoch.SpeciesSizes = []int{12, 5, 8, 7}
+	epoch.OffspringEvents = []*genetics.OffspringEvent{
+		{Type:genetics.BabyStealingEvent, Generation:gen_id, Offspring:10, Species:[]int{1, 3}, Received:[]int{7, 3}},
+	}
 	epoch.Diversity = 32
 	epoch.WinnerEvals = 12423
 	epoch.WinnerNodes = 7
@@ -117,6 +239,15 @@ func buildTestGeneration(gen_id int, fitness float64) *Generation {
 	genome := buildTestGenome(gen_id)
 	org := genetics.Organism{Fitness:fitness, Genotype:genome, Generation:gen_id}
 	epoch.Best = &org
+	epoch.Metrics = metrics.NewPopulationMetrics([]*genetics.Organism{&org})
+	org.Ancestry = &genetics.Ancestry{
+		BirthGeneration:gen_id,
+		ParentIds:[]int{1, 2},
+		ParentFitness:[]float64{fitness / 2, fitness / 3},
+		Crossover:genetics.MateMultipointOperator,
+		Mutation:genetics.MutateAddNodeOperator,
+	}
+	epoch.Operators = genetics.CollectOperatorStatistics([]*genetics.Organism{&org})
 
 	return &epoch
 }
@@ -143,3 +274,32 @@ func buildTestGenome(id int) *genetics.Genome {
 
 	return genetics.NewGenome(id, traits, nodes, genes)
 }
+
+func TestExperiment_ExecuteOffspringEvents(t *testing.T) {
+	rand.Seed(42)
+	context := observerTestContext()
+	context.NumRuns, context.NumGenerations = 1, 7
+	context.DropOffAge, context.DeltaCodingWindow = 1, 1
+	neat.LogLevel = neat.LogLevelWarning
+
+	ex := Experiment{}
+	if err := ex.Execute(context, buildTestGenome(1), constantFitnessEvaluator{fitness:0.5}); err != nil {
+		t.Fatal(err)
+	}
+	// the population never improves after the first generation, thus delta coding performed after each
+	// DropOffAge + DeltaCodingWindow generations of stagnation
+	delta_coded := make([]int, 0)
+	for _, epoch := range ex.Trials[0].Generations {
+		for _, event := range epoch.OffspringEvents {
+			if event.Generation != epoch.Id {
+				t.Error("Event recorded in the wrong generation", epoch.Id, event)
+			}
+			if event.Type == genetics.DeltaCodingEvent {
+				delta_coded = append(delta_coded, epoch.Id)
+			}
+		}
+	}
+	if !reflect.DeepEqual(delta_coded, []int{3, 5}) {
+		t.Error("Wrong delta coding generations", delta_coded)
+	}
+}
@@ -0,0 +1,305 @@
+package experiments
+
+import (
+	"fmt"
+	"time"
+	"errors"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The topology of organisms migration between islands
+type MigrationTopology byte
+
+// The supported migration topologies
+const (
+	// The migrants from each island move to the next one, the last island sends migrants to the first one
+	RingMigration MigrationTopology = iota
+	// The migrants from each island move to all other islands
+	FullyConnectedMigration
+)
+
+// Returns migration topology by its name: "ring" or "full"
+func MigrationTopologyByName(name string) (MigrationTopology, error) {
+	switch name {
+	case "ring":
+		return RingMigration, nil
+	case "full":
+		return FullyConnectedMigration, nil
+	default:
+		return 0, errors.New(fmt.Sprintf("Unknown migration topology: %s", name))
+	}
+}
+
+// The island model of evolution where several sub-populations (islands) evolve independently with periodic migration
+// of the best organisms between them. It helps to keep diversity and to avoid premature convergence on hard tasks.
+type IslandModel struct {
+	// The number of islands, the population size of the context is divided among them
+	Islands           int
+	// The number of generations between migrations
+	MigrationInterval int
+	// The number of the best organisms migrating from each island
+	Migrants          int
+	// The topology of migration
+	Topology          MigrationTopology
+}
+
+// Checks that island model parameters are valid for given context
+func (m IslandModel) validate(context *neat.NeatContext) error {
+	if m.Islands < 2 {
+		return errors.New(fmt.Sprintf("At least two islands expected, found: %d", m.Islands))
+	}
+	if m.MigrationInterval <= 0 {
+		return errors.New(fmt.Sprintf("Wrong migration interval: %d", m.MigrationInterval))
+	}
+	incoming := m.Migrants
+	if m.Topology == FullyConnectedMigration {
+		incoming = m.Migrants * (m.Islands - 1)
+	}
+	if m.Migrants < 0 || incoming >= context.PopSize / m.Islands {
+		return errors.New(fmt.Sprintf("Wrong number of migrants: %d, island population size: %d",
+			m.Migrants, context.PopSize / m.Islands))
+	}
+	return nil
+}
+
+// Returns the indexes of islands receiving migrants from the given one
+func (m IslandModel) targets(island int) []int {
+	if m.Topology == RingMigration {
+		return []int{(island + 1) % m.Islands}
+	}
+	targets := make([]int, 0, m.Islands - 1)
+	for i := 0; i < m.Islands; i++ {
+		if i != island {
+			targets = append(targets, i)
+		}
+	}
+	return targets
+}
+
+// Moves copies of the best organisms between evaluated islands according to the migration topology
+func (m IslandModel) migrate(pops []*genetics.Population, generation int, contexts []*neat.NeatContext) error {
+	// select emigrants before any migration, thus only native organisms leave the islands
+	emigrants := make([][]*genetics.Organism, len(pops))
+	for i, pop := range pops {
+		emigrants[i] = pop.Elite(m.Migrants)
+	}
+	for i := range pops {
+		for _, target := range m.targets(i) {
+			if err := pops[target].Immigrate(emigrants[i], generation, contexts[target]); err != nil {
+				return err
+			}
+		}
+	}
+	neat.InfoLog(fmt.Sprintf(">>>>> Migration of %d organisms between %d islands done\n", m.Migrants, m.Islands))
+	return nil
+}
+
+// Advances all islands to the next epoch. The islands reproduced one after another with innovation numbers and node
+// IDs counters passed along, thus new genes and nodes got unique numbers among all islands and migrants can be mated
+// with native organisms.
+func epochIslands(pops []*genetics.Population, generation int, contexts []*neat.NeatContext) error {
+	innovation, node_id := int64(0), 0
+	for _, pop := range pops {
+		if pop.NextInnovationNumber() > innovation {
+			innovation = pop.NextInnovationNumber()
+		}
+		if pop.NextNodeId() > node_id {
+			node_id = pop.NextNodeId()
+		}
+	}
+	for i, pop := range pops {
+		pop.AdvanceCounters(innovation, node_id)
+		if _, err := pop.Epoch(generation, contexts[i]); err != nil {
+			return err
+		}
+		innovation, node_id = pop.NextInnovationNumber(), pop.NextNodeId()
+	}
+	return nil
+}
+
+// Combines statistics of the islands evaluated in the same generation. The winner statistics are taken from the first
+// island solved, with evaluations done by other islands added: in the previous generations and by the islands evaluated
+// before the solved one in the current generation.
+func combineIslands(id, trial_id int, pops []*genetics.Population, island_generations []Generation) Generation {
+	generation := Generation{
+		Id:id,
+		TrialId:trial_id,
+		Executed:time.Now(),
+	}
+	for i := range pops {
+		if g := island_generations[i]; g.Solved && !generation.Solved {
+			generation.Solved = true
+			generation.Best = g.Best
+			generation.WinnerNodes = g.WinnerNodes
+			generation.WinnerGenes = g.WinnerGenes
+			generation.WinnerEvals = g.WinnerEvals
+			for j, other := range pops {
+				if j == i {
+					continue
+				}
+				generation.WinnerEvals += len(other.Organisms) * id
+				if j < i {
+					generation.WinnerEvals += len(other.Organisms)
+				}
+			}
+		}
+	}
+	generation.FillPopulationStatistics(mergeIslands(pops))
+	return generation
+}
+
+// Returns population holding organisms and species of all islands
+func mergeIslands(pops []*genetics.Population) *genetics.Population {
+	all := &genetics.Population{
+		Species:make([]*genetics.Species, 0),
+		Organisms:make([]*genetics.Organism, 0),
+	}
+	for _, pop := range pops {
+		all.Species = append(all.Species, pop.Species...)
+		all.Organisms = append(all.Organisms, pop.Organisms...)
+		all.OffspringEvents = append(all.OffspringEvents, pop.OffspringEvents...)
+	}
+	return all
+}
+
+// Executes experiment using the island model. The organisms of each island are evaluated by the executor, which must
+// implement EpochEvaluator, since the epochs of all islands are driven together. The statistics of all islands combined stored into trial's Generations while the ones of each island
+// into trial's Islands. The observers receive the population merged from all islands, while species creation and
+// extinction are not reported since species IDs are not unique among islands. The genealogy and species history
+// tracking is not supported by island model.
+func (ex *Experiment) ExecuteIslands(context *neat.NeatContext, start_genome *genetics.Genome, executor interface{}, model IslandModel) (err error) {
+	if err = model.validate(context); err != nil {
+		return err
+	}
+	if err = validateTermination(context); err != nil {
+		return err
+	}
+	epoch_evaluator, err := epochEvaluator(executor)
+	if err != nil {
+		return err
+	}
+	if ex.Trials == nil {
+		ex.Trials = make(Trials, context.NumRuns)
+	}
+	if ex.TrackGenealogy {
+		neat.WarnLog("Genealogy tracking is not supported by island model")
+	}
+	if ex.TrackSpecies {
+		neat.WarnLog("Species history tracking is not supported by island model")
+	}
+
+	for run := 0; run < context.NumRuns; run++ {
+		// each island has its own context with population size divided among islands
+		contexts := make([]*neat.NeatContext, model.Islands)
+		pops := make([]*genetics.Population, model.Islands)
+		for i := range pops {
+			contexts[i] = copyContext(context)
+			contexts[i].PopSize = context.PopSize / model.Islands
+			neat.InfoLog(fmt.Sprintf("\n>>>>> Island: %d", i))
+			pops[i], err = spawnPopulation(start_genome, contexts[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		// start new trial
+		trial := Trial{
+			Id:run,
+			Islands:make([]Generations, model.Islands),
+			RestartPolicy:context.RestartPolicy,
+		}
+
+		if trial_observer, ok := executor.(TrialRunObserver); ok {
+			trial_observer.TrialRunStarted(&trial) // optional
+		}
+		lifecycle := newTrialLifecycle(ex.observers, &trial, false)
+		if err = lifecycle.trialStarted(mergeIslands(pops)); err != nil {
+			return err
+		}
+
+		criteria := newTerminationCriteria(context)
+
+		for generation_id := 0; generation_id < context.NumGenerations && !lifecycle.stopped; generation_id++ {
+			island_generations := make([]Generation, model.Islands)
+			for i, pop := range pops {
+				neat.InfoLog(fmt.Sprintf(">>>>> Generation:%3d\tRun: %d\tIsland: %d\n", generation_id, run, i))
+				island_generations[i] = Generation{
+					Id:generation_id,
+					TrialId:run,
+				}
+				err = epoch_evaluator.EpochEvaluate(pop, &island_generations[i], contexts[i])
+				if err != nil {
+					neat.InfoLog(fmt.Sprintf("!!!!! Generation [%d] evaluation failed at island [%d] !!!!!\n",
+						generation_id, i))
+					return err
+				}
+				island_generations[i].Executed = time.Now()
+				trial.Islands[i] = append(trial.Islands[i], island_generations[i])
+			}
+
+			generation := combineIslands(generation_id, run, pops, island_generations)
+			trial.Generations = append(trial.Generations, generation)
+			evaluated := &trial.Generations[len(trial.Generations) - 1]
+			all := mergeIslands(pops)
+			if err = lifecycle.generationEvaluated(evaluated, all); err != nil {
+				return err
+			}
+			reason := terminationReason(evaluated, all, criteria, lifecycle)
+			restart := reason == TerminationStagnation && context.RestartPolicy != neat.NoRestart
+			if reason != TerminationNone && !restart {
+				if generation.Solved {
+					neat.InfoLog(fmt.Sprintf(">>>>> The winner organism found in [%d] generation! <<<<<\n", generation_id))
+				} else {
+					neat.InfoLog(fmt.Sprintf(">>>>> The trial terminated in [%d] generation, reason: %s <<<<<\n",
+						generation_id, reason))
+				}
+				trial.Termination = reason
+				break
+			}
+
+			if restart {
+				// Reseed all stagnated islands instead of migration and reproduction
+				neat.InfoLog(fmt.Sprintf(">>>>> The islands stagnated in [%d] generation, restart from: %s\n",
+					generation_id, context.RestartPolicy))
+				seeds := criteria.seeds(start_genome)
+				for i, pop := range pops {
+					if err = pop.Reseed(seeds, contexts[i]); err != nil {
+						return err
+					}
+				}
+				criteria.restarted(generation_id + 1)
+				trial.Restarts = append(trial.Restarts, generation_id + 1)
+			} else {
+				if model.Migrants > 0 && (generation_id + 1) % model.MigrationInterval == 0 {
+					if err = model.migrate(pops, generation_id, contexts); err != nil {
+						return err
+					}
+				}
+
+				// Move to the next epoch if failed to find winner
+				neat.DebugLog(">>>>> start next generation")
+				if err = epochIslands(pops, generation_id + 1, contexts); err != nil {
+					neat.InfoLog(fmt.Sprintf("!!!!! Generation [%d] reproduction failed !!!!!\n", generation_id))
+					return err
+				}
+			}
+			if err = lifecycle.epochReproduced(generation_id + 1, mergeIslands(pops)); err != nil {
+				return err
+			}
+		}
+		if trial.Termination == TerminationNone {
+			trial.Termination = TerminationGenerations
+			if lifecycle.stopped {
+				trial.Termination = TerminationObserver
+			}
+		}
+		if err = lifecycle.trialEnded(); err != nil {
+			return err
+		}
+		// store trial into experiment
+		ex.Trials[run] = trial
+	}
+
+	return nil
+}
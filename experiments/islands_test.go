@@ -0,0 +1,181 @@
+package experiments
+
+import (
+	"testing"
+	"math/rand"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The evaluator assigning random fitness to organisms
+type randomFitnessEvaluator struct {
+	// The number of evaluated generations
+	evaluated int
+}
+
+func (e *randomFitnessEvaluator) EpochEvaluate(pop *genetics.Population, epoch *Generation, context *neat.NeatContext) error {
+	for _, org := range pop.Organisms {
+		org.Fitness = rand.Float64()
+	}
+	epoch.FillPopulationStatistics(pop)
+	e.evaluated++
+	return nil
+}
+
+func TestExperiment_ExecuteIslands(t *testing.T) {
+	rand.Seed(42)
+	context := &neat.NeatContext{
+		CompatThreshold:0.5,
+		DropOffAge:15,
+		PopSize:60,
+		MutateOnlyProb:0.5,
+		MutateAddNodeProb:0.2,
+		MutateAddLinkProb:0.2,
+		MateMultipointProb:0.5,
+		MateMultipointAvgProb:0.5,
+		InterspeciesMateRate:0.05,
+		NewLinkTries:20,
+		WeightMutPower:1.0,
+		SurvivalThresh:0.2,
+		AgeSignificance:1.0,
+		PrintEvery:10,
+		NumRuns:2,
+		NumGenerations:8,
+	}
+	neat.LogLevel = neat.LogLevelWarning
+
+	for _, topology := range []MigrationTopology{RingMigration, FullyConnectedMigration} {
+		model := IslandModel{Islands:3, MigrationInterval:2, Migrants:2, Topology:topology}
+		evaluator := randomFitnessEvaluator{}
+		observer := newRecordingObserver()
+		ex := Experiment{}
+		ex.AddObserver(observer)
+		err := ex.ExecuteIslands(context, buildTestGenome(1), &evaluator, model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if evaluator.evaluated != context.NumRuns * context.NumGenerations * model.Islands {
+			t.Error("Wrong number of evaluations", evaluator.evaluated)
+		}
+		if n := observer.count("evaluated"); n != context.NumRuns * context.NumGenerations {
+			t.Error("Wrong number of observed generations", n)
+		}
+		if n := observer.count("created"); n != 0 {
+			t.Error("Species creation should not be observed with islands", n)
+		}
+		for _, trial := range ex.Trials {
+			if len(trial.Generations) != context.NumGenerations {
+				t.Error("Wrong number of generations", len(trial.Generations))
+			}
+			if len(trial.Islands) != model.Islands {
+				t.Fatal("Wrong number of islands", len(trial.Islands))
+			}
+			for _, island := range trial.Islands {
+				if len(island) != context.NumGenerations {
+					t.Error("Wrong number of island generations", len(island))
+				}
+			}
+			// the combined generation holds statistics of all islands
+			last := trial.Generations[len(trial.Generations) - 1]
+			if last.Metrics.Organisms != context.PopSize {
+				t.Error("Wrong number of organisms in combined statistics", last.Metrics.Organisms)
+			}
+		}
+	}
+}
+
+func TestIslandModel_migrateAndEpoch(t *testing.T) {
+	rand.Seed(42)
+	context := &neat.NeatContext{
+		CompatThreshold:0.5,
+		DropOffAge:15,
+		PopSize:20,
+		MutateOnlyProb:0.5,
+		MutateAddNodeProb:0.3,
+		MutateAddLinkProb:0.3,
+		MateMultipointProb:0.5,
+		MateMultipointAvgProb:0.5,
+		NewLinkTries:20,
+		WeightMutPower:1.0,
+		SurvivalThresh:0.2,
+		AgeSignificance:1.0,
+	}
+	model := IslandModel{Islands:3, MigrationInterval:1, Migrants:3, Topology:FullyConnectedMigration}
+	pops := make([]*genetics.Population, model.Islands)
+	contexts := make([]*neat.NeatContext, model.Islands)
+	for i := range pops {
+		contexts[i] = context
+		pop, err := genetics.NewPopulation(buildTestGenome(1), context)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pops[i] = pop
+	}
+
+	evaluator := randomFitnessEvaluator{}
+	for generation := 0; generation < 10; generation++ {
+		for i, pop := range pops {
+			evaluator.EpochEvaluate(pop, &Generation{}, contexts[i])
+		}
+		if err := model.migrate(pops, generation, contexts); err != nil {
+			t.Fatal(err)
+		}
+		for _, pop := range pops {
+			if len(pop.Organisms) != context.PopSize {
+				t.Fatal("Population size changed by migration", len(pop.Organisms))
+			}
+		}
+		if err := epochIslands(pops, generation + 1, contexts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the same innovation number should denote the same link among all islands
+	links := make(map[int64][2]int)
+	for _, pop := range pops {
+		for _, org := range pop.Organisms {
+			for _, g := range org.Genotype.Genes {
+				link := [2]int{g.Link.InNode.Id, g.Link.OutNode.Id}
+				if l, ok := links[g.InnovationNum]; ok && l != link {
+					t.Error("Innovation number collision among islands", g.InnovationNum, l, link)
+				}
+				links[g.InnovationNum] = link
+			}
+		}
+	}
+}
+
+func TestMigrationTopologyByName(t *testing.T) {
+	if top, err := MigrationTopologyByName("full"); err != nil || top != FullyConnectedMigration {
+		t.Error("Wrong topology", top, err)
+	}
+	if _, err := MigrationTopologyByName("star"); err == nil {
+		t.Error("Error expected for unknown topology")
+	}
+	context := &neat.NeatContext{PopSize:30}
+	if err := (IslandModel{Islands:3, MigrationInterval:5, Migrants:5, Topology:FullyConnectedMigration}).validate(context); err == nil {
+		t.Error("Error expected for too many migrants")
+	}
+	if err := (IslandModel{Islands:3, MigrationInterval:5, Migrants:5}).validate(context); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCombineIslands(t *testing.T) {
+	pops := make([]*genetics.Population, 3)
+	island_generations := make([]Generation, 3)
+	for i := range pops {
+		pops[i] = &genetics.Population{Organisms:buildTestOrganisms(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)}
+		island_generations[i] = Generation{Id:2}
+	}
+	// the winner is found by the fifth organism of the second island in its third generation
+	island_generations[1].Solved = true
+	island_generations[1].WinnerEvals = 10 * 2 + 5
+	island_generations[2].Solved = true
+
+	generation := combineIslands(2, 0, pops, island_generations)
+	// two previous generations of other islands and the current generation of the first island
+	if !generation.Solved || generation.WinnerEvals != 25 + 2 * 10 * 2 + 10 {
+		t.Error("Wrong winner evaluations", generation.Solved, generation.WinnerEvals)
+	}
+}
@@ -0,0 +1,179 @@
+package experiments
+
+import (
+	"io"
+	"fmt"
+	"sync"
+	"time"
+	"expvar"
+	"strconv"
+	"net/http"
+	"encoding/json"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The name of experiment metrics among expvar variables
+const monitorExpvarName = "goneat"
+
+// The state of experiment execution at some moment
+type MonitorSnapshot struct {
+	// The ID of current trial
+	Trial                int     `json:"trial"`
+	// The ID of the last evaluated generation
+	Generation           int     `json:"generation"`
+	// The best fitness in the last evaluated generation
+	BestFitness          float64 `json:"best_fitness"`
+	// The mean fitness in the last evaluated generation
+	MeanFitness          float64 `json:"mean_fitness"`
+	// The number of species in the last evaluated generation
+	Species              int     `json:"species"`
+	// The mean complexity of organisms in the last evaluated generation
+	MeanComplexity       float64 `json:"mean_complexity"`
+	// The number of organisms evaluated since the start of experiment
+	Evaluations          int64   `json:"evaluations"`
+	// The number of organisms evaluated per second in the last evaluated generation
+	EvaluationsPerSecond float64 `json:"evaluations_per_second"`
+	// The number of trials solved since the start of experiment
+	TrialsSolved         int     `json:"trials_solved"`
+	// The number of seconds since the start of experiment
+	ElapsedSeconds       float64 `json:"elapsed_seconds"`
+}
+
+// The monitor of experiment execution which keeps the latest statistics and exposes them over HTTP as Prometheus text
+// metrics and expvar JSON, thus long runs can be watched by dashboards. It should be registered as observer of
+// experiment with Experiment.AddObserver. It is safe for concurrent use.
+type Monitor struct {
+	ObserverAdapter
+
+	// The current state
+	snapshot MonitorSnapshot
+	// The time when experiment started
+	started  time.Time
+	// The time of the last update
+	updated  time.Time
+	// The lock to guard state
+	mutex    sync.RWMutex
+}
+
+// Creates new monitor with start time of experiment set to now
+func NewMonitor() *Monitor {
+	now := time.Now()
+	return &Monitor{
+		snapshot:MonitorSnapshot{Generation:-1},
+		started:now,
+		updated:now,
+	}
+}
+
+// Notifies monitor that new trial started
+func (m *Monitor) TrialStarted(trial *Trial) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.snapshot.Trial = trial.Id
+	m.snapshot.Generation = -1
+	m.updated = time.Now()
+	return nil
+}
+
+// Notifies monitor that generation was evaluated, all organisms of population counted as evaluated
+func (m *Monitor) GenerationEvaluated(trial *Trial, epoch *Generation, pop *genetics.Population) error {
+	now := time.Now()
+	evaluations := len(pop.Organisms)
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s := &m.snapshot
+	s.Generation = epoch.Id
+	s.BestFitness = 0
+	for i, f := range epoch.Fitness {
+		if i == 0 || f > s.BestFitness {
+			s.BestFitness = f
+		}
+	}
+	s.MeanFitness, _, s.MeanComplexity = epoch.Average()
+	s.Species = epoch.Diversity
+	s.Evaluations += int64(evaluations)
+	if elapsed := now.Sub(m.updated).Seconds(); elapsed > 0 {
+		s.EvaluationsPerSecond = float64(evaluations) / elapsed
+	}
+	if epoch.Solved {
+		s.TrialsSolved++
+	}
+	m.updated = now
+	return nil
+}
+
+// Returns the current state of experiment execution
+func (m *Monitor) Snapshot() MonitorSnapshot {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	s := m.snapshot
+	s.ElapsedSeconds = time.Since(m.started).Seconds()
+	return s
+}
+
+// Writes the current state in the Prometheus text exposition format
+func (m *Monitor) WritePrometheus(w io.Writer) error {
+	s := m.Snapshot()
+	metrics := []struct {
+		name, kind, help string
+		value            float64
+	}{
+		{"goneat_trial", "gauge", "The ID of current trial.", float64(s.Trial)},
+		{"goneat_generation", "gauge", "The ID of the last evaluated generation.", float64(s.Generation)},
+		{"goneat_best_fitness", "gauge", "The best fitness in the last evaluated generation.", s.BestFitness},
+		{"goneat_mean_fitness", "gauge", "The mean fitness in the last evaluated generation.", s.MeanFitness},
+		{"goneat_species", "gauge", "The number of species in the last evaluated generation.", float64(s.Species)},
+		{"goneat_mean_complexity", "gauge", "The mean complexity of organisms in the last evaluated generation.",
+			s.MeanComplexity},
+		{"goneat_evaluations_total", "counter", "The number of evaluated organisms.", float64(s.Evaluations)},
+		{"goneat_evaluations_per_second", "gauge", "The number of organisms evaluated per second in the last generation.",
+			s.EvaluationsPerSecond},
+		{"goneat_trials_solved_total", "counter", "The number of solved trials.", float64(s.TrialsSolved)},
+		{"goneat_elapsed_seconds", "gauge", "The number of seconds since the start of experiment.", s.ElapsedSeconds},
+	}
+	for _, metric := range metrics {
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", metric.name, metric.help, metric.name,
+			metric.kind, metric.name, strconv.FormatFloat(metric.value, 'g', -1, 64))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes the current state along with all published expvar variables as JSON object in the format of expvar handler.
+// The state is written under "goneat" key without publishing it, thus any number of monitors can be created.
+func (m *Monitor) WriteExpvar(w io.Writer) error {
+	data, err := json.Marshal(m.Snapshot())
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(w, "{\n%q: %s", monitorExpvarName, data); err != nil {
+		return err
+	}
+	expvar.Do(func(kv expvar.KeyValue) {
+		if err == nil && kv.Key != monitorExpvarName {
+			_, err = fmt.Fprintf(w, ",\n%q: %s", kv.Key, kv.Value)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n}\n")
+	return err
+}
+
+// Returns HTTP handler serving the current state as Prometheus metrics at "/metrics" and as expvar JSON at
+// "/debug/vars"
+func (m *Monitor) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WritePrometheus(w)
+	})
+	mux.HandleFunc("/debug/vars", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		m.WriteExpvar(w)
+	})
+	return mux
+}
@@ -0,0 +1,127 @@
+package experiments
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"math/rand"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"encoding/json"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// Returns population with given number of organisms to be reported to the monitor
+func monitorTestPopulation(size int) *genetics.Population {
+	return &genetics.Population{Organisms:make([]*genetics.Organism, size)}
+}
+
+func TestMonitor_GenerationEvaluated(t *testing.T) {
+	m := NewMonitor()
+	m.TrialStarted(&Trial{Id:2})
+	m.GenerationEvaluated(nil, &Generation{Id:0, Fitness:Floats{0.5, 0.9}, Compexity:Floats{4, 6}, Diversity:2},
+		monitorTestPopulation(10))
+	m.GenerationEvaluated(nil, &Generation{Id:1, Fitness:Floats{0.7, 0.3}, Compexity:Floats{8, 6}, Diversity:2,
+		Solved:true}, monitorTestPopulation(10))
+
+	s := m.Snapshot()
+	if s.Trial != 2 || s.Generation != 1 || s.Species != 2 || s.Evaluations != 20 || s.TrialsSolved != 1 {
+		t.Error("Wrong snapshot", s)
+	}
+	if s.BestFitness != 0.7 || s.MeanFitness != 0.5 || s.MeanComplexity != 7 {
+		t.Error("Wrong fitness or complexity", s)
+	}
+	if s.EvaluationsPerSecond <= 0 || s.ElapsedSeconds <= 0 {
+		t.Error("Wrong rates", s)
+	}
+
+	m.TrialStarted(&Trial{Id:3})
+	if s = m.Snapshot(); s.Trial != 3 || s.Generation != -1 || s.Evaluations != 20 {
+		t.Error("Wrong snapshot of started trial", s)
+	}
+}
+
+func TestMonitor_WritePrometheus(t *testing.T) {
+	m := NewMonitor()
+	m.GenerationEvaluated(nil, &Generation{Id:4, Fitness:Floats{0.25}, Diversity:1}, monitorTestPopulation(15))
+	var buff bytes.Buffer
+	if err := m.WritePrometheus(&buff); err != nil {
+		t.Fatal(err)
+	}
+	text := buff.String()
+	for _, line := range []string{"# TYPE goneat_evaluations_total counter", "goneat_evaluations_total 15",
+		"goneat_generation 4", "goneat_best_fitness 0.25", "goneat_species 1"} {
+		if !strings.Contains(text, line + "\n") {
+			t.Error("Metric not found", line)
+		}
+	}
+}
+
+func TestMonitor_Handler(t *testing.T) {
+	m := NewMonitor()
+	m.GenerationEvaluated(nil, &Generation{Id:1, Fitness:Floats{0.5}, Diversity:1}, monitorTestPopulation(5))
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "goneat_evaluations_total 5\n") {
+		t.Error("Wrong Prometheus metrics", string(body))
+	}
+
+	resp, err = http.Get(server.URL + "/debug/vars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := make(map[string]json.RawMessage)
+	err = json.NewDecoder(resp.Body).Decode(&vars)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := MonitorSnapshot{}
+	if err = json.Unmarshal(vars["goneat"], &s); err != nil || s.Evaluations != 5 {
+		t.Error("Wrong experiment variables", err, s)
+	}
+	if _, ok := vars["memstats"]; !ok {
+		t.Error("Standard expvar variables missing")
+	}
+}
+
+func TestExperiment_ExecuteMonitor(t *testing.T) {
+	rand.Seed(42)
+	context := &neat.NeatContext{
+		CompatThreshold:0.5,
+		DropOffAge:15,
+		PopSize:40,
+		MutateOnlyProb:0.5,
+		MutateAddNodeProb:0.2,
+		MutateAddLinkProb:0.2,
+		MateMultipointProb:0.5,
+		MateMultipointAvgProb:0.5,
+		NewLinkTries:20,
+		WeightMutPower:1.0,
+		SurvivalThresh:0.2,
+		AgeSignificance:1.0,
+		NumRuns:2,
+		NumGenerations:3,
+	}
+	neat.LogLevel = neat.LogLevelWarning
+
+	monitor := NewMonitor()
+	ex := Experiment{}
+	ex.AddObserver(monitor)
+	if err := ex.Execute(context, buildTestGenome(1), &randomFitnessEvaluator{}); err != nil {
+		t.Fatal(err)
+	}
+	s := monitor.Snapshot()
+	if s.Trial != 1 || s.Generation != 2 || s.Evaluations != int64(context.NumRuns * context.NumGenerations * context.PopSize) {
+		t.Error("Wrong monitor state", s)
+	}
+}
@@ -0,0 +1,191 @@
+package experiments
+
+import (
+	"errors"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The error to be returned by observer to stop the current trial after the event. The trial ends as usually and the
+// experiment proceeds with the next trial.
+var ErrStopTrial = errors.New("trial stopped by observer")
+
+// The interface to describe observer interested to receive notifications about the lifecycle of experiment
+// execution. The observers are registered with Experiment.AddObserver and notified in order of registration. If any
+// method returns an error the experiment execution aborts with that error, except ErrStopTrial which only stops the
+// current trial. Embed ObserverAdapter to implement only the methods of interest.
+type ExperimentObserver interface {
+	// Invoked when new trial started before any epoch evaluation in that trial
+	TrialStarted(trial *Trial) error
+	// Invoked when trial ended, i.e. solved, stopped or run out of generations
+	TrialEnded(trial *Trial) error
+	// Invoked when generation of population was evaluated and its statistics collected
+	GenerationEvaluated(trial *Trial, epoch *Generation, pop *genetics.Population) error
+	// Invoked when population was reproduced to the given generation, i.e. before its evaluation
+	EpochReproduced(trial *Trial, generation int, pop *genetics.Population) error
+	// Invoked when new species appeared in population at the given generation
+	SpeciesCreated(trial *Trial, generation int, species *genetics.Species) error
+	// Invoked when species went extinct during reproduction to the given generation
+	SpeciesExtinct(trial *Trial, generation int, species *genetics.Species) error
+	// Invoked when evaluated generation has the best organism more fit than any of the previous generations in trial
+	ChampionFound(trial *Trial, epoch *Generation, champion *genetics.Organism) error
+	// Invoked when evaluated generation solved the experiment's problem
+	PopulationSolved(trial *Trial, epoch *Generation, pop *genetics.Population) error
+}
+
+// The observer ignoring all notifications. It is intended to be embedded into observers interested only in some of
+// the notifications.
+type ObserverAdapter struct{}
+
+func (ObserverAdapter) TrialStarted(trial *Trial) error {
+	return nil
+}
+func (ObserverAdapter) TrialEnded(trial *Trial) error {
+	return nil
+}
+func (ObserverAdapter) GenerationEvaluated(trial *Trial, epoch *Generation, pop *genetics.Population) error {
+	return nil
+}
+func (ObserverAdapter) EpochReproduced(trial *Trial, generation int, pop *genetics.Population) error {
+	return nil
+}
+func (ObserverAdapter) SpeciesCreated(trial *Trial, generation int, species *genetics.Species) error {
+	return nil
+}
+func (ObserverAdapter) SpeciesExtinct(trial *Trial, generation int, species *genetics.Species) error {
+	return nil
+}
+func (ObserverAdapter) ChampionFound(trial *Trial, epoch *Generation, champion *genetics.Organism) error {
+	return nil
+}
+func (ObserverAdapter) PopulationSolved(trial *Trial, epoch *Generation, pop *genetics.Population) error {
+	return nil
+}
+
+// Registers observer to be notified about lifecycle of this experiment execution
+func (ex *Experiment) AddObserver(observer ExperimentObserver) {
+	ex.observers = append(ex.observers, observer)
+}
+
+// The notifier of observers about lifecycle events of one trial, which keeps the state required to detect events
+type trialLifecycle struct {
+	// The registered observers
+	observers    []ExperimentObserver
+	// The observed trial
+	trial        *Trial
+	// The species of population by ID known to observers, nil if species events are not tracked
+	species      map[int]*genetics.Species
+	// The fitness of the best organism found in trial
+	bestFitness  float64
+	// The flag to indicate whether the best organism was found
+	hasChampion  bool
+	// The flag to indicate whether any observer asked to stop the trial
+	stopped      bool
+}
+
+// Creates notifier of observers about events of given trial. If track_species is true the species creation and
+// extinction are detected as well.
+func newTrialLifecycle(observers []ExperimentObserver, trial *Trial, track_species bool) *trialLifecycle {
+	l := &trialLifecycle{observers:observers, trial:trial}
+	if track_species {
+		l.species = make(map[int]*genetics.Species)
+	}
+	return l
+}
+
+// Notifies observers about event, the ErrStopTrial is recorded and not returned
+func (l *trialLifecycle) notify(event func(o ExperimentObserver) error) error {
+	for _, o := range l.observers {
+		if err := event(o); err == ErrStopTrial {
+			l.stopped = true
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Notifies that trial started with given initial population, which species are reported as created
+func (l *trialLifecycle) trialStarted(pop *genetics.Population) error {
+	err := l.notify(func(o ExperimentObserver) error {
+		return o.TrialStarted(l.trial)
+	})
+	if err != nil {
+		return err
+	}
+	return l.speciesChanged(0, pop)
+}
+
+// Notifies that trial ended
+func (l *trialLifecycle) trialEnded() error {
+	return l.notify(func(o ExperimentObserver) error {
+		return o.TrialEnded(l.trial)
+	})
+}
+
+// Notifies that generation was evaluated along with the new champion and solution found in it
+func (l *trialLifecycle) generationEvaluated(epoch *Generation, pop *genetics.Population) error {
+	err := l.notify(func(o ExperimentObserver) error {
+		return o.GenerationEvaluated(l.trial, epoch, pop)
+	})
+	if err != nil {
+		return err
+	}
+	if epoch.Best != nil && (!l.hasChampion || epoch.Best.Fitness > l.bestFitness) {
+		l.hasChampion, l.bestFitness = true, epoch.Best.Fitness
+		err = l.notify(func(o ExperimentObserver) error {
+			return o.ChampionFound(l.trial, epoch, epoch.Best)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if epoch.Solved {
+		return l.notify(func(o ExperimentObserver) error {
+			return o.PopulationSolved(l.trial, epoch, pop)
+		})
+	}
+	return nil
+}
+
+// Notifies about the extinct and the new species of reproduced population followed by the reproduction itself
+func (l *trialLifecycle) epochReproduced(generation int, pop *genetics.Population) error {
+	if err := l.speciesChanged(generation, pop); err != nil {
+		return err
+	}
+	return l.notify(func(o ExperimentObserver) error {
+		return o.EpochReproduced(l.trial, generation, pop)
+	})
+}
+
+// Notifies about species of population which went extinct or were created since the previous call
+func (l *trialLifecycle) speciesChanged(generation int, pop *genetics.Population) error {
+	if l.species == nil {
+		return nil
+	}
+	current := make(map[int]*genetics.Species, len(pop.Species))
+	for _, sp := range pop.Species {
+		current[sp.Id] = sp
+	}
+	for id, sp := range l.species {
+		if _, ok := current[id]; !ok {
+			err := l.notify(func(o ExperimentObserver) error {
+				return o.SpeciesExtinct(l.trial, generation, sp)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, sp := range pop.Species {
+		if _, ok := l.species[sp.Id]; !ok {
+			err := l.notify(func(o ExperimentObserver) error {
+				return o.SpeciesCreated(l.trial, generation, sp)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	l.species = current
+	return nil
+}
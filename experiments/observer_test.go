@@ -0,0 +1,285 @@
+package experiments
+
+import (
+	"os"
+	"fmt"
+	"errors"
+	"testing"
+	"math/rand"
+	"io/ioutil"
+	"path/filepath"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The observer recording received events
+type recordingObserver struct {
+	// The recorded events
+	events  []string
+	// The species created and not yet extinct, nil until species creation reported
+	alive   map[int]bool
+	// The error to be returned when generation with ID stopAt evaluated
+	stop    error
+	stopAt  int
+}
+
+func newRecordingObserver() *recordingObserver {
+	return &recordingObserver{stopAt:-1}
+}
+
+func (o *recordingObserver) record(format string, a ...interface{}) {
+	o.events = append(o.events, fmt.Sprintf(format, a...))
+}
+
+func (o *recordingObserver) count(event string) int {
+	n := 0
+	for _, e := range o.events {
+		if e == event {
+			n++
+		}
+	}
+	return n
+}
+
+func (o *recordingObserver) TrialStarted(trial *Trial) error {
+	o.record("started")
+	o.alive = nil
+	return nil
+}
+func (o *recordingObserver) TrialEnded(trial *Trial) error {
+	o.record("ended")
+	return nil
+}
+func (o *recordingObserver) GenerationEvaluated(trial *Trial, epoch *Generation, pop *genetics.Population) error {
+	o.record("evaluated")
+	if epoch.Id == o.stopAt {
+		return o.stop
+	}
+	return nil
+}
+func (o *recordingObserver) EpochReproduced(trial *Trial, generation int, pop *genetics.Population) error {
+	o.record("reproduced")
+	if o.alive == nil {
+		return nil
+	}
+	for _, sp := range pop.Species {
+		if !o.alive[sp.Id] {
+			return errors.New(fmt.Sprintf("species %d was not reported as created", sp.Id))
+		}
+	}
+	return nil
+}
+func (o *recordingObserver) SpeciesCreated(trial *Trial, generation int, species *genetics.Species) error {
+	o.record("created")
+	if o.alive == nil {
+		o.alive = make(map[int]bool)
+	}
+	if o.alive[species.Id] {
+		return errors.New(fmt.Sprintf("species %d created twice", species.Id))
+	}
+	o.alive[species.Id] = true
+	return nil
+}
+func (o *recordingObserver) SpeciesExtinct(trial *Trial, generation int, species *genetics.Species) error {
+	o.record("extinct")
+	if !o.alive[species.Id] {
+		return errors.New(fmt.Sprintf("species %d extinct without being created", species.Id))
+	}
+	delete(o.alive, species.Id)
+	return nil
+}
+func (o *recordingObserver) ChampionFound(trial *Trial, epoch *Generation, champion *genetics.Organism) error {
+	o.record("champion")
+	return nil
+}
+func (o *recordingObserver) PopulationSolved(trial *Trial, epoch *Generation, pop *genetics.Population) error {
+	o.record("solved")
+	return nil
+}
+
+// The evaluator which solves the problem in generation solveAt by assigning the maximal fitness to the first organism
+type solvingEvaluator struct {
+	solveAt int
+}
+
+func (e solvingEvaluator) EpochEvaluate(pop *genetics.Population, epoch *Generation, context *neat.NeatContext) error {
+	for _, org := range pop.Organisms {
+		org.Fitness = rand.Float64()
+	}
+	if epoch.Id == e.solveAt {
+		org := pop.Organisms[0]
+		org.Fitness, org.IsWinner = 2.0, true
+		epoch.Solved = true
+		epoch.Best = org
+	}
+	epoch.FillPopulationStatistics(pop)
+	return nil
+}
+
+func observerTestContext() *neat.NeatContext {
+	return &neat.NeatContext{
+		CompatThreshold:0.5,
+		DisjointCoeff:1.0,
+		ExcessCoeff:1.0,
+		MutdiffCoeff:0.4,
+		DropOffAge:15,
+		PopSize:40,
+		MutateOnlyProb:0.5,
+		MutateAddNodeProb:0.2,
+		MutateAddLinkProb:0.2,
+		MateMultipointProb:0.5,
+		MateMultipointAvgProb:0.5,
+		NewLinkTries:20,
+		WeightMutPower:1.0,
+		SurvivalThresh:0.2,
+		AgeSignificance:1.0,
+		NumRuns:2,
+		NumGenerations:5,
+	}
+}
+
+func TestExperiment_ExecuteObservers(t *testing.T) {
+	rand.Seed(42)
+	context := observerTestContext()
+	neat.LogLevel = neat.LogLevelWarning
+
+	observer := newRecordingObserver()
+	ex := Experiment{}
+	ex.AddObserver(observer)
+	if err := ex.Execute(context, buildTestGenome(1), &randomFitnessEvaluator{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := observer.count("started"); n != context.NumRuns {
+		t.Error("Wrong number of started trials", n)
+	}
+	if n := observer.count("ended"); n != context.NumRuns {
+		t.Error("Wrong number of ended trials", n)
+	}
+	if n := observer.count("evaluated"); n != context.NumRuns * context.NumGenerations {
+		t.Error("Wrong number of evaluated generations", n)
+	}
+	// the population reproduced after each not solved generation including the last one
+	if n := observer.count("reproduced"); n != context.NumRuns * context.NumGenerations {
+		t.Error("Wrong number of reproduced generations", n)
+	}
+	if n := observer.count("champion"); n < context.NumRuns {
+		t.Error("Champion of each trial should be found", n)
+	}
+	if n := observer.count("solved"); n != 0 {
+		t.Error("The problem should not be solved", n)
+	}
+	if observer.count("created") <= observer.count("extinct") {
+		t.Error("Wrong number of created and extinct species", observer.count("created"), observer.count("extinct"))
+	}
+	if observer.events[0] != "started" || observer.events[1] != "created" {
+		t.Error("Initial species should be created right after trial started", observer.events[:2])
+	}
+	if observer.events[len(observer.events) - 1] != "ended" {
+		t.Error("The last event should be trial end", observer.events[len(observer.events) - 1])
+	}
+}
+
+func TestExperiment_ExecuteObserverStopTrial(t *testing.T) {
+	rand.Seed(42)
+	context := observerTestContext()
+	neat.LogLevel = neat.LogLevelWarning
+
+	observer := newRecordingObserver()
+	observer.stop, observer.stopAt = ErrStopTrial, 1
+	ex := Experiment{}
+	ex.AddObserver(&ObserverAdapter{})
+	ex.AddObserver(observer)
+	if err := ex.Execute(context, buildTestGenome(1), &randomFitnessEvaluator{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ex.Trials) != context.NumRuns {
+		t.Fatal("Wrong number of trials", len(ex.Trials))
+	}
+	for _, trial := range ex.Trials {
+		if len(trial.Generations) != 2 {
+			t.Error("Trial should be stopped after second generation", trial.Id, len(trial.Generations))
+		}
+	}
+	if n := observer.count("ended"); n != context.NumRuns {
+		t.Error("Stopped trials should be ended", n)
+	}
+}
+
+func TestExperiment_ExecuteObserverError(t *testing.T) {
+	rand.Seed(42)
+	context := observerTestContext()
+	neat.LogLevel = neat.LogLevelWarning
+
+	observer := newRecordingObserver()
+	observer.stop, observer.stopAt = errors.New("observer failed"), 2
+	ex := Experiment{}
+	ex.AddObserver(observer)
+	if err := ex.Execute(context, buildTestGenome(1), &randomFitnessEvaluator{}); err != observer.stop {
+		t.Error("Observer error should abort execution", err)
+	}
+	if n := observer.count("evaluated"); n != 3 {
+		t.Error("Wrong number of evaluated generations", n)
+	}
+}
+
+func TestPopulationDumper(t *testing.T) {
+	rand.Seed(42)
+	context := observerTestContext()
+	context.NumRuns = 1
+	neat.LogLevel = neat.LogLevelWarning
+
+	out_dir, err := ioutil.TempDir("", "dumper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(out_dir)
+
+	observer := newRecordingObserver()
+	ex := Experiment{}
+	ex.AddObserver(PopulationDumper{OutputPath:out_dir, PrintEvery:2, WinnerName:"test_winner"})
+	ex.AddObserver(observer)
+	if err = ex.Execute(context, buildTestGenome(1), solvingEvaluator{solveAt:3}); err != nil {
+		t.Fatal(err)
+	}
+	if n := observer.count("solved"); n != 1 {
+		t.Error("Population should be solved once", n)
+	}
+
+	for _, name := range []string{"gen_0", "gen_2", "gen_3"} {
+		if _, err = os.Stat(filepath.Join(out_dir, "0", name)); err != nil {
+			t.Error("Population not dumped", name, err)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(out_dir, "0", "gen_1")); err == nil {
+		t.Error("Population should not be dumped in generation 1")
+	}
+	winners, err := filepath.Glob(filepath.Join(out_dir, "0", "test_winner_*"))
+	if err != nil || len(winners) != 1 {
+		t.Error("Winner genome not dumped", winners, err)
+	}
+}
+
+func TestPopulationDumper_WinnerFitness(t *testing.T) {
+	out_dir, err := ioutil.TempDir("", "dumper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(out_dir)
+
+	pop := &genetics.Population{Organisms:buildTestOrganisms(1, 2)}
+	pop.Organisms[1].Fitness, pop.Organisms[1].IsWinner = 1.0, true
+	dumper := PopulationDumper{OutputPath:out_dir, WinnerName:"test_winner", WinnerFitness:true}
+	if err = dumper.PopulationSolved(&Trial{}, &Generation{}, pop); err != nil {
+		t.Fatal(err)
+	}
+	org := pop.Organisms[1]
+	name := fmt.Sprintf("test_winner_1.0_%d-%d", org.Phenotype.NodeCount(), org.Phenotype.LinkCount())
+	if _, err = os.Stat(filepath.Join(out_dir, "0", name)); err != nil {
+		t.Error("Winner genome not dumped with fitness in name", err)
+	}
+
+	if err = dumpToFile(filepath.Join(out_dir, "missing", "gen_0"), pop.WriteBySpecies); err == nil {
+		t.Error("The failure to dump population not reported")
+	}
+}
@@ -0,0 +1,96 @@
+// Package plot renders charts of the experiments statistics: fitness, complexity and species curves per generation.
+// The charts can be written as SVG or PNG images using only the standard library.
+package plot
+
+import (
+	"fmt"
+	"io"
+	"bytes"
+	"image/color"
+	"encoding/xml"
+)
+
+// The point on canvas in pixels
+type point struct {
+	x, y float64
+}
+
+// The anchor of the text relative to its position
+type textAnchor byte
+
+const (
+	anchorStart textAnchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// The drawing surface of the chart. The coordinates are in pixels with origin at the top left corner.
+type canvas interface {
+	// Draws polyline through given points
+	polyline(points []point, c color.RGBA, width float64)
+	// Fills polygon with given vertices
+	polygon(points []point, fill color.RGBA)
+	// Draws text with baseline at given position, if vertical the text is rotated counterclockwise
+	text(x, y float64, s string, anchor textAnchor, vertical bool, c color.RGBA)
+	// Writes image to the writer
+	write(w io.Writer) error
+}
+
+// The canvas producing SVG document
+type svgCanvas struct {
+	width, height int
+	buf           bytes.Buffer
+}
+
+func newSVGCanvas(width, height int) *svgCanvas {
+	c := svgCanvas{width:width, height:height}
+	fmt.Fprintf(&c.buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		width, height, width, height)
+	fmt.Fprintf(&c.buf, "<rect width=\"%d\" height=\"%d\" fill=\"white\"/>\n", width, height)
+	return &c
+}
+
+func (c *svgCanvas) polyline(points []point, col color.RGBA, width float64) {
+	fmt.Fprintf(&c.buf, "<polyline points=\"%s\" fill=\"none\" stroke=\"%s\" stroke-opacity=\"%.3g\" stroke-width=\"%g\"/>\n",
+		svgPoints(points), svgColor(col), float64(col.A) / 255, width)
+}
+
+func (c *svgCanvas) polygon(points []point, fill color.RGBA) {
+	fmt.Fprintf(&c.buf, "<polygon points=\"%s\" fill=\"%s\" fill-opacity=\"%.3g\"/>\n",
+		svgPoints(points), svgColor(fill), float64(fill.A) / 255)
+}
+
+func (c *svgCanvas) text(x, y float64, s string, anchor textAnchor, vertical bool, col color.RGBA) {
+	anchors := [...]string{"start", "middle", "end"}
+	transform := ""
+	if vertical {
+		transform = fmt.Sprintf(" transform=\"rotate(-90 %.1f %.1f)\"", x, y)
+	}
+	fmt.Fprintf(&c.buf, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"%s\" font-family=\"sans-serif\" font-size=\"12\" fill=\"%s\"%s>",
+		x, y, anchors[anchor], svgColor(col), transform)
+	xml.EscapeText(&c.buf, []byte(s))
+	c.buf.WriteString("</text>\n")
+}
+
+func (c *svgCanvas) write(w io.Writer) error {
+	if _, err := w.Write(c.buf.Bytes()); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "</svg>\n")
+	return err
+}
+
+func svgPoints(points []point) string {
+	var buf bytes.Buffer
+	for i, p := range points {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		fmt.Fprintf(&buf, "%.1f,%.1f", p.x, p.y)
+	}
+	return buf.String()
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,330 @@
+package plot
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"errors"
+	"strconv"
+	"image/color"
+)
+
+// The image format of the chart
+type Format byte
+
+// The supported image formats
+const (
+	SVG Format = iota
+	PNG
+)
+
+// Returns image format by its name: "svg" or "png"
+func FormatByName(name string) (Format, error) {
+	switch name {
+	case "svg":
+		return SVG, nil
+	case "png":
+		return PNG, nil
+	default:
+		return 0, errors.New(fmt.Sprintf("Unknown image format: %s", name))
+	}
+}
+
+// Returns the file name extension for this format
+func (f Format) Extension() string {
+	if f == PNG {
+		return "png"
+	}
+	return "svg"
+}
+
+// The data series with Y values corresponding to X values
+type Series struct {
+	// The name of series to show in legend, the series without name is not shown
+	Name string
+	// The X values
+	X    []float64
+	// The Y values
+	Y    []float64
+}
+
+// The filled band between lower and upper values, e.g. between the first and the third quartiles
+type Band struct {
+	// The name of band to show in legend, the band without name is not shown
+	Name  string
+	// The X values
+	X     []float64
+	// The lower bound values
+	Lower []float64
+	// The upper bound values
+	Upper []float64
+}
+
+// The line chart with optional bands and stacked areas. The bands are drawn first, the stacked areas over them and
+// the lines on top. The colors assigned to the series in order of bands, stacked areas, and lines.
+type Chart struct {
+	// The title of chart
+	Title   string
+	// The label of X axis
+	XLabel  string
+	// The label of Y axis
+	YLabel  string
+	// The size of image in pixels
+	Width   int
+	Height  int
+
+	// The bands
+	Bands   []Band
+	// The series stacked one on top of the other as filled areas, all with the same X values
+	Stacked []Series
+	// The series drawn as lines
+	Lines   []Series
+}
+
+// The default size of chart image
+const defaultWidth, defaultHeight = 800, 500
+
+// The margins around the plotting area, the right one holds the legend
+const marginLeft, marginRight, marginTop, marginBottom = 70, 160, 40, 50
+
+// The number of ticks per axis to aim for
+const ticksCount = 6
+
+// The height of the legend entry
+const legendRowHeight = 18
+
+// The categorical palette of series colors
+var palette = []color.RGBA{
+	{0x1f, 0x77, 0xb4, 0xff},
+	{0xff, 0x7f, 0x0e, 0xff},
+	{0x2c, 0xa0, 0x2c, 0xff},
+	{0xd6, 0x27, 0x28, 0xff},
+	{0x94, 0x67, 0xbd, 0xff},
+	{0x8c, 0x56, 0x4b, 0xff},
+	{0xe3, 0x77, 0xc2, 0xff},
+	{0x7f, 0x7f, 0x7f, 0xff},
+	{0xbc, 0xbd, 0x22, 0xff},
+	{0x17, 0xbe, 0xcf, 0xff},
+}
+
+var (
+	black = color.RGBA{0, 0, 0, 0xff}
+	gray = color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+)
+
+// Returns color of the series with given index with given opacity
+func seriesColor(index int, alpha uint8) color.RGBA {
+	c := palette[index % len(palette)]
+	c.A = alpha
+	return c
+}
+
+// Writes image of this chart in the given format
+func (ch *Chart) Write(w io.Writer, format Format) error {
+	if err := ch.validate(); err != nil {
+		return err
+	}
+	width, height := ch.Width, ch.Height
+	if width <= 0 || height <= 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+	if width <= marginLeft + marginRight || height <= marginTop + marginBottom {
+		return errors.New(fmt.Sprintf("Chart size is too small: %dx%d", width, height))
+	}
+	var c canvas
+	switch format {
+	case SVG:
+		c = newSVGCanvas(width, height)
+	case PNG:
+		c = newPNGCanvas(width, height)
+	default:
+		return errors.New(fmt.Sprintf("Unsupported image format: %d", format))
+	}
+	ch.draw(c, width, height)
+	return c.write(w)
+}
+
+// Checks that lengths of the series values match
+func (ch *Chart) validate() error {
+	for _, b := range ch.Bands {
+		if len(b.Lower) != len(b.X) || len(b.Upper) != len(b.X) {
+			return errors.New(fmt.Sprintf("Wrong number of values in band: %s", b.Name))
+		}
+	}
+	for _, s := range ch.Stacked {
+		if len(s.X) != len(ch.Stacked[0].X) || len(s.Y) != len(s.X) {
+			return errors.New(fmt.Sprintf("Wrong number of values in stacked series: %s", s.Name))
+		}
+	}
+	for _, s := range ch.Lines {
+		if len(s.Y) != len(s.X) {
+			return errors.New(fmt.Sprintf("Wrong number of values in series: %s", s.Name))
+		}
+	}
+	return nil
+}
+
+// Returns the cumulative values of stacked series, the first row is the baseline of zeros
+func (ch *Chart) stackedLevels() [][]float64 {
+	if len(ch.Stacked) == 0 {
+		return nil
+	}
+	levels := make([][]float64, len(ch.Stacked) + 1)
+	levels[0] = make([]float64, len(ch.Stacked[0].X))
+	for i, s := range ch.Stacked {
+		levels[i + 1] = make([]float64, len(s.Y))
+		for j, y := range s.Y {
+			levels[i + 1][j] = levels[i][j] + y
+		}
+	}
+	return levels
+}
+
+// Returns ranges of all values in this chart. The Y range always includes zero.
+func (ch *Chart) ranges(levels [][]float64) (x_min, x_max, y_min, y_max float64) {
+	x_min, x_max = math.Inf(1), math.Inf(-1)
+	y_min, y_max = 0, 0
+	update := func(xs []float64, ys ...[]float64) {
+		for _, x := range xs {
+			x_min, x_max = math.Min(x_min, x), math.Max(x_max, x)
+		}
+		for _, values := range ys {
+			for _, y := range values {
+				y_min, y_max = math.Min(y_min, y), math.Max(y_max, y)
+			}
+		}
+	}
+	for _, b := range ch.Bands {
+		update(b.X, b.Lower, b.Upper)
+	}
+	if len(levels) > 0 {
+		update(ch.Stacked[0].X, levels...)
+	}
+	for _, s := range ch.Lines {
+		update(s.X, s.Y)
+	}
+	if math.IsInf(x_min, 0) {
+		x_min, x_max = 0, 1
+	}
+	return x_min, x_max, y_min, y_max
+}
+
+// Returns the range extended to the multiples of nice tick step and the step
+func niceRange(min, max float64) (float64, float64, float64) {
+	if max <= min {
+		max = min + 1
+	}
+	raw := (max - min) / ticksCount
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude * 10
+	for _, m := range []float64{1, 2, 5} {
+		if raw <= m * magnitude {
+			step = m * magnitude
+			break
+		}
+	}
+	return math.Floor(min / step) * step, math.Ceil(max / step) * step, step
+}
+
+// Returns the label of axis tick
+func tickLabel(v float64) string {
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
+
+// Draws this chart on the canvas of given size
+func (ch *Chart) draw(c canvas, width, height int) {
+	levels := ch.stackedLevels()
+	x_min, x_max, y_min, y_max := ch.ranges(levels)
+	x_min, x_max, x_step := niceRange(x_min, x_max)
+	y_min, y_max, y_step := niceRange(y_min, y_max)
+
+	left, top := float64(marginLeft), float64(marginTop)
+	right, bottom := float64(width - marginRight), float64(height - marginBottom)
+	px := func(x float64) float64 {
+		return left + (x - x_min) / (x_max - x_min) * (right - left)
+	}
+	py := func(y float64) float64 {
+		return bottom - (y - y_min) / (y_max - y_min) * (bottom - top)
+	}
+	// the points of series in pixels
+	points := func(xs, ys []float64) []point {
+		pts := make([]point, len(xs))
+		for i := range xs {
+			pts[i] = point{px(xs[i]), py(ys[i])}
+		}
+		return pts
+	}
+	// the points of polygon between lower and upper values in pixels
+	area := func(xs, lower, upper []float64) []point {
+		pts := points(xs, upper)
+		for i := len(xs) - 1; i >= 0; i-- {
+			pts = append(pts, point{px(xs[i]), py(lower[i])})
+		}
+		return pts
+	}
+
+	// grid and ticks
+	for i := 0; float64(i) * x_step <= x_max - x_min + x_step / 2; i++ {
+		x := x_min + float64(i) * x_step
+		c.polyline([]point{{px(x), top}, {px(x), bottom}}, gray, 1)
+		c.text(px(x), bottom + 18, tickLabel(x), anchorMiddle, false, black)
+	}
+	for i := 0; float64(i) * y_step <= y_max - y_min + y_step / 2; i++ {
+		y := y_min + float64(i) * y_step
+		c.polyline([]point{{left, py(y)}, {right, py(y)}}, gray, 1)
+		c.text(left - 6, py(y) + 5, tickLabel(y), anchorEnd, false, black)
+	}
+
+	// data
+	legend := make([]Series, 0)
+	colors := make([]color.RGBA, 0)
+	index := 0
+	for _, b := range ch.Bands {
+		col := seriesColor(index, 0x50)
+		c.polygon(area(b.X, b.Lower, b.Upper), col)
+		legend, colors = append(legend, Series{Name:b.Name}), append(colors, col)
+		index++
+	}
+	for i, s := range ch.Stacked {
+		col := seriesColor(index, 0xc0)
+		c.polygon(area(s.X, levels[i], levels[i + 1]), col)
+		legend, colors = append(legend, s), append(colors, col)
+		index++
+	}
+	for _, s := range ch.Lines {
+		col := seriesColor(index, 0xff)
+		c.polyline(points(s.X, s.Y), col, 2)
+		legend, colors = append(legend, s), append(colors, col)
+		index++
+	}
+
+	// axes, labels and legend
+	c.polyline([]point{{left, top}, {left, bottom}, {right, bottom}}, black, 1)
+	c.text(float64(width) / 2, top / 2 + 6, ch.Title, anchorMiddle, false, black)
+	c.text((left + right) / 2, float64(height) - 10, ch.XLabel, anchorMiddle, false, black)
+	c.text(20, (top + bottom) / 2, ch.YLabel, anchorMiddle, true, black)
+	ch.drawLegend(c, legend, colors, right + 15, top, bottom)
+}
+
+// Draws legend entries of named series one below the other, the entries not fitting in height are counted in the
+// last row
+func (ch *Chart) drawLegend(c canvas, series []Series, colors []color.RGBA, x, top, bottom float64) {
+	rows := int((bottom - top) / legendRowHeight)
+	y := top
+	for i, s := range series {
+		if rows < 1 {
+			return
+		}
+		if s.Name == "" {
+			continue
+		}
+		if rows == 1 && i < len(series) - 1 {
+			c.text(x, y + 11, fmt.Sprintf("+%d more", len(series) - i), anchorStart, false, black)
+			return
+		}
+		c.polygon([]point{{x, y}, {x + 14, y}, {x + 14, y + 12}, {x, y + 12}}, colors[i])
+		c.text(x + 20, y + 11, s.Name, anchorStart, false, black)
+		y += legendRowHeight
+		rows--
+	}
+}
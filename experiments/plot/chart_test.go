@@ -0,0 +1,100 @@
+package plot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"image/png"
+	"image/color"
+)
+
+func buildTestChart() *Chart {
+	xs := []float64{0, 1, 2, 3}
+	return &Chart{
+		Title:"Test <chart>",
+		XLabel:"Generation",
+		YLabel:"Value",
+		Width:400,
+		Height:300,
+		Bands:[]Band{{Name:"Band", X:xs, Lower:[]float64{0, 1, 1, 2}, Upper:[]float64{1, 2, 3, 4}}},
+		Stacked:[]Series{{Name:"First", X:xs, Y:[]float64{1, 1, 1, 1}}, {Name:"Second", X:xs, Y:[]float64{2, 2, 2, 2}}},
+		Lines:[]Series{{Name:"Line", X:xs, Y:[]float64{0.5, 1.5, 2.5, 3.5}}},
+	}
+}
+
+func TestChart_WriteSVG(t *testing.T) {
+	var buff bytes.Buffer
+	if err := buildTestChart().Write(&buff, SVG); err != nil {
+		t.Fatal(err)
+	}
+	svg := buff.String()
+	if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Error("Not SVG document", svg)
+	}
+	// one band and two stacked areas, plus legend swatches
+	if count := strings.Count(svg, "<polygon"); count != 3 + 4 {
+		t.Error("Wrong number of polygons", count)
+	}
+	if !strings.Contains(svg, "Test &lt;chart&gt;") {
+		t.Error("Title not escaped")
+	}
+	if !strings.Contains(svg, "rotate(-90") {
+		t.Error("Y label not rotated")
+	}
+}
+
+func TestChart_WritePNG(t *testing.T) {
+	var buff bytes.Buffer
+	if err := buildTestChart().Write(&buff, PNG); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != 400 || size.Y != 300 {
+		t.Error("Wrong image size", size)
+	}
+	// the line color should be found among pixels
+	line_color := seriesColor(3, 0xff)
+	found := false
+	for y := 0; y < 300 && !found; y++ {
+		for x := 0; x < 400 && !found; x++ {
+			found = color.RGBAModel.Convert(img.At(x, y)) == line_color
+		}
+	}
+	if !found {
+		t.Error("Line not drawn")
+	}
+}
+
+func TestChart_WriteErrors(t *testing.T) {
+	chart := buildTestChart()
+	chart.Lines[0].Y = chart.Lines[0].Y[1:]
+	if err := chart.Write(&bytes.Buffer{}, SVG); err == nil {
+		t.Error("Mismatched series values accepted")
+	}
+	chart = buildTestChart()
+	chart.Width = 100
+	if err := chart.Write(&bytes.Buffer{}, PNG); err == nil {
+		t.Error("Too small chart accepted")
+	}
+	if _, err := FormatByName("gif"); err == nil {
+		t.Error("Unknown format accepted")
+	}
+}
+
+func TestNiceRange(t *testing.T) {
+	min, max, step := niceRange(0, 0.93)
+	if min != 0 || max != 1 || step != 0.2 {
+		t.Error("Wrong range", min, max, step)
+	}
+	min, max, step = niceRange(3, 147)
+	if min != 0 || max != 150 || step != 50 {
+		t.Error("Wrong range", min, max, step)
+	}
+	min, max, step = niceRange(5, 5)
+	if min != 5 || max != 6 || step != 0.2 {
+		t.Error("Wrong range of single value", min, max, step)
+	}
+}
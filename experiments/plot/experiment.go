@@ -0,0 +1,222 @@
+package plot
+
+import (
+	"os"
+	"fmt"
+	"math"
+	"sort"
+	"path/filepath"
+	"github.com/yaricom/goNEAT/experiments"
+)
+
+// The statistics of some value per generation among trials
+type generationStats struct {
+	// The generation IDs
+	X    []float64
+	// The mean values
+	Mean []float64
+	// The first and the third quartiles of values
+	Q25  []float64
+	Q75  []float64
+}
+
+// Collects statistics of the value per generation among given trials. The trials finished earlier don't contribute
+// to the statistics of the later generations.
+func collectStats(trials experiments.Trials, value func(epoch experiments.Generation) (float64, bool)) generationStats {
+	values := make([][]float64, 0)
+	for _, t := range trials {
+		for i, epoch := range t.Generations {
+			if i >= len(values) {
+				values = append(values, make([]float64, 0, len(trials)))
+			}
+			if v, ok := value(epoch); ok {
+				values[i] = append(values[i], v)
+			}
+		}
+	}
+	stats := generationStats{}
+	for i, vals := range values {
+		if len(vals) == 0 {
+			continue
+		}
+		sort.Float64s(vals)
+		sum := 0.0
+		for _, v := range vals {
+			sum += v
+		}
+		stats.X = append(stats.X, float64(i))
+		stats.Mean = append(stats.Mean, sum / float64(len(vals)))
+		stats.Q25 = append(stats.Q25, quantile(vals, 0.25))
+		stats.Q75 = append(stats.Q75, quantile(vals, 0.75))
+	}
+	return stats
+}
+
+// Returns quantile of sorted values using linear interpolation between closest ranks
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted) - 1)
+	lo := int(math.Floor(pos))
+	if lo + 1 >= len(sorted) {
+		return sorted[len(sorted) - 1]
+	}
+	return sorted[lo] + (pos - float64(lo)) * (sorted[lo + 1] - sorted[lo])
+}
+
+// Returns the fitness of the best species' champion in generation
+func bestFitness(epoch experiments.Generation) (float64, bool) {
+	if len(epoch.Fitness) == 0 {
+		return 0, false
+	}
+	best := epoch.Fitness[0]
+	for _, f := range epoch.Fitness[1:] {
+		best = math.Max(best, f)
+	}
+	return best, true
+}
+
+// Returns chart of the mean and the best fitness per generation averaged among trials with the band between the
+// first and the third quartiles of the best fitness
+func FitnessChart(trials experiments.Trials) *Chart {
+	best := collectStats(trials, bestFitness)
+	mean := collectStats(trials, func(epoch experiments.Generation) (float64, bool) {
+		fitness, _, _ := epoch.Average()
+		return fitness, len(epoch.Fitness) > 0
+	})
+	return &Chart{
+		Title:fmt.Sprintf("Fitness, trials: %d", len(trials)),
+		XLabel:"Generation",
+		YLabel:"Fitness",
+		Bands:[]Band{{Name:"Best Q25-Q75", X:best.X, Lower:best.Q25, Upper:best.Q75}},
+		Lines:[]Series{
+			{Name:"Best", X:best.X, Y:best.Mean},
+			{Name:"Mean", X:mean.X, Y:mean.Mean},
+		},
+	}
+}
+
+// Returns chart of the mean complexity of organisms per generation averaged among trials with the band between the
+// first and the third quartiles
+func ComplexityChart(trials experiments.Trials) *Chart {
+	stats := collectStats(trials, func(epoch experiments.Generation) (float64, bool) {
+		_, _, complexity := epoch.Average()
+		return complexity, len(epoch.Compexity) > 0
+	})
+	return &Chart{
+		Title:fmt.Sprintf("Complexity, trials: %d", len(trials)),
+		XLabel:"Generation",
+		YLabel:"Complexity",
+		Bands:[]Band{{Name:"Q25-Q75", X:stats.X, Lower:stats.Q25, Upper:stats.Q75}},
+		Lines:[]Series{{Name:"Mean", X:stats.X, Y:stats.Mean}},
+	}
+}
+
+// Returns chart of the number of species per generation averaged among trials with the band between the first and
+// the third quartiles
+func DiversityChart(trials experiments.Trials) *Chart {
+	stats := collectStats(trials, func(epoch experiments.Generation) (float64, bool) {
+		return float64(epoch.Diversity), true
+	})
+	return &Chart{
+		Title:fmt.Sprintf("Species, trials: %d", len(trials)),
+		XLabel:"Generation",
+		YLabel:"Species",
+		Bands:[]Band{{Name:"Q25-Q75", X:stats.X, Lower:stats.Q25, Upper:stats.Q75}},
+		Lines:[]Series{{Name:"Mean", X:stats.X, Y:stats.Mean}},
+	}
+}
+
+// Returns stacked area chart of the species sizes per generation of given trial. The species are stacked in order of
+// their appearance. The generations without species sizes, e.g. read from the legacy data, are plotted empty.
+func SpeciesChart(trial experiments.Trial) *Chart {
+	xs := make([]float64, len(trial.Generations))
+	index := make(map[int]int) // the index of species series by species ID
+	stacked := make([]Series, 0)
+	for i, epoch := range trial.Generations {
+		xs[i] = float64(i)
+		for j, id := range epoch.SpeciesIds {
+			if j >= len(epoch.SpeciesSizes) {
+				break
+			}
+			k, ok := index[id]
+			if !ok {
+				k = len(stacked)
+				index[id] = k
+				stacked = append(stacked, Series{
+					Name:fmt.Sprintf("Species %d", id),
+					X:xs,
+					Y:make([]float64, len(trial.Generations)),
+				})
+			}
+			stacked[k].Y[i] = float64(epoch.SpeciesSizes[j])
+		}
+	}
+	return &Chart{
+		Title:fmt.Sprintf("Species sizes, trial: %d", trial.Id),
+		XLabel:"Generation",
+		YLabel:"Organisms",
+		Stacked:stacked,
+	}
+}
+
+// Writes fitness, complexity, and species count charts of all trials of the experiment into given directory and the
+// species sizes chart of each trial into its subdirectory along with the speciation graph if species history was
+// tracked. The directories are created if missing.
+func WriteExperiment(ex *experiments.Experiment, dir string, format Format) error {
+	charts := map[string]*Chart{
+		"fitness":FitnessChart(ex.Trials),
+		"complexity":ComplexityChart(ex.Trials),
+		"diversity":DiversityChart(ex.Trials),
+	}
+	if err := writeCharts(charts, dir, format); err != nil {
+		return err
+	}
+	for _, t := range ex.Trials {
+		trial_dir := filepath.Join(dir, fmt.Sprintf("%d", t.Id))
+		if err := writeCharts(trialSpeciesCharts(t), trial_dir, format); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes fitness, complexity, species count, and species sizes charts of the trial into given directory, which is
+// created if missing. The speciation graph is written as well if species history was tracked.
+func WriteTrial(trial experiments.Trial, dir string, format Format) error {
+	trials := experiments.Trials{trial}
+	charts := trialSpeciesCharts(trial)
+	charts["fitness"] = FitnessChart(trials)
+	charts["complexity"] = ComplexityChart(trials)
+	charts["diversity"] = DiversityChart(trials)
+	return writeCharts(charts, dir, format)
+}
+
+// Returns the species sizes chart and the speciation graph of the trial, the latter only if species history was
+// tracked
+func trialSpeciesCharts(trial experiments.Trial) map[string]*Chart {
+	charts := map[string]*Chart{"species":SpeciesChart(trial)}
+	if trial.SpeciesHistory != nil {
+		charts["speciation"] = SpeciationChart(trial.SpeciesHistory, fmt.Sprintf("Speciation, trial: %d", trial.Id))
+	}
+	return charts
+}
+
+// Writes charts into the files named by their keys with extension of the format
+func writeCharts(charts map[string]*Chart, dir string, format Format) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	for name, chart := range charts {
+		file, err := os.Create(filepath.Join(dir, fmt.Sprintf("%s.%s", name, format.Extension())))
+		if err != nil {
+			return err
+		}
+		err = chart.Write(file, format)
+		if c_err := file.Close(); err == nil {
+			err = c_err
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package plot
+
+import (
+	"os"
+	"testing"
+	"path/filepath"
+	"github.com/yaricom/goNEAT/experiments"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+func buildTestTrials() experiments.Trials {
+	return experiments.Trials{
+		{Id:0, Generations:experiments.Generations{
+			{Id:0, Fitness:experiments.Floats{1, 3}, Compexity:experiments.Floats{4, 6}, Diversity:2,
+				SpeciesIds:[]int{1, 2}, SpeciesSizes:[]int{6, 4}},
+			{Id:1, Fitness:experiments.Floats{5, 2}, Compexity:experiments.Floats{8, 6}, Diversity:2,
+				SpeciesIds:[]int{2, 3}, SpeciesSizes:[]int{7, 3}},
+		}},
+		{Id:1, Generations:experiments.Generations{
+			{Id:0, Fitness:experiments.Floats{2}, Compexity:experiments.Floats{5}, Diversity:1},
+		}},
+	}
+}
+
+func TestFitnessChart(t *testing.T) {
+	chart := FitnessChart(buildTestTrials())
+	best, mean, band := chart.Lines[0], chart.Lines[1], chart.Bands[0]
+	// the second trial finished at the first generation
+	if len(best.X) != 2 || best.Y[0] != 2.5 || best.Y[1] != 5 {
+		t.Error("Wrong best fitness", best)
+	}
+	if mean.Y[0] != 2 || mean.Y[1] != 3.5 {
+		t.Error("Wrong mean fitness", mean)
+	}
+	if band.Lower[0] != 2.25 || band.Upper[0] != 2.75 || band.Lower[1] != 5 || band.Upper[1] != 5 {
+		t.Error("Wrong quartiles of best fitness", band)
+	}
+}
+
+func TestComplexityAndDiversityCharts(t *testing.T) {
+	complexity := ComplexityChart(buildTestTrials()).Lines[0]
+	if complexity.Y[0] != 5 || complexity.Y[1] != 7 {
+		t.Error("Wrong mean complexity", complexity)
+	}
+	diversity := DiversityChart(buildTestTrials()).Lines[0]
+	if diversity.Y[0] != 1.5 || diversity.Y[1] != 2 {
+		t.Error("Wrong mean diversity", diversity)
+	}
+}
+
+func TestSpeciesChart(t *testing.T) {
+	chart := SpeciesChart(buildTestTrials()[0])
+	if len(chart.Stacked) != 3 {
+		t.Fatal("Wrong number of species", len(chart.Stacked))
+	}
+	expected := [][]float64{{6, 0}, {4, 7}, {0, 3}}
+	for i, s := range chart.Stacked {
+		if s.Y[0] != expected[i][0] || s.Y[1] != expected[i][1] {
+			t.Error("Wrong species sizes", s.Name, s.Y)
+		}
+	}
+}
+
+func TestWriteExperiment(t *testing.T) {
+	dir := t.TempDir()
+	ex := experiments.Experiment{Id:1, Trials:buildTestTrials()}
+	if err := WriteExperiment(&ex, dir, PNG); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"fitness.png", "complexity.png", "diversity.png", "0/species.png", "1/species.png"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error("Chart not written", name, err)
+		}
+	}
+	if err := WriteTrial(ex.Trials[0], filepath.Join(dir, "trial"), SVG); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "trial", "species.svg")); err != nil {
+		t.Error("Trial chart not written", err)
+	}
+}
+
+func TestSpeciationChart(t *testing.T) {
+	history := genetics.NewSpeciesHistory()
+	history.Records = []*genetics.SpeciesRecord{
+		{Generation:0, SpeciesId:1, ParentId:-1, Size:8},
+		{Generation:0, SpeciesId:2, ParentId:-1, Size:2},
+		{Generation:1, SpeciesId:1, ParentId:-1, Size:5},
+		{Generation:1, SpeciesId:2, ParentId:-1, Size:2},
+		{Generation:1, SpeciesId:3, ParentId:1, Size:3},
+		{Generation:2, SpeciesId:2, ParentId:-1, Size:4},
+		{Generation:2, SpeciesId:3, ParentId:1, Size:6},
+	}
+	chart := SpeciationChart(history, "test")
+	// the child species placed right after its parent
+	names := []string{"Species 1", "Species 3 < 1", "Species 2"}
+	sizes := [][]float64{{8, 5, 0}, {0, 3, 6}, {2, 2, 4}}
+	if len(chart.Stacked) != len(names) {
+		t.Fatal("Wrong number of species", len(chart.Stacked))
+	}
+	for i, s := range chart.Stacked {
+		if s.Name != names[i] {
+			t.Error("Wrong species order", i, s.Name)
+		}
+		for j, y := range s.Y {
+			if y != sizes[i][j] {
+				t.Error("Wrong species size", s.Name, j, y)
+			}
+		}
+	}
+
+	trial := buildTestTrials()[0]
+	trial.SpeciesHistory = history
+	dir := t.TempDir()
+	if err := WriteTrial(trial, dir, SVG); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "speciation.svg")); err != nil {
+		t.Error("Speciation graph not written", err)
+	}
+}
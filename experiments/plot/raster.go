@@ -0,0 +1,211 @@
+package plot
+
+import (
+	"io"
+	"math"
+	"sort"
+	"unicode"
+	"image"
+	"image/png"
+	"image/color"
+)
+
+// The scale of the bitmap font glyphs, i.e. size of glyph pixel in image pixels
+const fontScale = 2
+
+// The size of the bitmap font glyphs in glyph pixels
+const glyphWidth, glyphHeight = 3, 5
+
+// The bitmap font with upper case letters, digits, and common punctuation. The lower case letters drawn as upper case.
+var glyphs = map[rune][glyphHeight]string{
+	'0':{"###", "#.#", "#.#", "#.#", "###"},
+	'1':{".#.", "##.", ".#.", ".#.", "###"},
+	'2':{"###", "..#", "###", "#..", "###"},
+	'3':{"###", "..#", "###", "..#", "###"},
+	'4':{"#.#", "#.#", "###", "..#", "..#"},
+	'5':{"###", "#..", "###", "..#", "###"},
+	'6':{"###", "#..", "###", "#.#", "###"},
+	'7':{"###", "..#", "..#", "..#", "..#"},
+	'8':{"###", "#.#", "###", "#.#", "###"},
+	'9':{"###", "#.#", "###", "..#", "###"},
+	'A':{".#.", "#.#", "###", "#.#", "#.#"},
+	'B':{"##.", "#.#", "##.", "#.#", "##."},
+	'C':{".##", "#..", "#..", "#..", ".##"},
+	'D':{"##.", "#.#", "#.#", "#.#", "##."},
+	'E':{"###", "#..", "##.", "#..", "###"},
+	'F':{"###", "#..", "##.", "#..", "#.."},
+	'G':{".##", "#..", "#.#", "#.#", ".##"},
+	'H':{"#.#", "#.#", "###", "#.#", "#.#"},
+	'I':{"###", ".#.", ".#.", ".#.", "###"},
+	'J':{"..#", "..#", "..#", "#.#", ".#."},
+	'K':{"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L':{"#..", "#..", "#..", "#..", "###"},
+	'M':{"#.#", "###", "###", "#.#", "#.#"},
+	'N':{"##.", "#.#", "#.#", "#.#", "#.#"},
+	'O':{".#.", "#.#", "#.#", "#.#", ".#."},
+	'P':{"##.", "#.#", "##.", "#..", "#.."},
+	'Q':{".#.", "#.#", "#.#", "##.", ".##"},
+	'R':{"##.", "#.#", "##.", "#.#", "#.#"},
+	'S':{".##", "#..", ".#.", "..#", "##."},
+	'T':{"###", ".#.", ".#.", ".#.", ".#."},
+	'U':{"#.#", "#.#", "#.#", "#.#", "###"},
+	'V':{"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W':{"#.#", "#.#", "###", "###", "#.#"},
+	'X':{"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y':{"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z':{"###", "..#", ".#.", "#..", "###"},
+	'.':{"...", "...", "...", "...", ".#."},
+	',':{"...", "...", "...", ".#.", "#.."},
+	':':{"...", ".#.", "...", ".#.", "..."},
+	'-':{"...", "...", "###", "...", "..."},
+	'+':{"...", ".#.", "###", ".#.", "..."},
+	'_':{"...", "...", "...", "...", "###"},
+	'/':{"..#", "..#", ".#.", "#..", "#.."},
+	'%':{"#.#", "..#", ".#.", "#..", "#.#"},
+	'(':{"..#", ".#.", ".#.", ".#.", "..#"},
+	')':{"#..", ".#.", ".#.", ".#.", "#.."},
+	'<':{"..#", ".#.", "#..", ".#.", "..#"},
+	'>':{"#..", ".#.", "..#", ".#.", "#.."},
+	'=':{"...", "###", "...", "###", "..."},
+	'#':{"#.#", "###", "#.#", "###", "#.#"},
+	'?':{"###", "..#", ".#.", "...", ".#."},
+	' ':{"...", "...", "...", "...", "..."},
+}
+
+// The canvas producing PNG image. The shapes are not antialiased.
+type pngCanvas struct {
+	img *image.RGBA
+}
+
+func newPNGCanvas(width, height int) *pngCanvas {
+	c := pngCanvas{img:image.NewRGBA(image.Rect(0, 0, width, height))}
+	for i := range c.img.Pix {
+		c.img.Pix[i] = 0xff
+	}
+	return &c
+}
+
+func (c *pngCanvas) polyline(points []point, col color.RGBA, width float64) {
+	radius := math.Max(width / 2, 0.5)
+	for i := 1; i < len(points); i++ {
+		p0, p1 := points[i - 1], points[i]
+		steps := int(math.Ceil(math.Max(math.Abs(p1.x - p0.x), math.Abs(p1.y - p0.y)) * 2))
+		for s := 0; s <= steps; s++ {
+			t := 0.0
+			if steps > 0 {
+				t = float64(s) / float64(steps)
+			}
+			c.stamp(p0.x + t * (p1.x - p0.x), p0.y + t * (p1.y - p0.y), radius, col)
+		}
+	}
+}
+
+// Paints square of pixels with given center and half size. The pixels painted with full opacity to avoid darker spots
+// where the stamps of one line overlap.
+func (c *pngCanvas) stamp(x, y, radius float64, col color.RGBA) {
+	x0, x1 := int(math.Floor(x - radius + 0.5)), int(math.Floor(x + radius - 0.5))
+	y0, y1 := int(math.Floor(y - radius + 0.5)), int(math.Floor(y + radius - 0.5))
+	for py := y0; py <= y1; py++ {
+		for px := x0; px <= x1; px++ {
+			c.set(px, py, col)
+		}
+	}
+}
+
+// Fills polygon using scanline algorithm with even-odd rule
+func (c *pngCanvas) polygon(points []point, fill color.RGBA) {
+	if len(points) < 3 {
+		return
+	}
+	min_y, max_y := points[0].y, points[0].y
+	for _, p := range points {
+		min_y, max_y = math.Min(min_y, p.y), math.Max(max_y, p.y)
+	}
+	bounds := c.img.Bounds()
+	y_start := int(math.Max(math.Floor(min_y), float64(bounds.Min.Y)))
+	y_end := int(math.Min(math.Ceil(max_y), float64(bounds.Max.Y - 1)))
+	crossings := make([]float64, 0, len(points))
+	for y := y_start; y <= y_end; y++ {
+		// sample at the center of pixel row
+		sy := float64(y) + 0.5
+		crossings = crossings[:0]
+		for i := range points {
+			p0, p1 := points[i], points[(i + 1) % len(points)]
+			if (p0.y <= sy && p1.y > sy) || (p1.y <= sy && p0.y > sy) {
+				crossings = append(crossings, p0.x + (sy - p0.y) * (p1.x - p0.x) / (p1.y - p0.y))
+			}
+		}
+		sort.Float64s(crossings)
+		for i := 0; i + 1 < len(crossings); i += 2 {
+			for x := int(math.Ceil(crossings[i] - 0.5)); float64(x) + 0.5 <= crossings[i + 1]; x++ {
+				c.blend(x, y, fill)
+			}
+		}
+	}
+}
+
+func (c *pngCanvas) text(x, y float64, s string, anchor textAnchor, vertical bool, col color.RGBA) {
+	runes := []rune(s)
+	advance := (glyphWidth + 1) * fontScale
+	length := float64(len(runes) * advance - fontScale)
+	shift := 0.0
+	switch anchor {
+	case anchorMiddle:
+		shift = length / 2
+	case anchorEnd:
+		shift = length
+	}
+	// position of the top left corner of the text in the text direction coordinates
+	ox, oy := int(math.Round(x - shift)), int(math.Round(y)) - glyphHeight * fontScale
+	if vertical {
+		ox, oy = int(math.Round(x)) - glyphHeight * fontScale, int(math.Round(y + shift))
+	}
+	for i, r := range runes {
+		glyph, ok := glyphs[unicode.ToUpper(r)]
+		if !ok {
+			glyph = glyphs['?']
+		}
+		for gy, row := range glyph {
+			for gx, bit := range row {
+				if bit != '#' {
+					continue
+				}
+				// offset of glyph pixel along and across the text direction
+				along, across := i * advance + gx * fontScale, gy * fontScale
+				for dy := 0; dy < fontScale; dy++ {
+					for dx := 0; dx < fontScale; dx++ {
+						if vertical {
+							c.set(ox + across + dy, oy - along - dx, col)
+						} else {
+							c.set(ox + along + dx, oy + across + dy, col)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func (c *pngCanvas) write(w io.Writer) error {
+	return png.Encode(w, c.img)
+}
+
+// Sets color of the pixel, the color's alpha is ignored
+func (c *pngCanvas) set(x, y int, col color.RGBA) {
+	if image.Pt(x, y).In(c.img.Bounds()) {
+		c.img.SetRGBA(x, y, color.RGBA{R:col.R, G:col.G, B:col.B, A:0xff})
+	}
+}
+
+// Blends color of the pixel with given not premultiplied color
+func (c *pngCanvas) blend(x, y int, col color.RGBA) {
+	if !image.Pt(x, y).In(c.img.Bounds()) {
+		return
+	}
+	a := float64(col.A) / 255
+	bg := c.img.RGBAAt(x, y)
+	mix := func(fg, bg uint8) uint8 {
+		return uint8(math.Round(float64(fg) * a + float64(bg) * (1 - a)))
+	}
+	c.img.SetRGBA(x, y, color.RGBA{R:mix(col.R, bg.R), G:mix(col.G, bg.G), B:mix(col.B, bg.B), A:0xff})
+}
@@ -0,0 +1,66 @@
+package plot
+
+import (
+	"fmt"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// Returns the speciation graph of given species history: the sizes of species per generation stacked one on top of
+// the other, where each species is placed right above its parent species followed by its own descendants, thus the
+// new species branch off their parents as in the figures of the original NEAT paper.
+func SpeciationChart(history *genetics.SpeciesHistory, title string) *Chart {
+	lifespans := history.Lifespans()
+	last := history.LastGeneration()
+
+	// order species depth first by lineage
+	known := make(map[int]bool, len(lifespans))
+	for _, ls := range lifespans {
+		known[ls.SpeciesId] = true
+	}
+	children := make(map[int][]*genetics.SpeciesLifespan)
+	roots := make([]*genetics.SpeciesLifespan, 0)
+	for _, ls := range lifespans {
+		if known[ls.ParentId] && ls.ParentId != ls.SpeciesId {
+			children[ls.ParentId] = append(children[ls.ParentId], ls)
+		} else {
+			roots = append(roots, ls)
+		}
+	}
+	ordered := make([]*genetics.SpeciesLifespan, 0, len(lifespans))
+	var visit func(ls *genetics.SpeciesLifespan)
+	visit = func(ls *genetics.SpeciesLifespan) {
+		ordered = append(ordered, ls)
+		for _, child := range children[ls.SpeciesId] {
+			visit(child)
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+
+	xs := make([]float64, last + 1)
+	for i := range xs {
+		xs[i] = float64(i)
+	}
+	index := make(map[int]int, len(ordered)) // the index of species series by species ID
+	stacked := make([]Series, len(ordered))
+	for i, ls := range ordered {
+		name := fmt.Sprintf("Species %d", ls.SpeciesId)
+		if ls.ParentId >= 0 {
+			name = fmt.Sprintf("Species %d < %d", ls.SpeciesId, ls.ParentId)
+		}
+		stacked[i] = Series{Name:name, X:xs, Y:make([]float64, len(xs))}
+		index[ls.SpeciesId] = i
+	}
+	for _, r := range history.Records {
+		if r.Generation >= 0 {
+			stacked[index[r.SpeciesId]].Y[r.Generation] = float64(r.Size)
+		}
+	}
+	return &Chart{
+		Title:title,
+		XLabel:"Generation",
+		YLabel:"Organisms",
+		Stacked:stacked,
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/yaricom/goNEAT/neat"
 	"math"
 	"github.com/yaricom/goNEAT/neat/genetics"
-	"os"
 	"sort"
 )
 
@@ -30,12 +29,24 @@ type CartDoublePoleGenerationEvaluator struct {
 
 	// The flag to indicate whether to use continuous activation or discrete
 	ActionType experiments.ActionType
+
+	// The integration time step to activate networks as continuous-time recurrent neural networks (CTRNN).
+	// If zero the discrete network activation will be used.
+	CTRNNTimeStep float64
+
+	// The optional tracer to record activations of evaluated networks for debugging. Each network evaluation is
+	// recorded as separate episode, thus it's better to limit number of recorded steps per episode.
+	Tracer        *network.ActivationTracer
 }
 
 // The structure to describe cart pole emulation
 type CartPole struct {
 	// The flag to indicate that we are executing Markov experiment setup (known velocities information)
 	isMarkov            bool
+	// The integration time step for CTRNN activation, zero if discrete activation should be used
+	ctrnnTimeStep       float64
+	// The tracer to record network activations, nil if tracing disabled
+	tracer              *network.ActivationTracer
 	// Flag that we are looking at the champion in Non-Markov experiment
 	nonMarkovLong       bool
 	// Flag that we are testing champion's generalization
@@ -57,9 +68,19 @@ type CartPole struct {
 	polev_sum           float64
 }
 
+// Perform evaluation of one epoch on double pole balancing and advance population to the next epoch if winner not found
+func (ex CartDoublePoleGenerationEvaluator) GenerationEvaluate(pop *genetics.Population, epoch *experiments.Generation, context *neat.NeatContext) error {
+	if err := ex.EpochEvaluate(pop, epoch, context); err != nil {
+		return err
+	}
+	return experiments.AdvanceEpoch(pop, epoch, context)
+}
+
 // Perform evaluation of one epoch on double pole balancing
-func (ex CartDoublePoleGenerationEvaluator) GenerationEvaluate(pop *genetics.Population, epoch *experiments.Generation, context *neat.NeatContext) (err error) {
+func (ex CartDoublePoleGenerationEvaluator) EpochEvaluate(pop *genetics.Population, epoch *experiments.Generation, context *neat.NeatContext) (err error) {
 	cartPole := newCartPole(ex.Markov)
+	cartPole.ctrnnTimeStep = ex.CTRNNTimeStep
+	cartPole.tracer = ex.Tracer
 
 	cartPole.nonMarkovLong = false
 	cartPole.generalizationTest = false
@@ -199,40 +220,6 @@ func (ex CartDoublePoleGenerationEvaluator) GenerationEvaluate(pop *genetics.Pop
 	// Fill statistics about current epoch
 	epoch.FillPopulationStatistics(pop)
 
-	// Only print to file every print_every generations
-	if epoch.Solved || epoch.Id % context.PrintEvery == 0 {
-		pop_path := fmt.Sprintf("%s/gen_%d", experiments.OutDirForTrial(ex.OutputPath, epoch.TrialId), epoch.Id)
-		file, err := os.Create(pop_path)
-		if err != nil {
-			neat.ErrorLog(fmt.Sprintf("Failed to dump population, reason: %s\n", err))
-		} else {
-			pop.WriteBySpecies(file)
-		}
-	}
-
-	if epoch.Solved {
-		// print winner organism
-		for _, org := range pop.Organisms {
-			if org.IsWinner {
-				// Prints the winner organism to file!
-				org_path := fmt.Sprintf("%s/%s_%.1f_%d-%d", experiments.OutDirForTrial(ex.OutputPath, epoch.TrialId),
-					"pole2_winner", org.Fitness, org.Phenotype.NodeCount(), org.Phenotype.LinkCount())
-				file, err := os.Create(org_path)
-				if err != nil {
-					neat.ErrorLog(fmt.Sprintf("Failed to dump winner organism genome, reason: %s\n", err))
-				} else {
-					org.Genotype.Write(file)
-					neat.InfoLog(fmt.Sprintf("Generation #%d winner %d dumped to: %s\n", epoch.Id, org.Genotype.Id, org_path))
-				}
-				break
-			}
-		}
-	} else {
-		// Move to the next epoch if failed to find winner
-		neat.DebugLog(">>>>> start next generation")
-		_, err = pop.Epoch(epoch.Id + 1, context)
-	}
-
 	return err
 }
 
@@ -277,6 +264,14 @@ func (ex *CartDoublePoleGenerationEvaluator) orgEvaluate(organism *genetics.Orga
 }
 
 
+// Activates provided network either as continuous-time recurrent neural network or in discrete mode
+func (cp *CartPole) activateNet(net *network.Network) (bool, error) {
+	if cp.ctrnnTimeStep > 0 {
+		return net.ActivateCTRNN(cp.ctrnnTimeStep)
+	}
+	return net.Activate()
+}
+
 // If markov is false, then velocity information will be withheld from the network population (non-Markov)
 func newCartPole(markov bool) *CartPole {
 	return &CartPole {
@@ -293,6 +288,16 @@ func (cp *CartPole)evalNet(net *network.Network, actionType experiments.ActionTy
 	input := make([]float64, 7)
 
 	cp.resetState()
+	if cp.tracer != nil {
+		// record each evaluation as separate episode
+		cp.tracer.StartEpisode()
+		net.Tracer = cp.tracer
+		defer func() { net.Tracer = nil }()
+	}
+	if cp.ctrnnTimeStep > 0 {
+		// the CTRNN neurons state should not be carried over from previous evaluation
+		net.Flush()
+	}
 
 	if cp.isMarkov {
 		for steps = 0; steps < markov_max_steps; steps++ {
@@ -307,7 +312,7 @@ func (cp *CartPole)evalNet(net *network.Network, actionType experiments.ActionTy
 			net.LoadSensors(input)
 
 			/*-- activate the network based on the input --*/
-			if res, err := net.Activate(); !res {
+			if res, err := cp.activateNet(net); !res {
 				//If it loops, exit returning only fitness of 1 step
 				neat.DebugLog(fmt.Sprintf("Failed to activate Network, reason: %s", err))
 				return 1.0
@@ -342,7 +347,7 @@ func (cp *CartPole)evalNet(net *network.Network, actionType experiments.ActionTy
 			net.LoadSensors(input)
 
 			/*-- activate the network based on the input --*/
-			if res, err := net.Activate(); !res {
+			if res, err := cp.activateNet(net); !res {
 				// If it loops, exit returning only fitness of 1 step
 				neat.WarnLog(fmt.Sprintf("Failed to activate Network, reason: %s", err))
 				return 0.0001
@@ -6,8 +6,10 @@ import (
 	"github.com/yaricom/goNEAT/neat"
 	"fmt"
 	"github.com/yaricom/goNEAT/neat/genetics"
+	"github.com/yaricom/goNEAT/neat/esp"
 	"github.com/yaricom/goNEAT/experiments"
 	"math/rand"
+	"github.com/yaricom/goNEAT/neat/network"
 )
 
 // Run double pole-balancing experiment with Markov environment setup
@@ -111,6 +113,100 @@ func TestCartDoublePoleGenerationEvaluator_GenerationEvaluateMarkov(t *testing.T
 	t.Logf("Trials solved/run: %d/%d", solved_trials, len(experiment.Trials))
 }
 
+// Run double pole-balancing experiment with Markov environment setup by Enforced Sub-Populations method
+func TestCartDoublePoleGenerationEvaluator_GenerationEvaluateMarkovESP(t *testing.T) {
+	// to make sure we have predictable results
+	rand.Seed(423)
+
+	// The single run of POLE2 Markov experiment with networks of five hidden neurons
+	experiment, err := executeESP("../../out/pole2_markov_esp_test", "../../data/pole2_markov.neat",
+		"../../data/pole2_markov_startgenes", true, esp.Options{
+			HiddenNeurons:5,
+			SubPopSize:20,
+			TrialsPerNeuron:10,
+			MutationRate:0.4,
+			MutationPower:0.3,
+			BurstStagnation:20,
+		})
+	if err != nil {
+		t.Error("Failed to perform POLE2 Markov ESP experiment:", err)
+		return
+	}
+
+	trial := experiment.Trials[0]
+	if !trial.Solved() {
+		t.Error("POLE2 Markov ESP experiment not solved in generations:", len(trial.Generations))
+	}
+	t.Logf("Solved in generations: %d\n", len(trial.Generations))
+}
+
+// Run double pole-balancing experiment with Non-Markov environment setup by Enforced Sub-Populations method to be
+// compared with NEAT
+func TestCartDoublePoleGenerationEvaluator_GenerationEvaluateNonMarkovESP(t *testing.T) {
+	// to make sure we have predictable results
+	rand.Seed(423)
+
+	// The single run of POLE2 Non-Markov experiment with recurrent networks of five hidden neurons, which have the same
+	// number of evaluations per generation as NEAT population
+	experiment, err := executeESP("../../out/pole2_non-markov_esp_test", "../../data/pole2_non-markov.neat",
+		"../../data/pole2_non-markov_startgenes", false, esp.Options{
+			HiddenNeurons:5,
+			SubPopSize:100,
+			TrialsPerNeuron:10,
+			MutationRate:0.4,
+			MutationPower:0.3,
+			BurstStagnation:20,
+			Recurrent:true,
+		})
+	if err != nil {
+		t.Error("Failed to perform POLE2 Non-Markov ESP experiment:", err)
+		return
+	}
+
+	trial := experiment.Trials[0]
+	first, best := trial.Generations[0].Best.Fitness, trial.BestFitness().Max()
+	if best <= first {
+		t.Error("The fitness not improved by ESP", first, best)
+	}
+	t.Logf("Solved: %t, generations: %d, best fitness: %f\n", trial.Solved(), len(trial.Generations), best)
+}
+
+// Executes single run of double pole-balancing experiment by Enforced Sub-Populations method with given options
+func executeESP(out_dir_path, context_path, genome_path string, markov bool, options esp.Options) (*experiments.Experiment, error) {
+	// Load context configuration
+	configFile, err := os.Open(context_path)
+	if err != nil {
+		return nil, err
+	}
+	context := neat.LoadContext(configFile)
+	context.NumRuns = 1
+	neat.LogLevel = neat.LogLevelInfo
+
+	// Load Genome
+	genomeFile, err := os.Open(genome_path)
+	if err != nil {
+		return nil, err
+	}
+	start_genome, err := genetics.ReadGenome(genomeFile, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// create output dir
+	os.RemoveAll(out_dir_path)
+	if err = os.MkdirAll(out_dir_path, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	experiment := &experiments.Experiment{Id:0}
+	err = experiment.ExecuteESP(context, start_genome, CartDoublePoleGenerationEvaluator{
+		OutputPath:out_dir_path,
+		Markov:markov,
+		ActionType:experiments.ContinuousAction,
+	}, options)
+	return experiment, err
+}
+
 // Run double pole-balancing experiment with Non-Markov environment setup
 func TestCartDoublePoleGenerationEvaluator_GenerationEvaluateNonMarkov(t *testing.T) {
 	// to make sure we have predictable results
@@ -221,3 +317,38 @@ func TestCartDoublePoleGenerationEvaluator_GenerationEvaluateNonMarkov(t *testin
 	}
 	t.Logf("Best Generalization Score: %.0f\n", best_g_score)
 }
+
+// Test that activations of evaluated network recorded by tracer
+func TestCartPole_evalNetTraced(t *testing.T) {
+	genomeFile, err := os.Open("../../data/pole2_markov_startgenes")
+	if err != nil {
+		t.Error("Failed to open genome file")
+		return
+	}
+	start_genome, err := genetics.ReadGenome(genomeFile, 1)
+	if err != nil {
+		t.Error("Failed to read start genome")
+		return
+	}
+	net := genetics.NewOrganism(0.0, start_genome, 1).Phenotype
+
+	cartPole := newCartPole(true)
+	cartPole.tracer = network.NewActivationTracer(10)
+	for i := 0; i < 2; i++ {
+		steps := cartPole.evalNet(net, experiments.ContinuousAction)
+		if steps == 0 {
+			t.Error("No steps performed")
+		}
+	}
+	if net.Tracer != nil {
+		t.Error("Tracer should be detached from network after evaluation")
+	}
+	if cartPole.tracer.EpisodesCount() != 2 {
+		t.Error("Wrong number of episodes recorded", 2, cartPole.tracer.EpisodesCount())
+	}
+	for _, tr := range cartPole.tracer.Traces {
+		if len(tr.Nodes) != net.NodeCount() {
+			t.Error("Wrong number of nodes traced", net.NodeCount(), len(tr.Nodes))
+		}
+	}
+}
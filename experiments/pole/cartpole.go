@@ -24,8 +24,16 @@ type CartPoleGenerationEvaluator struct {
 	WinBalancingSteps int
 }
 
+// This method evaluates one epoch for given population and advances population to the next epoch if winner not found.
+func (ex CartPoleGenerationEvaluator) GenerationEvaluate(pop *genetics.Population, epoch *experiments.Generation, context *neat.NeatContext) error {
+	if err := ex.EpochEvaluate(pop, epoch, context); err != nil {
+		return err
+	}
+	return experiments.AdvanceEpoch(pop, epoch, context)
+}
+
 // This method evaluates one epoch for given population and prints results into output directory if any.
-func (ex CartPoleGenerationEvaluator) GenerationEvaluate(pop *genetics.Population, epoch *experiments.Generation, context *neat.NeatContext) (err error) {
+func (ex CartPoleGenerationEvaluator) EpochEvaluate(pop *genetics.Population, epoch *experiments.Generation, context *neat.NeatContext) (err error) {
 	// Evaluate each organism on a test
 	for _, org := range pop.Organisms {
 		res := ex.orgEvaluate(org)
@@ -54,40 +62,6 @@ func (ex CartPoleGenerationEvaluator) GenerationEvaluate(pop *genetics.Populatio
 	// Fill statistics about current epoch
 	epoch.FillPopulationStatistics(pop)
 
-	// Only print to file every print_every generations
-	if epoch.Solved || epoch.Id % context.PrintEvery == 0 {
-		pop_path := fmt.Sprintf("%s/gen_%d", experiments.OutDirForTrial(ex.OutputPath, epoch.TrialId), epoch.Id)
-		file, err := os.Create(pop_path)
-		if err != nil {
-			neat.ErrorLog(fmt.Sprintf("Failed to dump population, reason: %s\n", err))
-		} else {
-			pop.WriteBySpecies(file)
-		}
-	}
-
-	if epoch.Solved {
-		// print winner organism
-		for _, org := range pop.Organisms {
-			if org.IsWinner {
-				// Prints the winner organism to file!
-				org_path := fmt.Sprintf("%s/%s_%d-%d", experiments.OutDirForTrial(ex.OutputPath, epoch.TrialId),
-					"pole1_winner", org.Phenotype.NodeCount(), org.Phenotype.LinkCount())
-				file, err := os.Create(org_path)
-				if err != nil {
-					neat.ErrorLog(fmt.Sprintf("Failed to dump winner organism genome, reason: %s\n", err))
-				} else {
-					org.Genotype.Write(file)
-					neat.InfoLog(fmt.Sprintf("Generation #%d winner dumped to: %s\n", epoch.Id, org_path))
-				}
-				break
-			}
-		}
-	} else {
-		// Move to the next epoch if failed to find winner
-		neat.DebugLog(">>>>> start next generation")
-		_, err = pop.Epoch(epoch.Id + 1, context)
-	}
-
 	return err
 }
 
@@ -137,6 +111,8 @@ func (ex *CartPoleGenerationEvaluator) runCart(net *network.Network) (steps int)
 	}
 
 	in := make([]float64, 5)
+	// The networks evolved with per-node bias may have no bias sensor - skip bias input for them
+	has_bias := len(net.Inputs) >= len(in)
 	for steps = 0; steps < ex.WinBalancingSteps; steps++ {
 		/*-- setup the input layer based on the four inputs --*/
 		in[0] = 1.0  // Bias
@@ -144,7 +120,11 @@ func (ex *CartPoleGenerationEvaluator) runCart(net *network.Network) (steps int)
 		in[2] = (x_dot + .75) / 1.5
 		in[3] = (theta + twelve_degrees) / .41
 		in[4] = (theta_dot + 1.0) / 2.0
-		net.LoadSensors(in)
+		if has_bias {
+			net.LoadSensors(in)
+		} else {
+			net.LoadSensors(in[1:])
+		}
 
 		/*-- activate the network based on the input --*/
 		if res, err := net.Activate(); !res {
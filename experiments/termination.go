@@ -0,0 +1,123 @@
+package experiments
+
+import (
+	"time"
+	"math"
+	"errors"
+	"fmt"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The reason why trial was terminated
+type TerminationReason string
+
+// The supported termination reasons
+const (
+	// The trial is not terminated yet, e.g. it was read from the data of previous format versions
+	TerminationNone TerminationReason = ""
+	// The problem was solved
+	TerminationSolved TerminationReason = "solved"
+	// The maximal number of generations was evaluated
+	TerminationGenerations TerminationReason = "generations"
+	// The fitness of the best organism reached target value
+	TerminationFitnessTarget TerminationReason = "fitness_target"
+	// The wall-clock time budget exhausted
+	TerminationTimeBudget TerminationReason = "time_budget"
+	// The evaluations budget exhausted
+	TerminationEvaluationsBudget TerminationReason = "evaluations_budget"
+	// The population stagnated with no restart policy
+	TerminationStagnation TerminationReason = "stagnation"
+	// The trial was stopped by experiment observer
+	TerminationObserver TerminationReason = "observer"
+)
+
+// The checker of trial termination criteria configured in the context
+type terminationCriteria struct {
+	// The context holding criteria
+	context      *neat.NeatContext
+	// The time when trial started
+	started      time.Time
+	// The number of organisms evaluated in trial
+	evaluations  int
+	// The best fitness since the start of trial or the last restart
+	bestFitness  float64
+	// The generation where the best fitness was improved the last time or population restarted
+	lastImproved int
+	// The hall of fame of trial, nil if not used by restart policy
+	hallOfFame   *genetics.HallOfFame
+}
+
+// Checks whether termination criteria and restart policy in the context are valid
+func validateTermination(context *neat.NeatContext) error {
+	if context.FitnessTarget < 0 || context.TimeBudget < 0 || context.EvaluationsBudget < 0 ||
+		context.StagnationGenerations < 0 {
+		return errors.New("Termination criteria should not be negative")
+	}
+	switch context.RestartPolicy {
+	case neat.NoRestart, neat.RestartFromStartGenome:
+	case neat.RestartFromHallOfFame:
+		if context.HallOfFameSize <= 0 {
+			return errors.New(fmt.Sprintf("Hall of fame size should be positive to restart from it, found: %d",
+				context.HallOfFameSize))
+		}
+	default:
+		return errors.New(fmt.Sprintf("Unsupported restart policy: %s", context.RestartPolicy))
+	}
+	return nil
+}
+
+// Creates checker of termination criteria of trial started now
+func newTerminationCriteria(context *neat.NeatContext) *terminationCriteria {
+	c := &terminationCriteria{
+		context:context,
+		started:time.Now(),
+		bestFitness:math.Inf(-1),
+	}
+	if context.RestartPolicy == neat.RestartFromHallOfFame {
+		c.hallOfFame = genetics.NewHallOfFame(context.HallOfFameSize)
+	}
+	return c
+}
+
+// Updates the state with evaluated generation of population and returns the reason to terminate trial or
+// TerminationNone if trial should continue. The TerminationStagnation is returned even if population can be
+// restarted according to the restart policy.
+func (c *terminationCriteria) check(epoch *Generation, pop *genetics.Population) TerminationReason {
+	c.evaluations += len(pop.Organisms)
+	if c.hallOfFame != nil {
+		c.hallOfFame.Update(pop, epoch.Id)
+	}
+	if epoch.Best != nil && epoch.Best.Fitness > c.bestFitness {
+		c.bestFitness = epoch.Best.Fitness
+		c.lastImproved = epoch.Id
+	}
+
+	if c.context.FitnessTarget > 0 && c.bestFitness >= c.context.FitnessTarget {
+		return TerminationFitnessTarget
+	}
+	if c.context.TimeBudget > 0 && time.Since(c.started).Seconds() >= c.context.TimeBudget {
+		return TerminationTimeBudget
+	}
+	if c.context.EvaluationsBudget > 0 && c.evaluations >= c.context.EvaluationsBudget {
+		return TerminationEvaluationsBudget
+	}
+	if c.context.StagnationGenerations > 0 && epoch.Id - c.lastImproved >= c.context.StagnationGenerations {
+		return TerminationStagnation
+	}
+	return TerminationNone
+}
+
+// Returns genomes to reseed stagnated population from according to the restart policy
+func (c *terminationCriteria) seeds(start_genome *genetics.Genome) []*genetics.Genome {
+	if c.context.RestartPolicy == neat.RestartFromHallOfFame {
+		return c.hallOfFame.Genomes()
+	}
+	return []*genetics.Genome{start_genome}
+}
+
+// Resets the stagnation detector when population restarted to be evaluated in the given generation, thus the
+// restarted population has the same number of generations to improve
+func (c *terminationCriteria) restarted(generation int) {
+	c.bestFitness, c.lastImproved = math.Inf(-1), generation
+}
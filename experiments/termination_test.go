@@ -0,0 +1,139 @@
+package experiments
+
+import (
+	"bytes"
+	"testing"
+	"math/rand"
+	"reflect"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The evaluator assigning the same fitness to all organisms, thus the population never improves
+type constantFitnessEvaluator struct {
+	fitness float64
+}
+
+func (e constantFitnessEvaluator) EpochEvaluate(pop *genetics.Population, epoch *Generation, context *neat.NeatContext) error {
+	for _, org := range pop.Organisms {
+		org.Fitness = e.fitness
+	}
+	epoch.FillPopulationStatistics(pop)
+	return nil
+}
+
+func terminationTestContext() *neat.NeatContext {
+	context := observerTestContext()
+	context.NumRuns = 1
+	context.NumGenerations = 7
+	neat.LogLevel = neat.LogLevelWarning
+	return context
+}
+
+func TestExperiment_ExecuteTermination(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(context *neat.NeatContext)
+		evaluator EpochEvaluator
+		reason    TerminationReason
+		length    int
+	}{
+		{"generations", func(context *neat.NeatContext) {},
+			&randomFitnessEvaluator{}, TerminationGenerations, 7},
+		{"fitness target", func(context *neat.NeatContext) {context.FitnessTarget = 0.5},
+			&randomFitnessEvaluator{}, TerminationFitnessTarget, 1},
+		{"time budget", func(context *neat.NeatContext) {context.TimeBudget = 1e-9},
+			&randomFitnessEvaluator{}, TerminationTimeBudget, 1},
+		{"evaluations budget", func(context *neat.NeatContext) {context.EvaluationsBudget = context.PopSize * 3},
+			&randomFitnessEvaluator{}, TerminationEvaluationsBudget, 3},
+		{"stagnation", func(context *neat.NeatContext) {context.StagnationGenerations = 2},
+			constantFitnessEvaluator{fitness:0.5}, TerminationStagnation, 3},
+		{"solved", func(context *neat.NeatContext) {context.StagnationGenerations = 2},
+			solvingEvaluator{solveAt:1}, TerminationSolved, 2},
+	}
+	for _, test := range tests {
+		rand.Seed(42)
+		context := terminationTestContext()
+		test.configure(context)
+		ex := Experiment{}
+		if err := ex.Execute(context, buildTestGenome(1), test.evaluator); err != nil {
+			t.Fatal(test.name, err)
+		}
+		trial := ex.Trials[0]
+		if trial.Termination != test.reason || len(trial.Generations) != test.length {
+			t.Error("Wrong termination", test.name, trial.Termination, len(trial.Generations))
+		}
+		if len(trial.Restarts) != 0 {
+			t.Error("Trial should not be restarted", test.name, trial.Restarts)
+		}
+	}
+}
+
+func TestExperiment_ExecuteRestart(t *testing.T) {
+	for _, policy := range []neat.RestartPolicy{neat.RestartFromStartGenome, neat.RestartFromHallOfFame} {
+		rand.Seed(42)
+		context := terminationTestContext()
+		context.StagnationGenerations = 2
+		context.RestartPolicy = policy
+		context.HallOfFameSize = 3
+
+		observer := newRecordingObserver()
+		ex := Experiment{}
+		ex.AddObserver(observer)
+		err := ex.Execute(context, buildTestGenome(1), constantFitnessEvaluator{fitness:0.5})
+		if err != nil {
+			t.Fatal(policy, err)
+		}
+
+		// the restarted population has the same number of generations to improve
+		trial := ex.Trials[0]
+		if !reflect.DeepEqual(trial.Restarts, []int{3, 6}) {
+			t.Error("Wrong restarts", policy, trial.Restarts)
+		}
+		if trial.Termination != TerminationGenerations || trial.RestartPolicy != policy {
+			t.Error("Wrong termination", policy, trial.Termination, trial.RestartPolicy)
+		}
+		if len(trial.Generations) != context.NumGenerations {
+			t.Error("Wrong number of generations", policy, len(trial.Generations))
+		}
+		for _, epoch := range trial.Generations {
+			if epoch.Metrics.Organisms != context.PopSize {
+				t.Error("Wrong population size", policy, epoch.Id, epoch.Metrics.Organisms)
+			}
+		}
+		if observer.count("extinct") == 0 {
+			t.Error("Species of restarted population should go extinct", policy)
+		}
+
+		// termination and restarts are persisted
+		var buff bytes.Buffer
+		if err = ex.Write(&buff); err != nil {
+			t.Fatal(err)
+		}
+		read := Experiment{}
+		if err = read.Read(&buff); err != nil {
+			t.Fatal(err)
+		}
+		restored := read.Trials[0]
+		if restored.Termination != trial.Termination || restored.RestartPolicy != policy ||
+			!reflect.DeepEqual(restored.Restarts, trial.Restarts) {
+			t.Error("Wrong restored termination", policy, restored.Termination, restored.RestartPolicy, restored.Restarts)
+		}
+	}
+}
+
+func TestExperiment_ExecuteTerminationInvalid(t *testing.T) {
+	configs := []func(context *neat.NeatContext){
+		func(context *neat.NeatContext) {context.EvaluationsBudget = -1},
+		func(context *neat.NeatContext) {context.RestartPolicy = neat.RestartFromHallOfFame},
+		func(context *neat.NeatContext) {context.RestartPolicy = neat.RestartPolicy(10)},
+	}
+	for i, configure := range configs {
+		context := terminationTestContext()
+		configure(context)
+		ex := Experiment{}
+		if err := ex.Execute(context, buildTestGenome(1), &randomFitnessEvaluator{}); err == nil {
+			t.Error("Invalid termination configuration accepted", i)
+		}
+	}
+}
@@ -2,9 +2,13 @@ package experiments
 
 import (
 	"time"
+	"github.com/yaricom/goNEAT/neat"
 	"github.com/yaricom/goNEAT/neat/genetics"
 	"sort"
 	"encoding/gob"
+	"io"
+	"errors"
+	"fmt"
 )
 
 // The structure to hold statistics about one experiment run (trial)
@@ -15,6 +19,22 @@ type Trial struct {
 	Generations      Generations
 	// The winner generation
 	WinnerGeneration *Generation
+	// The results per generation of each island if trial was executed with island model, where Generations hold
+	// the statistics of all islands combined
+	Islands          []Generations
+	// The results per generation of parasite population if trial was executed with competitive coevolution, where
+	// Generations hold the statistics of host population
+	Parasites        Generations
+	// The reason why trial was terminated
+	Termination      TerminationReason
+	// The policy used to restart stagnated population
+	RestartPolicy    neat.RestartPolicy
+	// The generations where stagnated population was restarted, i.e. the first generations of restarted populations
+	Restarts         []int
+	// The genealogy of organisms evaluated in this trial, nil if it was not tracked. It is not persisted with trial data.
+	Genealogy        *genetics.Genealogy
+	// The history of species in this trial, nil if it was not tracked. It is not persisted with trial data.
+	SpeciesHistory   *genetics.SpeciesHistory
 }
 
 func (t Trial) LastExecuted() time.Time {
@@ -28,11 +48,13 @@ func (t Trial) LastExecuted() time.Time {
 }
 
 // Finds the most fit organism among all epochs in this trial. It's also possible to get the best organism only among the ones
-// which was able to solve the experiment's problem.
+// which was able to solve the experiment's problem. The epochs without the best organism are skipped.
 func (t Trial) BestOrganism(onlySolvers bool) (*genetics.Organism, bool) {
 	var orgs = make(genetics.Organisms, 0, len(t.Generations))
 	for _, e := range t.Generations {
-		if !onlySolvers {
+		if e.Best == nil {
+			continue
+		} else if !onlySolvers {
 			// include all the most fit in each epoch
 			orgs = append(orgs, e.Best)
 		} else if e.Solved {
@@ -57,30 +79,38 @@ func (t Trial) Solved() bool {
 	return false
 }
 
-// Fitness returns the fitnesses of the best organisms for each epoch in this trial
+// Fitness returns the fitnesses of the best organisms for each epoch in this trial. The epochs without the best
+// organism are skipped.
 func (t Trial) BestFitness() Floats {
-	var x Floats = make([]float64, len(t.Generations))
-	for i, e := range t.Generations {
-		x[i] = e.Best.Fitness
-
+	var x Floats = make([]float64, 0, len(t.Generations))
+	for _, e := range t.Generations {
+		if e.Best != nil {
+			x = append(x, e.Best.Fitness)
+		}
 	}
 	return x
 }
 
-// Age returns the age of the best species for each epoch in this trial
+// Age returns the age of the best species for each epoch in this trial. The epochs without the best organism or
+// with the best organism which species is unknown (e.g. loaded from legacy data) are skipped.
 func (t Trial) BestAge() Floats {
-	var x Floats = make([]float64, len(t.Generations))
-	for i, e := range t.Generations {
-		x[i] = float64(e.Best.Species.Age)
+	var x Floats = make([]float64, 0, len(t.Generations))
+	for _, e := range t.Generations {
+		if e.Best != nil && e.Best.Species != nil {
+			x = append(x, float64(e.Best.Species.Age))
+		}
 	}
 	return x
 }
 
-// Complexity returns the complexity of the best species for each epoch in this trial
+// Complexity returns the complexity of the best species for each epoch in this trial. The epochs without the best
+// organism are skipped.
 func (t Trial) BestComplexity() Floats {
-	var x Floats = make([]float64, len(t.Generations))
-	for i, e := range t.Generations {
-		x[i] = float64(e.Best.Phenotype.Complexity())
+	var x Floats = make([]float64, 0, len(t.Generations))
+	for _, e := range t.Generations {
+		if e.Best != nil {
+			x = append(x, float64(e.Best.Phenotype.Complexity()))
+		}
 	}
 	return x
 }
@@ -129,39 +159,122 @@ func (t *Trial) Winner() (nodes, genes, evals, diversity int) {
 	return nodes, genes, evals, diversity
 }
 
+// Writes the genealogy of this trial as a graph in the GraphViz DOT format
+func (t Trial) WriteGenealogyDOT(w io.Writer) error {
+	if t.Genealogy == nil {
+		return errors.New(fmt.Sprintf("Genealogy was not tracked for trial: %d", t.Id))
+	}
+	return t.Genealogy.WriteDOT(w)
+}
+
+// Writes the ancestry graph of the winner organism of this trial in the GraphViz DOT format
+func (t Trial) WriteWinnerAncestryDOT(w io.Writer) error {
+	if t.Genealogy == nil {
+		return errors.New(fmt.Sprintf("Genealogy was not tracked for trial: %d", t.Id))
+	}
+	for _, e := range t.Generations {
+		if e.Solved && e.Best != nil {
+			return t.Genealogy.WriteAncestorsDOT(w, e.Id, e.Best.Genotype.Id)
+		}
+	}
+	return errors.New(fmt.Sprintf("No winner found in trial: %d", t.Id))
+}
+
 // Encodes this trial
 func (t *Trial) Encode(enc *gob.Encoder) error {
-	err := enc.Encode(t.Id)
-	err = enc.Encode(len(t.Generations))
-	for _, e := range t.Generations {
-		err = e.Encode(enc)
-		if err != nil {
+	if err := enc.Encode(t.Id); err != nil {
+		return err
+	}
+	if err := encodeGenerations(enc, t.Generations); err != nil {
+		return err
+	}
+	if err := enc.Encode(len(t.Islands)); err != nil {
+		return err
+	}
+	for _, island := range t.Islands {
+		if err := encodeGenerations(enc, island); err != nil {
 			return err
 		}
 	}
-	return err
+	if err := encodeValues(enc, string(t.Termination), byte(t.RestartPolicy), t.Restarts); err != nil {
+		return err
+	}
+	return encodeGenerations(enc, t.Parasites)
 }
 
-// Decodes trial data
+func encodeGenerations(enc *gob.Encoder, generations Generations) error {
+	if err := enc.Encode(len(generations)); err != nil {
+		return err
+	}
+	for _, e := range generations {
+		if err := e.Encode(enc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decodes trial data encoded with the current format version
 func (t *Trial) Decode(dec *gob.Decoder) error {
-	err := dec.Decode(&t.Id)
-	var ngen int
-	err = dec.Decode(&ngen)
-	if err != nil {
+	return t.decode(dec, FormatVersion)
+}
+
+// Decodes trial data encoded with given format version
+func (t *Trial) decode(dec *gob.Decoder, version int) (err error) {
+	if err = dec.Decode(&t.Id); err != nil {
 		return err
 	}
-	t.Generations = make([]Generation, ngen)
-	for i := 0; i < ngen; i++ {
-		gen := Generation{}
-		err = gen.Decode(dec)
-		if err != nil {
+	if t.Generations, err = decodeGenerations(dec, version); err != nil {
+		return err
+	}
+	if version < formatLegacyIslands {
+		// no islands in the earlier formats
+		return nil
+	}
+	var nislands int
+	if err = dec.Decode(&nislands); err != nil {
+		return err
+	}
+	if nislands > 0 {
+		t.Islands = make([]Generations, nislands)
+	}
+	for i := range t.Islands {
+		if t.Islands[i], err = decodeGenerations(dec, version); err != nil {
 			return err
 		}
-		t.Generations[i] = gen
+	}
+	if version < formatVersion4 {
+		return nil
+	}
+	var termination string
+	var policy byte
+	if err = decodeValues(dec, &termination, &policy, &t.Restarts); err != nil {
+		return err
+	}
+	t.Termination, t.RestartPolicy = TerminationReason(termination), neat.RestartPolicy(policy)
+	if version < formatVersion6 {
+		return nil
+	}
+	if t.Parasites, err = decodeGenerations(dec, version); err == nil && len(t.Parasites) == 0 {
+		t.Parasites = nil
 	}
 	return err
 }
 
+func decodeGenerations(dec *gob.Decoder, version int) (Generations, error) {
+	var ngen int
+	if err := dec.Decode(&ngen); err != nil {
+		return nil, err
+	}
+	generations := make(Generations, ngen)
+	for i := range generations {
+		if err := generations[i].decode(dec, version); err != nil {
+			return nil, err
+		}
+	}
+	return generations, nil
+}
+
 // Trials is a sortable collection of experiment runs (trials) by execution time and id
 type Trials []Trial
 
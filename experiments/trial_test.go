@@ -4,7 +4,10 @@ import (
 	"testing"
 	"math"
 	"bytes"
+	"reflect"
 	"encoding/gob"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/genetics"
 )
 
 func TestTrial_Encode_Decode(t *testing.T) {
@@ -35,6 +38,91 @@ func TestTrial_Encode_Decode(t *testing.T) {
 	deepCompareTrials(trial, &dec_trial, t)
 }
 
+func TestTrial_Encode_DecodeIslands(t *testing.T) {
+	trial := buildTestTrial(2, 3)
+	trial.Islands = []Generations{
+		buildTestTrial(0, 3).Generations,
+		buildTestTrial(0, 2).Generations,
+	}
+
+	var buff bytes.Buffer
+	err := trial.Encode(gob.NewEncoder(&buff))
+	if err != nil {
+		t.Fatal("failed to encode Trial", err)
+	}
+	dec_trial := Trial{}
+	err = dec_trial.Decode(gob.NewDecoder(&buff))
+	if err != nil {
+		t.Fatal("failed to decode trial", err)
+	}
+	deepCompareTrials(trial, &dec_trial, t)
+}
+
+func TestTrial_Encode_DecodeParasites(t *testing.T) {
+	trial := buildTestTrial(3, 3)
+	trial.Parasites = buildTestTrial(0, 3).Generations
+
+	var buff bytes.Buffer
+	err := trial.Encode(gob.NewEncoder(&buff))
+	if err != nil {
+		t.Fatal("failed to encode Trial", err)
+	}
+	dec_trial := Trial{}
+	err = dec_trial.Decode(gob.NewDecoder(&buff))
+	if err != nil {
+		t.Fatal("failed to decode trial", err)
+	}
+	deepCompareTrials(trial, &dec_trial, t)
+}
+
+func TestTrial_Encode_DecodeNoBest(t *testing.T) {
+	trial := buildTestTrial(4, 3)
+	trial.Generations[0].Best.Species = genetics.NewSpecies(1)
+	trial.Generations[0].Best.Species.Age = 5
+	trial.Generations[1].Best = nil
+
+	var buff bytes.Buffer
+	err := trial.Encode(gob.NewEncoder(&buff))
+	if err != nil {
+		t.Fatal("failed to encode Trial", err)
+	}
+	dec_trial := Trial{}
+	err = dec_trial.Decode(gob.NewDecoder(&buff))
+	if err != nil {
+		t.Fatal("failed to decode trial", err)
+	}
+	if dec_trial.Generations[1].Best != nil {
+		t.Fatal("The best organism restored", dec_trial.Generations[1].Best)
+	}
+
+	// the generation without best organism is skipped
+	if fitness := dec_trial.BestFitness(); len(fitness) != 2 || fitness[1] != trial.Generations[2].Best.Fitness {
+		t.Error("Wrong best fitness", fitness)
+	}
+	if complexity := dec_trial.BestComplexity(); len(complexity) != 2 {
+		t.Error("Wrong best complexity", complexity)
+	}
+	// the organism without species is skipped
+	if age := dec_trial.BestAge(); len(age) != 1 || age[0] != 5 {
+		t.Error("Wrong best age", age)
+	}
+	if org, found := dec_trial.BestOrganism(true); !found || org.Fitness != trial.Generations[2].Best.Fitness {
+		t.Error("Wrong best organism", org, found)
+	}
+
+	// the trial which winner generation has no best organism
+	no_best := buildTestTrial(5, 1)
+	no_best.Generations[0].Best = nil
+	if _, found := no_best.BestOrganism(false); found {
+		t.Error("The best organism found")
+	}
+	ex := Experiment{Id:1, Name:"Test No Best", Trials:Trials{dec_trial, *no_best}}
+	if age := ex.BestAge(); len(age) != 2 || age[0] != 0 || age[1] != 0 {
+		t.Error("Wrong experiment best age", age)
+	}
+	ex.PrintStatistics()
+}
+
 func deepCompareTrials(first, second *Trial, t *testing.T) {
 	if first.Id != second.Id {
 		t.Error("first.Id != second.Id")
@@ -47,10 +135,44 @@ func deepCompareTrials(first, second *Trial, t *testing.T) {
 	for i := 0; i < len(first.Generations); i++ {
 		deepCompareGenerations(&first.Generations[i], &second.Generations[i], t)
 	}
+	if first.Termination != second.Termination || first.RestartPolicy != second.RestartPolicy {
+		t.Error("Wrong termination", second.Termination, second.RestartPolicy)
+	}
+	if len(first.Restarts) != 0 || len(second.Restarts) != 0 {
+		if !reflect.DeepEqual(first.Restarts, second.Restarts) {
+			t.Error("first.Restarts != second.Restarts", first.Restarts, second.Restarts)
+		}
+	}
+	if len(first.Parasites) != len(second.Parasites) {
+		t.Error("len(first.Parasites) != len(second.Parasites)")
+	} else {
+		for i := range first.Parasites {
+			deepCompareGenerations(&first.Parasites[i], &second.Parasites[i], t)
+		}
+	}
+	if len(first.Islands) != len(second.Islands) {
+		t.Error("len(first.Islands) != len(second.Islands)")
+		return
+	}
+	for i := range first.Islands {
+		if len(first.Islands[i]) != len(second.Islands[i]) {
+			t.Errorf("len(first.Islands[%d]) != len(second.Islands[%d])", i, i)
+			continue
+		}
+		for j := range first.Islands[i] {
+			deepCompareGenerations(&first.Islands[i][j], &second.Islands[i][j], t)
+		}
+	}
 }
 
 func buildTestTrial(id, num_generations int) *Trial {
-	trial := Trial{Id:id, Generations:make([]Generation, num_generations)}
+	trial := Trial{
+		Id:id,
+		Generations:make([]Generation, num_generations),
+		Termination:TerminationStagnation,
+		RestartPolicy:neat.RestartFromHallOfFame,
+		Restarts:[]int{num_generations / 2},
+	}
 	for i := 0; i < num_generations; i++ {
 		trial.Generations[i] = *buildTestGeneration(i + 1, float64(i + 1) * math.E)
 	}
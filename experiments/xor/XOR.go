@@ -12,11 +12,23 @@ import (
 	"github.com/yaricom/goNEAT/neat/genetics"
 	"math"
 	"github.com/yaricom/goNEAT/experiments"
+	"github.com/yaricom/goNEAT/neat/network"
 )
 
 // The precision to use for XOR evaluation, i.e. one is x > 1 - precision and zero is x < precision
 const precision = 0.5
 
+// The tolerance of neurons activations change to consider network relaxed on particular input
+const relaxation_tolerance = 1e-10
+// The minimal number of relaxation iterations used when the depth of network with loop can not be estimated, the same
+// as the maximal number of attempts to activate network outputs
+const min_relaxation_iterations = 20
+
+// The weight below which links are removed from the winner genome during simplification
+const simplify_weight_tolerance = 1e-3
+// The maximal allowed difference of the outputs of winner genome after simplification
+const simplify_epsilon = 1e-3
+
 // XOR is very simple and does not make a very interesting scientific experiment; however, it is a good way to
 // check whether your system works.
 // Make sure recurrency is disabled for the XOR test. If NEAT is able to add recurrent connections, it may solve XOR by
@@ -31,8 +43,16 @@ type XORGenerationEvaluator struct {
 	OutputPath string
 }
 
+// This method evaluates one epoch for given population and advances population to the next epoch if winner not found.
+func (ex XORGenerationEvaluator) GenerationEvaluate(pop *genetics.Population, epoch *experiments.Generation, context *neat.NeatContext) error {
+	if err := ex.EpochEvaluate(pop, epoch, context); err != nil {
+		return err
+	}
+	return experiments.AdvanceEpoch(pop, epoch, context)
+}
+
 // This method evaluates one epoch for given population and prints results into output directory if any.
-func (ex XORGenerationEvaluator) GenerationEvaluate(pop *genetics.Population, epoch *experiments.Generation, context *neat.NeatContext) (err error) {
+func (ex XORGenerationEvaluator) EpochEvaluate(pop *genetics.Population, epoch *experiments.Generation, context *neat.NeatContext) (err error) {
 	// Evaluate each organism on a test
 	for _, org := range pop.Organisms {
 		res, err := ex.org_evaluate(org, context)
@@ -64,58 +84,67 @@ func (ex XORGenerationEvaluator) GenerationEvaluate(pop *genetics.Population, ep
 	// Fill statistics about current epoch
 	epoch.FillPopulationStatistics(pop)
 
-	// Only print to file every print_every generations
-	if epoch.Solved || epoch.Id % context.PrintEvery == 0 {
-		pop_path := fmt.Sprintf("%s/gen_%d", experiments.OutDirForTrial(ex.OutputPath, epoch.TrialId), epoch.Id)
-		file, err := os.Create(pop_path)
-		if err != nil {
-			neat.ErrorLog(fmt.Sprintf("Failed to dump population, reason: %s\n", err))
-		} else {
-			pop.WriteBySpecies(file)
-		}
-	}
-
 	if epoch.Solved {
-		// print winner organism
+		// Prints the winner genome without dead structure, the winner itself dumped by experiments.PopulationDumper
 		for _, org := range pop.Organisms {
 			if org.IsWinner {
-				// Prints the winner organism to file!
-				org_path := fmt.Sprintf("%s/%s_%d-%d", experiments.OutDirForTrial(ex.OutputPath, epoch.TrialId),
-					"xor_winner", org.Phenotype.NodeCount(), org.Phenotype.LinkCount())
-				file, err := os.Create(org_path)
-				if err != nil {
-					neat.ErrorLog(fmt.Sprintf("Failed to dump winner organism genome, reason: %s\n", err))
-				} else {
-					org.Genotype.Write(file)
-					neat.InfoLog(fmt.Sprintf("Generation #%d winner dumped to: %s\n", epoch.Id, org_path))
-				}
+				ex.dumpSimplified(org, epoch)
 				break
 			}
 		}
-	} else {
-		// Move to the next epoch if failed to find winner
-		neat.DebugLog(">>>>> start next generation")
-		_, err = pop.Epoch(epoch.Id + 1, context)
 	}
 
 	return err
 }
 
-// This methods evaluates provided organism
-func (ex *XORGenerationEvaluator) org_evaluate(organism *genetics.Organism, context *neat.NeatContext) (bool, error) {
-	// The four possible input combinations to xor
-	// The first number is for biasing
+// Simplifies the genome of winner organism and dumps it to the file
+func (ex *XORGenerationEvaluator) dumpSimplified(org *genetics.Organism, epoch *experiments.Generation) {
+	simple, err := org.Genotype.Simplify(simplify_weight_tolerance, xorInputs(org.Phenotype), simplify_epsilon)
+	if err != nil {
+		neat.WarnLog(fmt.Sprintf("Failed to simplify winner genome, reason: %s\n", err))
+		return
+	}
+	simple_path := fmt.Sprintf("%s/%s_%d-%d", experiments.OutDirForTrial(ex.OutputPath, epoch.TrialId),
+		"xor_winner_simplified", len(simple.Nodes), simple.Extrons())
+	file, err := os.Create(simple_path)
+	if err != nil {
+		neat.ErrorLog(fmt.Sprintf("Failed to dump simplified winner genome, reason: %s\n", err))
+	} else {
+		simple.Write(file)
+		neat.InfoLog(fmt.Sprintf("Generation #%d simplified winner dumped to: %s\n", epoch.Id, simple_path))
+	}
+}
+
+// Returns the four possible input combinations to xor suitable for given network. The first number is for biasing,
+// it is skipped for the networks evolved with per-node bias which has no bias sensor.
+func xorInputs(net *network.Network) [][]float64 {
 	in := [][]float64{
 		{1.0, 0.0, 0.0},
 		{1.0, 0.0, 1.0},
 		{1.0, 1.0, 0.0},
 		{1.0, 1.0, 1.0}}
+	if len(net.Inputs) < len(in[0]) {
+		for i := range in {
+			in[i] = in[i][1:]
+		}
+	}
+	return in
+}
+
+// This methods evaluates provided organism
+func (ex *XORGenerationEvaluator) org_evaluate(organism *genetics.Organism, context *neat.NeatContext) (bool, error) {
+	// The four possible input combinations to xor
+	in := xorInputs(organism.Phenotype)
 
 	net_depth, err := organism.Phenotype.MaxDepth() // The max depth of the network to be activated
+	relax_iterations := net_depth + 2
 	if err != nil {
+		if relax_iterations < min_relaxation_iterations {
+			relax_iterations = min_relaxation_iterations
+		}
 		neat.WarnLog(
-			fmt.Sprintf("Failed to estimate maximal depth of the network with loop:\n%s\nUsing default dpeth: %d",
-				organism.Genotype, net_depth))
+			fmt.Sprintf("Failed to estimate maximal depth of the network with loop:\n%s\nUsing relaxation iterations: %d",
+				organism.Genotype, relax_iterations))
 	}
 	neat.DebugLog(fmt.Sprintf("Network depth: %d for organism: %d\n", net_depth, organism.Genotype.Id))
 	if net_depth == 0 {
@@ -125,28 +154,20 @@ func (ex *XORGenerationEvaluator) org_evaluate(organism *genetics.Organism, cont
 	success := false  // Check for successful activation
 	out := make([]float64, 4) // The four outputs
 
-	// Load and activate the network on each input
+	// Load and relax the network on each input, use depth to limit relaxation iterations of networks without loops
+	success = true
 	for count := 0; count < 4; count++ {
-		organism.Phenotype.LoadSensors(in[count])
-
-		// Relax net and get output
-		success, err = organism.Phenotype.Activate()
-		if err != nil {
+		outputs, err := organism.Phenotype.Relax(in[count], relax_iterations, relaxation_tolerance)
+		if err == network.ErrNetActivationAborted {
+			success = false
+			break
+		} else if err == network.ErrNetRelaxationNotConverged {
+			neat.DebugLog(fmt.Sprintf("Network of organism: %d not settled, using last outputs", organism.Genotype.Id))
+		} else if err != nil {
 			neat.ErrorLog("Failed to activate network")
 			return false, err
 		}
-
-		// use depth to ensure relaxation
-		for relax := 0; relax <= net_depth; relax++ {
-			success, err = organism.Phenotype.Activate()
-			if err != nil {
-				neat.ErrorLog("Failed to activate network")
-				return false, err
-			}
-		}
-		out[count] = organism.Phenotype.Outputs[0].Activation
-
-		organism.Phenotype.Flush()
+		out[count] = outputs[0]
 	}
 
 	if (success) {
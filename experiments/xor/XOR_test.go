@@ -1,6 +1,7 @@
 package xor
 
 import (
+	"bytes"
 	"testing"
 	"time"
 	"os"
@@ -186,4 +187,39 @@ func TestXOR_disconnected(t *testing.T) {
 	mean_diversity /= count
 	mean_age /= count
 	t.Logf("Mean best organisms: complexity=%.1f, diversity=%.1f, age=%.1f", mean_complexity, mean_diversity, mean_age)
-}
\ No newline at end of file
+}
+// Tests evaluation of network with loop, which output activated only after more ticks than its estimated depth
+func TestXORGenerationEvaluator_orgEvaluateLoop(t *testing.T) {
+	// the chain of hidden nodes from inputs to output with self-loop at the first hidden node, the nodes of chain placed
+	// in reverse order, thus signal moves one node further per activation tick
+	chain := 14
+	buf := bytes.NewBufferString("genomestart 1\ntrait 1 0.1 0 0 0 0 0 0 0\n")
+	buf.WriteString("node 1 0 1 3\nnode 2 0 1 1\nnode 3 0 1 1\nnode 4 0 0 2\n")
+	for i := 0; i < chain; i++ {
+		fmt.Fprintf(buf, "node %d 0 0 0\n", i + 5)
+	}
+	first := chain + 4
+	fmt.Fprintf(buf, "gene 1 2 %d 1.0 0 1 0 1\ngene 1 3 %d 1.0 0 2 0 1\ngene 1 %d %d 0.5 1 3 0 1\n", first, first,
+		first, first)
+	for i := first; i > 5; i-- {
+		fmt.Fprintf(buf, "gene 1 %d %d 1.0 0 %d 0 1\n", i, i - 1, first - i + 4)
+	}
+	fmt.Fprintf(buf, "gene 1 5 4 1.0 0 %d 0 1\ngenomeend 1\n", chain + 3)
+	gnome, err := genetics.ReadGenome(buf, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	org := genetics.NewOrganism(0.0, gnome, 1)
+	if depth, err := org.Phenotype.MaxDepth(); err == nil || depth + 2 > chain {
+		t.Fatal("The depth of network with loop should be underestimated", depth, err)
+	}
+
+	neat.LogLevel = neat.LogLevelError
+	ex := XORGenerationEvaluator{}
+	if _, err = ex.org_evaluate(org, &neat.NeatContext{}); err != nil {
+		t.Fatal(err)
+	}
+	if org.Error == 1.0 {
+		t.Error("The network with loop not activated")
+	}
+}
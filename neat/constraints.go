@@ -0,0 +1,67 @@
+package neat
+
+import (
+	"fmt"
+	"errors"
+	"strings"
+)
+
+// The structural constraints of genomes respected by mutations and crossover, e.g. to fit the evolved networks into
+// embedded targets with hard size limits. The zero value imposes no constraints.
+type Constraints struct {
+	// The maximal number of hidden nodes in genome, zero for no limit
+	MaxHiddenNodes  int
+	// The maximal number of enabled genes in genome, zero for no limit
+	MaxEnabledGenes int
+	// The maximal number of enabled links incoming into any node, zero for no limit
+	MaxFanIn        int
+	// If set, the recurrent links are not created and not inherited
+	FeedForwardOnly bool
+	// The pairs of neuron types of source and target nodes which are not allowed to be linked directly. The neuron
+	// types are the values of network.NeuronType, e.g. {1, 2} bans links from inputs to outputs.
+	BannedLinks     [][2]byte
+}
+
+// The names of neuron types used in configuration of banned links, in order of network.NeuronType values
+var neuronTypeNames = []string{"hidden", "input", "output", "bias"}
+
+// Returns true if the link from node of in_type to node of out_type is banned, where types are network.NeuronType values
+func (c *Constraints) IsLinkBanned(in_type, out_type byte) bool {
+	for _, pair := range c.BannedLinks {
+		if pair[0] == in_type && pair[1] == out_type {
+			return true
+		}
+	}
+	return false
+}
+
+// Bans the links from node of in_type to node of out_type, where types are network.NeuronType values
+func (c *Constraints) BanLinks(in_type, out_type byte) {
+	if !c.IsLinkBanned(in_type, out_type) {
+		c.BannedLinks = append(c.BannedLinks, [2]byte{in_type, out_type})
+	}
+}
+
+// Parses the configuration parameter banning links between neuron types which has the form ban_<in type>_<out type>,
+// e.g. ban_input_output or ban_hidden_hidden
+func parseBannedLink(name string) (in_type, out_type byte, err error) {
+	types := strings.Split(strings.TrimPrefix(name, "ban_"), "_")
+	if len(types) != 2 {
+		return 0, 0, errors.New(fmt.Sprintf("Wrong banned link parameter: %s", name))
+	}
+	found := 0
+	for i, type_name := range neuronTypeNames {
+		if types[0] == type_name {
+			in_type = byte(i)
+			found++
+		}
+		if types[1] == type_name {
+			out_type = byte(i)
+			found++
+		}
+	}
+	if found != 2 {
+		return 0, 0, errors.New(fmt.Sprintf("Unknown neuron types in banned link parameter: %s", name))
+	}
+	return in_type, out_type, nil
+}
@@ -0,0 +1,359 @@
+// Package esp provides the cooperative coevolution of neurons by Enforced Sub-Populations (ESP) method. Each hidden
+// unit of network is evolved in its own sub-population of neurons, while networks are assembled from one neuron of each
+// sub-population and the fitness of network is credited back to the neurons participated in it. The assembled
+// networks are expressed as NEAT genomes, thus they can be evaluated by the same harness as NEAT organisms.
+package esp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"errors"
+	"math/rand"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/network"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+// The parameters of Enforced Sub-Populations method
+type Options struct {
+	// The number of hidden neurons in network, i.e. the number of sub-populations
+	HiddenNeurons   int
+	// The number of neurons in each sub-population
+	SubPopSize      int
+	// The number of networks each neuron participates in per generation
+	TrialsPerNeuron int
+	// The probability of each weight of offspring neuron to be mutated
+	MutationRate    float64
+	// The scale of Cauchy distributed noise added to the mutated weights
+	MutationPower   float64
+	// The number of generations without improvement of the best fitness after which sub-populations are rebuilt
+	// around the neurons of the best network (burst mutation), zero to disable
+	BurstStagnation int
+	// If set, each hidden neuron has recurrent connections from all hidden neurons including itself
+	Recurrent       bool
+}
+
+// Checks that options are valid
+func (o Options) validate() error {
+	if o.HiddenNeurons <= 0 {
+		return errors.New(fmt.Sprintf("Wrong number of hidden neurons: %d", o.HiddenNeurons))
+	}
+	if o.SubPopSize < 4 {
+		return errors.New(fmt.Sprintf("Sub-population size should be at least 4, found: %d", o.SubPopSize))
+	}
+	if o.TrialsPerNeuron <= 0 {
+		return errors.New(fmt.Sprintf("Wrong number of trials per neuron: %d", o.TrialsPerNeuron))
+	}
+	if o.MutationRate < 0 || o.MutationRate > 1 || o.MutationPower < 0 || o.BurstStagnation < 0 {
+		return errors.New(fmt.Sprintf("Wrong mutation rate: %f, power: %f or burst stagnation: %d",
+			o.MutationRate, o.MutationPower, o.BurstStagnation))
+	}
+	return nil
+}
+
+// The chromosome of hidden neuron holding weights of its connections
+type Neuron struct {
+	// The weights of connections from sensors of network
+	InputWeights     []float64
+	// The weights of recurrent connections from hidden neurons, empty if network is not recurrent
+	RecurrentWeights []float64
+	// The weights of connections to outputs of network
+	OutputWeights    []float64
+	// The bias of neuron
+	Bias             float64
+	// The average fitness of networks this neuron participated in during the last evaluation
+	Fitness          float64
+
+	// The total fitness of networks neuron participated in
+	totalFitness     float64
+	// The number of networks neuron participated in
+	trials           int
+}
+
+// Creates new neuron with random weights in range [-1, 1]
+func newRandomNeuron(inputs, hidden, outputs int) *Neuron {
+	n := &Neuron{
+		InputWeights:make([]float64, inputs),
+		RecurrentWeights:make([]float64, hidden),
+		OutputWeights:make([]float64, outputs),
+		Bias:rand.Float64() * 2.0 - 1.0,
+	}
+	for _, weights := range n.chromosome() {
+		for i := range weights {
+			weights[i] = rand.Float64() * 2.0 - 1.0
+		}
+	}
+	return n
+}
+
+// Returns deep copy of this neuron without fitness
+func (n *Neuron) duplicate() *Neuron {
+	d := &Neuron{
+		InputWeights:make([]float64, len(n.InputWeights)),
+		RecurrentWeights:make([]float64, len(n.RecurrentWeights)),
+		OutputWeights:make([]float64, len(n.OutputWeights)),
+		Bias:n.Bias,
+	}
+	copy(d.InputWeights, n.InputWeights)
+	copy(d.RecurrentWeights, n.RecurrentWeights)
+	copy(d.OutputWeights, n.OutputWeights)
+	return d
+}
+
+// Returns the weight vectors of neuron in order of chromosome
+func (n *Neuron) chromosome() [][]float64 {
+	return [][]float64{n.InputWeights, n.RecurrentWeights, n.OutputWeights}
+}
+
+// Returns the pointer to the gene (weight or bias) of neuron with given index in chromosome
+func (n *Neuron) gene(i int) *float64 {
+	for _, weights := range n.chromosome() {
+		if i < len(weights) {
+			return &weights[i]
+		}
+		i -= len(weights)
+	}
+	return &n.Bias
+}
+
+// Returns the number of genes in chromosome of neuron
+func (n *Neuron) genes() int {
+	return len(n.InputWeights) + len(n.RecurrentWeights) + len(n.OutputWeights) + 1
+}
+
+// The sub-population of neurons competing to fill one hidden unit of network
+type SubPopulation struct {
+	// The neurons of sub-population
+	Neurons []*Neuron
+}
+
+// Sorts neurons by fitness, the most fit first
+func (s *SubPopulation) sort() {
+	sort.SliceStable(s.Neurons, func(i, j int) bool {
+		return s.Neurons[i].Fitness > s.Neurons[j].Fitness
+	})
+}
+
+// The Enforced Sub-Populations evolution of networks with one hidden layer
+type ESP struct {
+	// The options of evolution
+	Options        Options
+	// The sub-populations of neurons, one per hidden unit
+	SubPopulations []*SubPopulation
+	// The best fitness of network found so far
+	BestFitness    float64
+	// The copies of neurons of the best network found so far, nil if no network evaluated yet
+	Best           []*Neuron
+
+	// The sensor nodes of networks
+	inputs         []*network.NNode
+	// The output nodes of networks
+	outputs        []*network.NNode
+	// The traits of network genomes
+	traits         []*neat.Trait
+	// The neurons of networks assembled for the current generation in order of organisms
+	teams          [][]*Neuron
+	// The generation where the best fitness was improved the last time or burst mutation performed
+	lastImproved   int
+}
+
+// Creates new ESP evolution of networks having the sensors and outputs of the start genome with random neurons in
+// sub-populations. The hidden nodes and genes of the start genome are ignored.
+func New(start_genome *genetics.Genome, options Options) (*ESP, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	e := &ESP{
+		Options:options,
+		BestFitness:math.Inf(-1),
+		traits:start_genome.Traits,
+	}
+	for _, node := range start_genome.Nodes {
+		if node.IsSensor() {
+			e.inputs = append(e.inputs, node)
+		} else if node.NeuronType == network.OutputNeuron {
+			e.outputs = append(e.outputs, node)
+		}
+	}
+	if len(e.inputs) == 0 || len(e.outputs) == 0 {
+		return nil, errors.New("Start genome should have sensors and outputs")
+	}
+
+	recurrent := 0
+	if options.Recurrent {
+		recurrent = options.HiddenNeurons
+	}
+	e.SubPopulations = make([]*SubPopulation, options.HiddenNeurons)
+	for i := range e.SubPopulations {
+		e.SubPopulations[i] = &SubPopulation{Neurons:make([]*Neuron, options.SubPopSize)}
+		for j := range e.SubPopulations[i].Neurons {
+			e.SubPopulations[i].Neurons[j] = newRandomNeuron(len(e.inputs), recurrent, len(e.outputs))
+		}
+	}
+	return e, nil
+}
+
+// Returns the number of networks assembled per generation
+func (e *ESP) NetworksPerGeneration() int {
+	return e.Options.SubPopSize * e.Options.TrialsPerNeuron
+}
+
+// Assembles networks to be evaluated in the given generation. Each network is built from one neuron of each
+// sub-population, where neurons are combined randomly while each of them participates in TrialsPerNeuron networks.
+// The networks are returned as organisms with genome IDs starting from one.
+func (e *ESP) Assemble(generation int) []*genetics.Organism {
+	for _, sp := range e.SubPopulations {
+		for _, n := range sp.Neurons {
+			n.totalFitness, n.trials = 0.0, 0
+		}
+	}
+	e.teams = make([][]*Neuron, 0, e.NetworksPerGeneration())
+	organisms := make([]*genetics.Organism, 0, e.NetworksPerGeneration())
+	for trial := 0; trial < e.Options.TrialsPerNeuron; trial++ {
+		perms := make([][]int, len(e.SubPopulations))
+		for i := range perms {
+			perms[i] = rand.Perm(e.Options.SubPopSize)
+		}
+		for k := 0; k < e.Options.SubPopSize; k++ {
+			team := make([]*Neuron, len(e.SubPopulations))
+			for i, sp := range e.SubPopulations {
+				team[i] = sp.Neurons[perms[i][k]]
+			}
+			e.teams = append(e.teams, team)
+			genome := e.Genome(len(organisms) + 1, team)
+			organisms = append(organisms, genetics.NewOrganism(0.0, genome, generation))
+		}
+	}
+	return organisms
+}
+
+// Builds the genome of network with one hidden layer made of given neurons
+func (e *ESP) Genome(id int, neurons []*Neuron) *genetics.Genome {
+	nodes := make([]*network.NNode, 0, len(e.inputs) + len(e.outputs) + len(neurons))
+	max_id := 0
+	for _, list := range [][]*network.NNode{e.inputs, e.outputs} {
+		for _, node := range list {
+			nodes = append(nodes, network.NewNNodeCopy(node, node.Trait))
+			if node.Id > max_id {
+				max_id = node.Id
+			}
+		}
+	}
+	inputs, outputs := nodes[:len(e.inputs)], nodes[len(e.inputs):]
+	hidden := make([]*network.NNode, len(neurons))
+	for i, n := range neurons {
+		hidden[i] = network.NewNNode(max_id + i + 1, network.HiddenNeuron)
+		hidden[i].Bias = n.Bias
+		nodes = append(nodes, hidden[i])
+	}
+
+	genes := make([]*genetics.Gene, 0)
+	innovation := int64(1)
+	add_gene := func(weight float64, in_node, out_node *network.NNode, recurrent bool) {
+		genes = append(genes, genetics.NewGene(weight, in_node, out_node, recurrent, innovation, 0.0))
+		innovation++
+	}
+	for i, n := range neurons {
+		for j, w := range n.InputWeights {
+			add_gene(w, inputs[j], hidden[i], false)
+		}
+		for j, w := range n.RecurrentWeights {
+			add_gene(w, hidden[j], hidden[i], true)
+		}
+		for j, w := range n.OutputWeights {
+			add_gene(w, hidden[i], outputs[j], false)
+		}
+	}
+	return genetics.NewGenome(id, e.traits, nodes, genes)
+}
+
+// Credits the fitness of evaluated organisms returned by the last Assemble to the neurons participated in them. The
+// fitness of neuron is the average fitness of its networks.
+func (e *ESP) Credit(organisms []*genetics.Organism) {
+	for i, team := range e.teams {
+		org := organisms[i]
+		for _, n := range team {
+			n.totalFitness += org.Fitness
+			n.trials++
+		}
+		if org.Fitness > e.BestFitness {
+			e.BestFitness = org.Fitness
+			e.Best = make([]*Neuron, len(team))
+			for j, n := range team {
+				e.Best[j] = n.duplicate()
+			}
+			e.lastImproved = org.Generation
+		}
+	}
+	for _, sp := range e.SubPopulations {
+		for _, n := range sp.Neurons {
+			if n.trials > 0 {
+				n.Fitness = n.totalFitness / float64(n.trials)
+			}
+		}
+	}
+}
+
+// Produces the next generation of neurons in each sub-population. The neurons of the top quarter are mated with
+// one-point crossover to a random neuron ranked higher (the best neuron with itself) and the offspring replaces the
+// worst half of sub-population. The offspring weights are mutated by Cauchy distributed noise. If the best fitness
+// has not improved for BurstStagnation generations, sub-populations are rebuilt around neurons of the best network.
+func (e *ESP) Epoch(generation int) {
+	if e.Options.BurstStagnation > 0 && e.Best != nil && generation - e.lastImproved > e.Options.BurstStagnation {
+		neat.InfoLog(fmt.Sprintf("ESP: burst mutation in generation %d, best fitness: %f", generation, e.BestFitness))
+		e.burstMutation()
+		e.lastImproved = generation
+		return
+	}
+
+	for _, sp := range e.SubPopulations {
+		sp.sort()
+		quarter := len(sp.Neurons) / 4
+		replaced := len(sp.Neurons) - 2 * quarter
+		for i := 0; i < quarter; i++ {
+			mate := 0
+			if i > 0 {
+				mate = rand.Intn(i)
+			}
+			first, second := crossover(sp.Neurons[i], sp.Neurons[mate])
+			sp.Neurons[replaced + 2 * i], sp.Neurons[replaced + 2 * i + 1] = first, second
+		}
+		for _, n := range sp.Neurons[replaced:] {
+			e.mutate(n, e.Options.MutationRate)
+		}
+	}
+}
+
+// Rebuilds each sub-population from copies of the corresponding neuron of the best network with all genes mutated,
+// the first copy is kept intact
+func (e *ESP) burstMutation() {
+	for i, sp := range e.SubPopulations {
+		for j := range sp.Neurons {
+			sp.Neurons[j] = e.Best[i].duplicate()
+			if j > 0 {
+				e.mutate(sp.Neurons[j], 1.0)
+			}
+		}
+	}
+}
+
+// Adds Cauchy distributed noise scaled by MutationPower to the genes of neuron, each gene is mutated with given
+// probability
+func (e *ESP) mutate(n *Neuron, rate float64) {
+	for i := 0; i < n.genes(); i++ {
+		if rand.Float64() < rate {
+			*n.gene(i) += e.Options.MutationPower * math.Tan(math.Pi * (rand.Float64() - 0.5))
+		}
+	}
+}
+
+// Produces two offspring by one-point crossover of parents chromosomes
+func crossover(mom, dad *Neuron) (*Neuron, *Neuron) {
+	first, second := mom.duplicate(), dad.duplicate()
+	point := rand.Intn(first.genes())
+	for i := point; i < first.genes(); i++ {
+		*first.gene(i), *second.gene(i) = *dad.gene(i), *mom.gene(i)
+	}
+	return first, second
+}
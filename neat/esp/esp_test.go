@@ -0,0 +1,171 @@
+package esp
+
+import (
+	"testing"
+	"strings"
+	"math/rand"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/network"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+func buildTestStartGenome() *genetics.Genome {
+	traits := []*neat.Trait{
+		neat.ReadTrait(strings.NewReader("1 0.1 0 0 0 0 0 0 0")),
+	}
+	nodes := []*network.NNode{
+		network.ReadNNode(strings.NewReader("1 1 1 1"), traits),
+		network.ReadNNode(strings.NewReader("2 1 1 1"), traits),
+		network.ReadNNode(strings.NewReader("3 1 1 3"), traits),
+		network.ReadNNode(strings.NewReader("4 1 0 2"), traits),
+	}
+	genes := []*genetics.Gene{
+		genetics.ReadGene(strings.NewReader("1 1 4 0.0 false 1 0 true"), traits, nodes),
+		genetics.ReadGene(strings.NewReader("1 2 4 0.0 false 2 0 true"), traits, nodes),
+		genetics.ReadGene(strings.NewReader("1 3 4 0.0 false 3 0 true"), traits, nodes),
+	}
+	return genetics.NewGenome(1, traits, nodes, genes)
+}
+
+func testOptions() Options {
+	return Options{
+		HiddenNeurons:3,
+		SubPopSize:8,
+		TrialsPerNeuron:5,
+		MutationRate:0.5,
+		MutationPower:0.3,
+		BurstStagnation:2,
+		Recurrent:true,
+	}
+}
+
+func TestNew(t *testing.T) {
+	e, err := New(buildTestStartGenome(), testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.SubPopulations) != 3 || len(e.inputs) != 3 || len(e.outputs) != 1 {
+		t.Error("Wrong ESP", len(e.SubPopulations), len(e.inputs), len(e.outputs))
+	}
+	n := e.SubPopulations[2].Neurons[7]
+	if len(n.InputWeights) != 3 || len(n.RecurrentWeights) != 3 || len(n.OutputWeights) != 1 || n.genes() != 8 {
+		t.Error("Wrong neuron", n)
+	}
+
+	invalid := []func(o *Options){
+		func(o *Options) {o.HiddenNeurons = 0},
+		func(o *Options) {o.SubPopSize = 3},
+		func(o *Options) {o.TrialsPerNeuron = 0},
+		func(o *Options) {o.MutationRate = 1.5},
+	}
+	for i, configure := range invalid {
+		options := testOptions()
+		configure(&options)
+		if _, err = New(buildTestStartGenome(), options); err == nil {
+			t.Error("Invalid options accepted", i)
+		}
+	}
+}
+
+func TestESP_Assemble(t *testing.T) {
+	rand.Seed(42)
+	e, err := New(buildTestStartGenome(), testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	organisms := e.Assemble(3)
+	if len(organisms) != e.NetworksPerGeneration() || len(organisms) != 40 {
+		t.Fatal("Wrong number of networks", len(organisms))
+	}
+
+	// 3 sensors + 1 output + 3 hidden nodes, each hidden has 3 input, 3 recurrent and 1 output links
+	org := organisms[0]
+	if len(org.Genotype.Nodes) != 7 || len(org.Genotype.Genes) != 21 || org.Generation != 3 {
+		t.Error("Wrong network genome", len(org.Genotype.Nodes), len(org.Genotype.Genes), org.Generation)
+	}
+	org.Phenotype.LoadSensors([]float64{0.5, 0.5, 1.0})
+	if res, err := org.Phenotype.Activate(); !res {
+		t.Error("Failed to activate network", err)
+	}
+
+	// each neuron participates in TrialsPerNeuron networks and gets the average fitness of them
+	for i, org := range organisms {
+		org.Fitness = float64(i)
+	}
+	e.Credit(organisms)
+	for _, sp := range e.SubPopulations {
+		for _, n := range sp.Neurons {
+			if n.trials != 5 {
+				t.Error("Wrong number of neuron trials", n.trials)
+			}
+		}
+	}
+	if e.BestFitness != 39.0 || len(e.Best) != 3 || e.lastImproved != 3 {
+		t.Error("Wrong best network", e.BestFitness, len(e.Best), e.lastImproved)
+	}
+	total := 0.0
+	for _, n := range e.SubPopulations[0].Neurons {
+		total += n.Fitness
+	}
+	if total / 8.0 != 19.5 {
+		t.Error("Wrong average fitness of neurons", total / 8.0)
+	}
+}
+
+func TestESP_Epoch(t *testing.T) {
+	rand.Seed(42)
+	e, err := New(buildTestStartGenome(), testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	organisms := e.Assemble(0)
+	for i, org := range organisms {
+		org.Fitness = float64(i % 7)
+	}
+	e.Credit(organisms)
+	best := make([]*Neuron, len(e.SubPopulations))
+	for i, sp := range e.SubPopulations {
+		sp.sort()
+		best[i] = sp.Neurons[0]
+	}
+
+	// the best half of each sub-population survives
+	e.Epoch(1)
+	for i, sp := range e.SubPopulations {
+		if len(sp.Neurons) != 8 || sp.Neurons[0] != best[i] {
+			t.Error("The best neuron should survive", i)
+		}
+	}
+
+	// the burst mutation rebuilds sub-populations around the best network
+	e.Epoch(4)
+	if e.lastImproved != 4 {
+		t.Error("Burst mutation should reset stagnation", e.lastImproved)
+	}
+	for i, sp := range e.SubPopulations {
+		n := sp.Neurons[0]
+		if n == e.Best[i] || n.Bias != e.Best[i].Bias || n.InputWeights[0] != e.Best[i].InputWeights[0] {
+			t.Error("The copy of the best neuron should be kept", i)
+		}
+		if sp.Neurons[1].InputWeights[0] == e.Best[i].InputWeights[0] {
+			t.Error("The copy of the best neuron should be mutated", i)
+		}
+	}
+}
+
+func TestCrossover(t *testing.T) {
+	mom := &Neuron{InputWeights:[]float64{1, 1}, OutputWeights:[]float64{1}, Bias:1}
+	dad := &Neuron{InputWeights:[]float64{2, 2}, OutputWeights:[]float64{2}, Bias:2}
+	first, second := crossover(mom, dad)
+	for i := 0; i < first.genes(); i++ {
+		if *first.gene(i) + *second.gene(i) != 3 {
+			t.Error("Genes are lost by crossover", i)
+		}
+		if i > 0 && *first.gene(i) < *first.gene(i - 1) {
+			t.Error("Not one point crossover", first)
+		}
+	}
+	if mom.Bias != 1 || dad.InputWeights[0] != 2 {
+		t.Error("Parents should not be changed")
+	}
+}
@@ -0,0 +1,361 @@
+package genetics
+
+import (
+	"io"
+	"fmt"
+	"sort"
+	"bytes"
+	"strings"
+	"errors"
+)
+
+// The reproduction operator which produced an organism
+type ReproductionOperator byte
+
+// The reproduction operators
+const (
+	// The operator was not applied
+	NoOperator ReproductionOperator = iota
+	// The organism of the initial population
+	InitialOperator
+	// The exact copy of the species or population champion
+	CloneOperator
+	// The link weights mutation applied to the population champion offspring
+	MutateLinkWeightsOperator
+	// The add node structural mutation
+	MutateAddNodeOperator
+	// The add link structural mutation
+	MutateAddLinkOperator
+	// The connect sensors structural mutation
+	MutateConnectSensorsOperator
+	// All the non-structural mutations (weights, traits, toggle enable, etc.)
+	MutateNonstructuralOperator
+	// The multipoint crossover
+	MateMultipointOperator
+	// The multipoint crossover with averaging of matching genes
+	MateMultipointAvgOperator
+	// The single point crossover
+	MateSinglepointOperator
+	// The copy of organism migrated from other population
+	MigrationOperator
+)
+
+// Returns the name of the reproduction operator
+func ReproductionOperatorName(op ReproductionOperator) string {
+	switch op {
+	case NoOperator:
+		return "none"
+	case InitialOperator:
+		return "initial"
+	case CloneOperator:
+		return "clone"
+	case MutateLinkWeightsOperator:
+		return "mutate_link_weights"
+	case MutateAddNodeOperator:
+		return "mutate_add_node"
+	case MutateAddLinkOperator:
+		return "mutate_add_link"
+	case MutateConnectSensorsOperator:
+		return "mutate_connect_sensors"
+	case MutateNonstructuralOperator:
+		return "mutate_nonstructural"
+	case MateMultipointOperator:
+		return "mate_multipoint"
+	case MateMultipointAvgOperator:
+		return "mate_multipoint_avg"
+	case MateSinglepointOperator:
+		return "mate_singlepoint"
+	case MigrationOperator:
+		return "migration"
+	default:
+		return "unknown"
+	}
+}
+
+// Returns true if operator is the crossover
+func (op ReproductionOperator) IsCrossover() bool {
+	return op == MateMultipointOperator || op == MateMultipointAvgOperator || op == MateSinglepointOperator
+}
+
+// Returns true if operator is the structural mutation
+func (op ReproductionOperator) IsStructural() bool {
+	return op == MutateAddNodeOperator || op == MutateAddLinkOperator || op == MutateConnectSensorsOperator
+}
+
+// The origin of the organism: how and from which parents it was born
+type Ancestry struct {
+	// The generation (epoch) when organism was born and first evaluated, zero for the initial population
+	BirthGeneration int
+	// The genome IDs of parents in the previous generation, the mother comes first. Empty for the initial population.
+	ParentIds       []int
+	// The original fitness values of parents in the same order as IDs
+	ParentFitness   []float64
+	// The ID of the mother's species
+	ParentSpeciesId int
+
+	// The crossover operator applied or NoOperator if organism was produced without mating
+	Crossover       ReproductionOperator
+	// The mutation operator applied or NoOperator if crossover offspring was not mutated. For the champion copies it
+	// holds CloneOperator and for the initial population - InitialOperator.
+	Mutation        ReproductionOperator
+	// The flag to indicate that parents were selected from different species
+	Interspecies    bool
+}
+
+// Creates ancestry of the organism in the initial population
+func newInitialAncestry() *Ancestry {
+	return &Ancestry{
+		ParentIds:make([]int, 0),
+		ParentFitness:make([]float64, 0),
+		Mutation:InitialOperator,
+	}
+}
+
+// Creates ancestry of the offspring born in given generation from provided parents (mother first)
+func newAncestry(generation int, crossover, mutation ReproductionOperator, parents ...*Organism) *Ancestry {
+	a := Ancestry{
+		BirthGeneration:generation,
+		ParentIds:make([]int, len(parents)),
+		ParentFitness:make([]float64, len(parents)),
+		Crossover:crossover,
+		Mutation:mutation,
+	}
+	for i, p := range parents {
+		a.ParentIds[i] = p.Genotype.Id
+		a.ParentFitness[i] = p.OriginalFitness
+	}
+	if len(parents) > 0 && parents[0].Species != nil {
+		a.ParentSpeciesId = parents[0].Species.Id
+		if len(parents) > 1 && parents[1].Species != nil && parents[1].Species != parents[0].Species {
+			a.Interspecies = true
+		}
+	}
+	return &a
+}
+
+// Returns the generation of parents
+func (a *Ancestry) ParentsGeneration() int {
+	return a.BirthGeneration - 1
+}
+
+// Returns the name of the reproduction operators applied joined by '+', e.g. "mate_multipoint+mutate_add_node"
+func (a *Ancestry) Operator() string {
+	names := make([]string, 0, 2)
+	if a.Crossover != NoOperator {
+		name := ReproductionOperatorName(a.Crossover)
+		if a.Interspecies {
+			name += "_interspecies"
+		}
+		names = append(names, name)
+	}
+	if a.Mutation != NoOperator {
+		names = append(names, ReproductionOperatorName(a.Mutation))
+	}
+	if len(names) == 0 {
+		return ReproductionOperatorName(NoOperator)
+	}
+	return strings.Join(names, "+")
+}
+
+// Returns the best fitness among the parents or zero if there are no parents
+func (a *Ancestry) BestParentFitness() float64 {
+	best := 0.0
+	for i, f := range a.ParentFitness {
+		if i == 0 || f > best {
+			best = f
+		}
+	}
+	return best
+}
+
+// Stringer
+func (a *Ancestry) String() string {
+	return fmt.Sprintf("born: %d, parents: %v, operator: %s", a.BirthGeneration, a.ParentIds, a.Operator())
+}
+
+// The record about evaluated organism stored in the genealogy
+type GenealogyRecord struct {
+	// The generation where organism was evaluated
+	Generation int
+	// The ID of the organism's genome
+	GenomeId   int
+	// The ID of the organism's species
+	SpeciesId  int
+	// The fitness of the organism
+	Fitness    float64
+	// The flag to indicate whether organism is the winner
+	IsWinner   bool
+	// The origin of the organism
+	Ancestry   Ancestry
+}
+
+// The key to find record in genealogy. The genome IDs are renumbered every epoch, thus the generation is required to
+// distinguish organisms.
+type genealogyKey struct {
+	generation, genomeId int
+}
+
+// The genealogy holds records about all evaluated organisms of the population with references to their parents.
+// It can be used to trace the lineage of the winner and to estimate how often each reproduction operator produced an
+// improvement over the parents.
+type Genealogy struct {
+	// The records of evaluated organisms in order of recording
+	Records []*GenealogyRecord
+
+	// The index of records
+	index   map[genealogyKey]*GenealogyRecord
+}
+
+// Creates new empty genealogy
+func NewGenealogy() *Genealogy {
+	return &Genealogy{
+		Records:make([]*GenealogyRecord, 0),
+		index:make(map[genealogyKey]*GenealogyRecord),
+	}
+}
+
+// Records the evaluated organisms of the given generation. The organisms without ancestry recorded as initial ones.
+func (g *Genealogy) RecordGeneration(organisms []*Organism, generation int) {
+	for _, o := range organisms {
+		rec := &GenealogyRecord{
+			Generation:generation,
+			GenomeId:o.Genotype.Id,
+			Fitness:o.Fitness,
+			IsWinner:o.IsWinner,
+		}
+		if o.Species != nil {
+			rec.SpeciesId = o.Species.Id
+		}
+		if o.Ancestry != nil {
+			rec.Ancestry = *o.Ancestry
+		} else {
+			rec.Ancestry = *newInitialAncestry()
+			rec.Ancestry.BirthGeneration = generation
+		}
+		g.Records = append(g.Records, rec)
+		g.index[genealogyKey{generation:generation, genomeId:rec.GenomeId}] = rec
+	}
+}
+
+// Finds record of organism with given genome ID evaluated at given generation. Returns nil if not found.
+func (g *Genealogy) Find(generation, genomeId int) *GenealogyRecord {
+	return g.index[genealogyKey{generation:generation, genomeId:genomeId}]
+}
+
+// Returns the parent records of the given one
+func (g *Genealogy) Parents(rec *GenealogyRecord) []*GenealogyRecord {
+	parents := make([]*GenealogyRecord, 0, len(rec.Ancestry.ParentIds))
+	for _, id := range rec.Ancestry.ParentIds {
+		if p := g.Find(rec.Ancestry.ParentsGeneration(), id); p != nil {
+			parents = append(parents, p)
+		}
+	}
+	return parents
+}
+
+// Returns the line of descent of the organism following its mothers back to the initial population. The first
+// element is the oldest ancestor and the last one is the organism itself. Returns nil if organism not found.
+func (g *Genealogy) Lineage(generation, genomeId int) []*GenealogyRecord {
+	rec := g.Find(generation, genomeId)
+	if rec == nil {
+		return nil
+	}
+	lineage := []*GenealogyRecord{rec}
+	for len(rec.Ancestry.ParentIds) > 0 {
+		rec = g.Find(rec.Ancestry.ParentsGeneration(), rec.Ancestry.ParentIds[0])
+		if rec == nil {
+			break
+		}
+		lineage = append(lineage, rec)
+	}
+	// reverse to start from the oldest
+	for i, j := 0, len(lineage) - 1; i < j; i, j = i + 1, j - 1 {
+		lineage[i], lineage[j] = lineage[j], lineage[i]
+	}
+	return lineage
+}
+
+// Returns all the ancestors of the organism (through both parents) including the organism itself ordered by
+// generation. Returns nil if organism not found.
+func (g *Genealogy) Ancestors(generation, genomeId int) []*GenealogyRecord {
+	rec := g.Find(generation, genomeId)
+	if rec == nil {
+		return nil
+	}
+	visited := map[*GenealogyRecord]bool{rec:true}
+	ancestors := []*GenealogyRecord{rec}
+	for i := 0; i < len(ancestors); i++ {
+		for _, p := range g.Parents(ancestors[i]) {
+			if !visited[p] {
+				visited[p] = true
+				ancestors = append(ancestors, p)
+			}
+		}
+	}
+	sort.SliceStable(ancestors, func(i, j int) bool {
+		if ancestors[i].Generation == ancestors[j].Generation {
+			return ancestors[i].GenomeId < ancestors[j].GenomeId
+		}
+		return ancestors[i].Generation < ancestors[j].Generation
+	})
+	return ancestors
+}
+
+// Writes the whole genealogy as a graph in the GraphViz DOT format
+func (g *Genealogy) WriteDOT(w io.Writer) error {
+	return writeGenealogyDOT(w, g.Records)
+}
+
+// Writes the ancestry graph of given organism in the GraphViz DOT format
+func (g *Genealogy) WriteAncestorsDOT(w io.Writer, generation, genomeId int) error {
+	ancestors := g.Ancestors(generation, genomeId)
+	if ancestors == nil {
+		return errors.New(fmt.Sprintf("Organism not found in genealogy, generation: %d, genome: %d",
+			generation, genomeId))
+	}
+	return writeGenealogyDOT(w, ancestors)
+}
+
+// Writes records as graph in the GraphViz DOT format. The nodes are organisms labeled with genome ID, generation and
+// fitness, while edges go from parents to offspring labeled by reproduction operator. The edges to the parents not
+// present among records are omitted.
+func writeGenealogyDOT(w io.Writer, records []*GenealogyRecord) error {
+	present := make(map[genealogyKey]bool, len(records))
+	for _, rec := range records {
+		present[genealogyKey{generation:rec.Generation, genomeId:rec.GenomeId}] = true
+	}
+
+	b := bytes.NewBufferString("digraph genealogy {\n")
+	fmt.Fprintln(b, "\trankdir=TB;")
+	fmt.Fprintln(b, "\tnode [shape=box];")
+	for _, rec := range records {
+		attrs := ""
+		if rec.IsWinner {
+			attrs = ", style=filled, fillcolor=gold"
+		}
+		fmt.Fprintf(b, "\t%s [label=\"#%d gen %d\\nspecies %d\\nfitness %.4g\"%s];\n",
+			genealogyNodeName(rec.Generation, rec.GenomeId), rec.GenomeId, rec.Generation, rec.SpeciesId,
+			rec.Fitness, attrs)
+	}
+	for _, rec := range records {
+		for i, id := range rec.Ancestry.ParentIds {
+			if !present[genealogyKey{generation:rec.Ancestry.ParentsGeneration(), genomeId:id}] {
+				continue
+			}
+			attrs := fmt.Sprintf("label=\"%s\"", rec.Ancestry.Operator())
+			if i > 0 {
+				// the father
+				attrs = "style=dashed"
+			}
+			fmt.Fprintf(b, "\t%s -> %s [%s];\n", genealogyNodeName(rec.Ancestry.ParentsGeneration(), id),
+				genealogyNodeName(rec.Generation, rec.GenomeId), attrs)
+		}
+	}
+	fmt.Fprintln(b, "}")
+	_, err := b.WriteTo(w)
+	return err
+}
+
+func genealogyNodeName(generation, genomeId int) string {
+	return fmt.Sprintf("g%d_%d", generation, genomeId)
+}
@@ -0,0 +1,153 @@
+package genetics
+
+import (
+	"testing"
+	"math/rand"
+	"bytes"
+	"strings"
+	"github.com/yaricom/goNEAT/neat"
+)
+
+func TestAncestry_Operator(t *testing.T) {
+	a := Ancestry{Crossover:MateMultipointOperator, Mutation:MutateAddNodeOperator, Interspecies:true}
+	if op := a.Operator(); op != "mate_multipoint_interspecies+mutate_add_node" {
+		t.Error("Wrong operator name", op)
+	}
+	a = Ancestry{Mutation:CloneOperator}
+	if op := a.Operator(); op != "clone" {
+		t.Error("Wrong operator name", op)
+	}
+	a = Ancestry{Crossover:MateSinglepointOperator}
+	if op := a.Operator(); op != "mate_singlepoint" {
+		t.Error("Wrong operator name", op)
+	}
+	if !MutateConnectSensorsOperator.IsStructural() || MutateNonstructuralOperator.IsStructural() {
+		t.Error("Wrong structural operators")
+	}
+}
+
+func TestPopulation_EpochGenealogy(t *testing.T) {
+	rand.Seed(42)
+	conf := neat.NeatContext{
+		CompatThreshold:0.5,
+		DropOffAge:1,
+		PopSize: 30,
+		MutateOnlyProb:0.25,
+		MutateAddNodeProb:0.1,
+		MutateAddLinkProb:0.1,
+		MateMultipointProb:0.6,
+		MateMultipointAvgProb:0.4,
+		InterspeciesMateRate:0.1,
+		RecurOnlyProb:0.2,
+		NewLinkTries:20,
+		WeightMutPower:1.0,
+	}
+	gen := NewGenomeRand(1, 3, 2, 3, 15, false, 0.8)
+	pop, err := NewPopulation(gen, &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range pop.Organisms {
+		if o.Ancestry == nil || o.Ancestry.Mutation != InitialOperator || len(o.Ancestry.ParentIds) != 0 {
+			t.Fatal("Wrong ancestry of initial organism", o.Ancestry)
+		}
+	}
+
+	pop.Genealogy = NewGenealogy()
+	generations := 5
+	for i := 1; i <= generations; i++ {
+		for _, o := range pop.Organisms {
+			o.Fitness = rand.Float64()
+		}
+		if _, err = pop.Epoch(i, &conf); err != nil {
+			t.Fatal(err)
+		}
+		for _, o := range pop.Organisms {
+			if o.Ancestry == nil {
+				t.Fatal("Offspring without ancestry")
+			}
+			if o.Ancestry.BirthGeneration != i {
+				t.Error("Wrong birth generation", o.Ancestry.BirthGeneration, i)
+			}
+			if o.Ancestry.Crossover == NoOperator && o.Ancestry.Mutation == NoOperator {
+				t.Error("No reproduction operator recorded", o.Ancestry)
+			}
+			if o.Ancestry.Crossover != NoOperator && len(o.Ancestry.ParentIds) != 2 {
+				t.Error("Wrong number of crossover parents", o.Ancestry)
+			}
+			// all parents should be found among recorded previous generation
+			for _, id := range o.Ancestry.ParentIds {
+				if pop.Genealogy.Find(i - 1, id) == nil {
+					t.Error("Parent not found in genealogy", i - 1, id)
+				}
+			}
+		}
+	}
+	if len(pop.Genealogy.Records) != generations * conf.PopSize {
+		t.Error("Wrong number of genealogy records", len(pop.Genealogy.Records))
+	}
+
+	// the lineage of any organism in the last recorded generation should start at initial population
+	last := pop.Genealogy.Records[len(pop.Genealogy.Records) - 1]
+	lineage := pop.Genealogy.Lineage(last.Generation, last.GenomeId)
+	if len(lineage) != generations {
+		t.Error("Wrong lineage length", len(lineage))
+	}
+	if lineage[0].Generation != 0 || lineage[0].Ancestry.Mutation != InitialOperator {
+		t.Error("Lineage should start at initial population", lineage[0])
+	}
+	if lineage[len(lineage) - 1] != last {
+		t.Error("Lineage should end with organism itself")
+	}
+}
+
+func TestGenealogy_WriteDOT(t *testing.T) {
+	gl := NewGenealogy()
+	mom := NewOrganism(0.5, buildTestGenome(1), 1)
+	dad := NewOrganism(0.6, buildTestGenome(2), 1)
+	other := NewOrganism(0.1, buildTestGenome(3), 1)
+	gl.RecordGeneration([]*Organism{mom, dad, other}, 0)
+
+	mom.OriginalFitness, dad.OriginalFitness = mom.Fitness, dad.Fitness
+	baby := NewOrganism(0.9, buildTestGenome(1), 1)
+	baby.IsWinner = true
+	baby.Ancestry = newAncestry(1, MateMultipointOperator, NoOperator, mom, dad)
+	gl.RecordGeneration([]*Organism{baby}, 1)
+
+	if rec := gl.Find(1, 1); rec == nil || rec.Ancestry.BestParentFitness() != 0.6 {
+		t.Error("Wrong record of baby", rec)
+	}
+	ancestors := gl.Ancestors(1, 1)
+	if len(ancestors) != 3 {
+		t.Error("Wrong number of ancestors", len(ancestors))
+	}
+
+	b := bytes.NewBufferString("")
+	if err := gl.WriteDOT(b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if !strings.HasPrefix(out, "digraph genealogy {") {
+		t.Error("Wrong DOT header", out)
+	}
+	if !strings.Contains(out, "g0_1 -> g1_1 [label=\"mate_multipoint\"];") {
+		t.Error("Mother edge not found", out)
+	}
+	if !strings.Contains(out, "g0_2 -> g1_1 [style=dashed];") {
+		t.Error("Father edge not found", out)
+	}
+	if !strings.Contains(out, "fillcolor=gold") {
+		t.Error("Winner not highlighted", out)
+	}
+
+	b.Reset()
+	if err := gl.WriteAncestorsDOT(b, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "g0_3 ") {
+		t.Error("Unrelated organism in ancestry graph", b.String())
+	}
+	if err := gl.WriteAncestorsDOT(b, 5, 1); err == nil {
+		t.Error("Error expected for unknown organism")
+	}
+}
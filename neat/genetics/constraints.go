@@ -0,0 +1,123 @@
+package genetics
+
+import (
+	"errors"
+	"fmt"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/network"
+)
+
+// Returns the number of hidden nodes among given nodes
+func countHiddenNodes(nodes []*network.NNode) int {
+	count := 0
+	for _, n := range nodes {
+		if n.NeuronType == network.HiddenNeuron {
+			count++
+		}
+	}
+	return count
+}
+
+// Returns the number of enabled genes among given genes
+func countEnabledGenes(genes []*Gene) int {
+	count := 0
+	for _, gene := range genes {
+		if gene.IsEnabled {
+			count++
+		}
+	}
+	return count
+}
+
+// Returns the number of enabled genes linking into the node with given ID
+func countFanIn(genes []*Gene, node_id int) int {
+	count := 0
+	for _, gene := range genes {
+		if gene.IsEnabled && gene.Link.OutNode.Id == node_id {
+			count++
+		}
+	}
+	return count
+}
+
+// Checks whether the link between given nodes can be added to the genome having given genes without violating the
+// constraints. The limits of enabled genes and fan-in are only checked if link to be added is enabled.
+func linkAllowed(c *neat.Constraints, in_node, out_node *network.NNode, recurrent, enabled bool, genes []*Gene) bool {
+	if recurrent && c.FeedForwardOnly {
+		return false
+	}
+	if c.IsLinkBanned(byte(in_node.NeuronType), byte(out_node.NeuronType)) {
+		return false
+	}
+	if !enabled {
+		return true
+	}
+	if c.MaxEnabledGenes > 0 && countEnabledGenes(genes) >= c.MaxEnabledGenes {
+		return false
+	}
+	if c.MaxFanIn > 0 && countFanIn(genes, out_node.Id) >= c.MaxFanIn {
+		return false
+	}
+	return true
+}
+
+// Checks whether the gene can be inherited by offspring assembled from given genes and nodes so far without violating
+// the constraints. The enabled flag tells whether gene will be enabled in offspring.
+func inheritanceAllowed(c *neat.Constraints, gene *Gene, enabled bool, genes []*Gene, nodes []*network.NNode) bool {
+	if !linkAllowed(c, gene.Link.InNode, gene.Link.OutNode, gene.Link.IsRecurrent, enabled, genes) {
+		return false
+	}
+	if c.MaxHiddenNodes > 0 {
+		// count hidden nodes brought by gene into offspring
+		added := 0
+		for i, n := range []*network.NNode{gene.Link.InNode, gene.Link.OutNode} {
+			if n.NeuronType != network.HiddenNeuron || (i == 1 && n.Id == gene.Link.InNode.Id) {
+				continue
+			}
+			found := false
+			for _, node := range nodes {
+				if node.Id == n.Id {
+					found = true
+					break
+				}
+			}
+			if !found {
+				added++
+			}
+		}
+		if added > 0 && countHiddenNodes(nodes) + added > c.MaxHiddenNodes {
+			return false
+		}
+	}
+	return true
+}
+
+// Checks that genome does not violate given constraints, e.g. the start genome of population. Returns error describing
+// the first violation found or nil.
+func (g *Genome) checkConstraints(c *neat.Constraints) error {
+	if hidden := countHiddenNodes(g.Nodes); c.MaxHiddenNodes > 0 && hidden > c.MaxHiddenNodes {
+		return errors.New(fmt.Sprintf("Genome [%d] has %d hidden nodes, maximum allowed: %d",
+			g.Id, hidden, c.MaxHiddenNodes))
+	}
+	if enabled := countEnabledGenes(g.Genes); c.MaxEnabledGenes > 0 && enabled > c.MaxEnabledGenes {
+		return errors.New(fmt.Sprintf("Genome [%d] has %d enabled genes, maximum allowed: %d",
+			g.Id, enabled, c.MaxEnabledGenes))
+	}
+	for _, gene := range g.Genes {
+		in_node, out_node := gene.Link.InNode, gene.Link.OutNode
+		if gene.Link.IsRecurrent && c.FeedForwardOnly {
+			return errors.New(fmt.Sprintf("Genome [%d] has recurrent link %d -> %d, while feed-forward only allowed",
+				g.Id, in_node.Id, out_node.Id))
+		}
+		if c.IsLinkBanned(byte(in_node.NeuronType), byte(out_node.NeuronType)) {
+			return errors.New(fmt.Sprintf("Genome [%d] has banned link %d -> %d from %s to %s node",
+				g.Id, in_node.Id, out_node.Id, network.NeuronTypeName(in_node.NeuronType),
+				network.NeuronTypeName(out_node.NeuronType)))
+		}
+		if fan_in := countFanIn(g.Genes, out_node.Id); c.MaxFanIn > 0 && fan_in > c.MaxFanIn {
+			return errors.New(fmt.Sprintf("Genome [%d] has %d enabled links into node [%d], maximum allowed: %d",
+				g.Id, fan_in, out_node.Id, c.MaxFanIn))
+		}
+	}
+	return nil
+}
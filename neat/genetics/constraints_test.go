@@ -0,0 +1,220 @@
+package genetics
+
+import (
+	"testing"
+	"strings"
+	"math/rand"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/network"
+)
+
+// Builds test genome with two hidden nodes between inputs and output
+func buildTestGenomeWithHidden(id int) *Genome {
+	gnome := buildTestGenome(id)
+	gnome.Nodes = append(gnome.Nodes,
+		network.ReadNNode(strings.NewReader("5 0 0 0"), gnome.Traits),
+		network.ReadNNode(strings.NewReader("6 0 0 0"), gnome.Traits))
+	gnome.Genes = append(gnome.Genes,
+		ReadGene(strings.NewReader("1 1 5 1.0 false 4 0 true"), gnome.Traits, gnome.Nodes),
+		ReadGene(strings.NewReader("1 5 4 1.0 false 5 0 true"), gnome.Traits, gnome.Nodes),
+		ReadGene(strings.NewReader("1 2 6 1.0 false 6 0 true"), gnome.Traits, gnome.Nodes),
+		ReadGene(strings.NewReader("1 6 4 1.0 false 7 0 true"), gnome.Traits, gnome.Nodes),
+		ReadGene(strings.NewReader("1 4 4 1.0 true 8 0 true"), gnome.Traits, gnome.Nodes))
+	return gnome
+}
+
+func TestGenome_mutateAddNodeConstrained(t *testing.T) {
+	rand.Seed(42)
+	constraints := []neat.Constraints{
+		{MaxHiddenNodes:1},
+		{MaxEnabledGenes:3},
+		{BannedLinks:[][2]byte{{byte(network.HiddenNeuron), byte(network.OutputNeuron)}}},
+	}
+	for i, c := range constraints {
+		gnome := buildTestGenome(1)
+		gnome.genesis(1)
+		context := neat.NeatContext{Constraints:c}
+		pop := newPopulation()
+
+		// the first hidden node is allowed by limit of hidden nodes only
+		res, err := gnome.mutateAddNode(pop, &context)
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		if res != (c.MaxHiddenNodes == 1) {
+			t.Error("Wrong first node mutation result", i, res)
+		}
+		res, err = gnome.mutateAddNode(pop, &context)
+		if res || err != nil {
+			t.Error("New node added in violation of constraints", i, err)
+		}
+		if hidden := countHiddenNodes(gnome.Nodes); hidden > 1 || (c.MaxHiddenNodes == 0 && hidden != 0) {
+			t.Error("Wrong number of hidden nodes", i, hidden)
+		}
+	}
+}
+
+func TestGenome_mutateAddLinkConstrained(t *testing.T) {
+	rand.Seed(42)
+	pop := newPopulation()
+	pop.currInnovNum = int64(4)
+
+	// only recurrent links are left to be added to output
+	gnome := buildTestGenome(1)
+	gnome.genesis(1)
+	context := neat.NeatContext{
+		RecurOnlyProb:1.0,
+		NewLinkTries:20,
+		Constraints:neat.Constraints{FeedForwardOnly:true},
+	}
+	res, err := gnome.mutateAddLink(pop, &context)
+	if res || err != nil || len(gnome.Genes) != 3 {
+		t.Error("Recurrent link added to feed-forward network", len(gnome.Genes), err)
+	}
+
+	// no room for new links
+	context = neat.NeatContext{NewLinkTries:20, Constraints:neat.Constraints{MaxEnabledGenes:3}}
+	res, err = gnome.mutateAddLink(pop, &context)
+	if res || err != nil || len(gnome.Genes) != 3 {
+		t.Error("Link added above the limit of enabled genes", len(gnome.Genes), err)
+	}
+
+	// the output already has maximal fan-in, thus new links go only to the hidden node
+	gnome.Nodes = append(gnome.Nodes, network.ReadNNode(strings.NewReader("5 0 0 0"), gnome.Traits))
+	gnome.genesis(1)
+	context = neat.NeatContext{NewLinkTries:50, Constraints:neat.Constraints{MaxFanIn:3}}
+	for i := 0; i < 5; i++ {
+		if _, err = gnome.mutateAddLink(pop, &context); err != nil {
+			t.Fatal(err)
+		}
+		gnome.genesis(1)
+	}
+	if len(gnome.Genes) == 3 {
+		t.Error("No links added to hidden node")
+	}
+	if fan_in := countFanIn(gnome.Genes, 4); fan_in != 3 {
+		t.Error("The fan-in of output exceeded", fan_in)
+	}
+	if fan_in := countFanIn(gnome.Genes, 5); fan_in > 3 {
+		t.Error("The fan-in of hidden node exceeded", fan_in)
+	}
+}
+
+func TestGenome_mutateConnectSensorsConstrained(t *testing.T) {
+	gnome := buildTestGenome(1)
+	gnome.Nodes = append(gnome.Nodes, network.ReadNNode(strings.NewReader("5 0 1 1"), gnome.Traits))
+	gnome.genesis(1)
+	context := neat.NeatContext{
+		Constraints:neat.Constraints{BannedLinks:[][2]byte{{byte(network.InputNeuron), byte(network.OutputNeuron)}}},
+	}
+	res, err := gnome.mutateConnectSensors(newPopulation(), &context)
+	if res || err != nil || len(gnome.Genes) != 3 {
+		t.Error("Banned link added by sensors connection", len(gnome.Genes), err)
+	}
+}
+
+func TestGenome_mateConstrained(t *testing.T) {
+	rand.Seed(42)
+	cases := []struct {
+		constraints neat.Constraints
+		genes       int
+		nodes       int
+	}{
+		{neat.Constraints{}, 8, 6},
+		{neat.Constraints{MaxHiddenNodes:1}, 6, 5},
+		{neat.Constraints{MaxEnabledGenes:5}, 5, 5},
+		{neat.Constraints{MaxFanIn:4}, 6, 6},
+		{neat.Constraints{FeedForwardOnly:true}, 7, 6},
+		{neat.Constraints{BannedLinks:[][2]byte{{byte(network.InputNeuron), byte(network.HiddenNeuron)}}}, 6, 6},
+	}
+	for i, c := range cases {
+		context := neat.NeatContext{Constraints:c.constraints}
+		// the excess genes are inherited from the fitter first parent
+		child, err := buildTestGenomeWithHidden(1).mateMultipoint(buildTestGenome(2), 3, 10.0, 1.0, &context)
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		if len(child.Genes) != c.genes || len(child.Nodes) != c.nodes {
+			t.Error("Wrong offspring of multipoint crossover", i, len(child.Genes), len(child.Nodes))
+		}
+
+		child, err = buildTestGenomeWithHidden(1).mateMultipointAvg(buildTestGenome(2), 3, 10.0, 1.0, &context)
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		if len(child.Genes) != c.genes || len(child.Nodes) != c.nodes {
+			t.Error("Wrong offspring of multipoint average crossover", i, len(child.Genes), len(child.Nodes))
+		}
+
+		child, err = buildTestGenomeWithHidden(1).mateSinglepoint(buildTestGenome(2), 3, &context)
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		if len(child.Genes) != c.genes || len(child.Nodes) != c.nodes {
+			t.Error("Wrong offspring of single point crossover", i, len(child.Genes), len(child.Nodes))
+		}
+	}
+}
+
+func TestGenome_mutateGeneReenableConstrained(t *testing.T) {
+	gnome := buildTestGenome(1)
+	gnome.Genes[1].IsEnabled = false
+	constraints := neat.Constraints{MaxEnabledGenes:2}
+	if _, err := gnome.mutateGeneReenable(&constraints); err != nil {
+		t.Fatal(err)
+	}
+	if gnome.Genes[1].IsEnabled {
+		t.Error("Gene enabled above the limit of enabled genes")
+	}
+	if _, err := gnome.mutateToggleEnable(5, &constraints); err != nil {
+		t.Fatal(err)
+	}
+	if enabled := countEnabledGenes(gnome.Genes); enabled > 2 {
+		t.Error("Genes enabled above the limit by toggling", enabled)
+	}
+}
+
+func TestGenome_checkConstraints(t *testing.T) {
+	cases := []struct {
+		constraints neat.Constraints
+		valid       bool
+	}{
+		{neat.Constraints{}, true},
+		{neat.Constraints{MaxHiddenNodes:2, MaxEnabledGenes:8, MaxFanIn:6}, true},
+		{neat.Constraints{MaxHiddenNodes:1}, false},
+		{neat.Constraints{MaxEnabledGenes:7}, false},
+		{neat.Constraints{MaxFanIn:5}, false},
+		{neat.Constraints{FeedForwardOnly:true}, false},
+		{neat.Constraints{BannedLinks:[][2]byte{{byte(network.BiasNeuron), byte(network.HiddenNeuron)}}}, true},
+		{neat.Constraints{BannedLinks:[][2]byte{{byte(network.InputNeuron), byte(network.HiddenNeuron)}}}, false},
+	}
+	for i, c := range cases {
+		if err := buildTestGenomeWithHidden(1).checkConstraints(&c.constraints); (err == nil) != c.valid {
+			t.Error("Wrong constraints check result", i, err)
+		}
+	}
+}
+
+func TestNewPopulationConstrained(t *testing.T) {
+	context := neat.NeatContext{
+		CompatThreshold:0.5,
+		PopSize:10,
+		Constraints:neat.Constraints{BannedLinks:[][2]byte{{byte(network.InputNeuron), byte(network.OutputNeuron)}}},
+	}
+	if _, err := NewPopulation(buildTestGenome(1), &context); err == nil {
+		t.Error("Population spawned from start genome violating constraints")
+	}
+
+	context.Constraints = neat.Constraints{MaxHiddenNodes:2}
+	pop, err := NewPopulation(buildTestGenome(1), &context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = pop.Reseed([]*Genome{buildTestGenomeWithHidden(2)}, &context); err != nil {
+		t.Error(err)
+	}
+	context.Constraints.MaxHiddenNodes = 1
+	if err = pop.Reseed([]*Genome{buildTestGenomeWithHidden(3)}, &context); err == nil {
+		t.Error("Population reseeded from genome violating constraints")
+	}
+}
@@ -463,6 +463,118 @@ func (g *Genome) duplicate(new_id int) *Genome {
 	return NewGenome(new_id, traits_dup, nodes_dup, genes_dup)
 }
 
+// Creates simplified copy of this Genome with dead structure pruned: disabled genes, genes with absolute weight below
+// weightTolerance, and hidden nodes which either can not be reached from sensors or whose signal never reach any output
+// (along with all their genes). The sensors and outputs are always kept as well as all traits. If testInputs provided,
+// the outputs of simplified and original networks will be compared for each input vector after relaxation and
+// the error returned if any of outputs differs more than epsilon.
+func (g *Genome) Simplify(weightTolerance float64, testInputs [][]float64, epsilon float64) (*Genome, error) {
+	simple := g.duplicateWithEnabledFlags()
+
+	// remove disabled and near-zero genes
+	genes := make([]*Gene, 0, len(simple.Genes))
+	for _, gn := range simple.Genes {
+		if gn.IsEnabled && math.Abs(gn.Link.Weight) >= weightTolerance {
+			genes = append(genes, gn)
+		}
+	}
+
+	// find nodes reachable from sensors and nodes from which outputs are reachable
+	from_inputs := make(map[*network.NNode]bool)
+	to_outputs := make(map[*network.NNode]bool)
+	for _, nd := range simple.Nodes {
+		if nd.IsSensor() {
+			from_inputs[nd] = true
+		}
+		if nd.NeuronType == network.OutputNeuron {
+			to_outputs[nd] = true
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, gn := range genes {
+			if from_inputs[gn.Link.InNode] && !from_inputs[gn.Link.OutNode] {
+				from_inputs[gn.Link.OutNode] = true
+				changed = true
+			}
+			if to_outputs[gn.Link.OutNode] && !to_outputs[gn.Link.InNode] {
+				to_outputs[gn.Link.InNode] = true
+				changed = true
+			}
+		}
+	}
+
+	// keep only live hidden nodes and genes connecting live nodes
+	live := make(map[*network.NNode]bool)
+	nodes := make([]*network.NNode, 0, len(simple.Nodes))
+	for _, nd := range simple.Nodes {
+		if nd.NeuronType != network.HiddenNeuron || (from_inputs[nd] && to_outputs[nd]) {
+			live[nd] = true
+			nodes = append(nodes, nd)
+		}
+	}
+	simple.Nodes = nodes
+	simple.Genes = make([]*Gene, 0, len(genes))
+	for _, gn := range genes {
+		if live[gn.Link.InNode] && live[gn.Link.OutNode] {
+			simple.Genes = append(simple.Genes, gn)
+		}
+	}
+	neat.DebugLog(fmt.Sprintf("Genome %d simplified: nodes %d -> %d, genes %d -> %d",
+		g.Id, len(g.Nodes), len(simple.Nodes), len(g.Genes), len(simple.Genes)))
+
+	// verify that outputs are unchanged
+	if len(testInputs) > 0 {
+		orig_net, simple_net := g.duplicateWithEnabledFlags().genesis(g.Id), simple.genesis(g.Id)
+		for i, in := range testInputs {
+			orig_out, err := relaxForSimplify(orig_net, in)
+			if err != nil {
+				return nil, err
+			}
+			simple_out, err := relaxForSimplify(simple_net, in)
+			if err != nil {
+				return nil, err
+			}
+			for j := range orig_out {
+				if math.Abs(orig_out[j] - simple_out[j]) > epsilon {
+					return nil, errors.New(
+						fmt.Sprintf("Simplified genome output #%d for test input #%d differs: %f != %f",
+							j, i, orig_out[j], simple_out[j]))
+				}
+			}
+		}
+		simple.Phenotype = nil
+	}
+	return simple, nil
+}
+
+// Duplicates this Genome with the same id keeping genes enabled flags, which are reset by duplicate
+func (g *Genome) duplicateWithEnabledFlags() *Genome {
+	dup := g.duplicate(g.Id)
+	for i, gn := range g.Genes {
+		dup.Genes[i].IsEnabled = gn.IsEnabled
+	}
+	return dup
+}
+
+// Relaxes the network on given input for genome simplification check. The zero tolerance makes relaxation to run
+// exactly depth + 2 iterations, thus the oscillating networks are compared by the outputs of the last iteration and
+// the disconnected outputs are compared as inactive (zero).
+func relaxForSimplify(net *network.Network, in []float64) ([]float64, error) {
+	depth, err := net.MaxDepth()
+	if err != nil {
+		// the network with loops - use maximal depth returned
+		neat.DebugLog(fmt.Sprintf("Failed to estimate network depth: %s", err))
+	}
+	out, err := net.Relax(in, depth + 2, 0)
+	if err == network.ErrNetRelaxationNotConverged {
+		err = nil
+	} else if err == network.ErrNetActivationAborted {
+		out, err = net.ReadOutputs(), nil
+	}
+	return out, err
+}
+
 // For debugging: A number of tests can be run on a genome to check its integrity.
 // Note: Some of these tests do not indicate a bug, but rather are meant to be used to detect specific system states.
 func (g *Genome) verify() (bool, error) {
@@ -623,7 +735,7 @@ func (g *Genome) mutateConnectSensors(pop *Population, context *neat.NeatContext
 
 	// pick randomly from disconnected sensors
 	sensor := disconnected_sensors[rand.Intn(len(disconnected_sensors))]
-	// add new links to chosen sensor, avoiding redundancy
+	// add new links to chosen sensor, avoiding redundancy and links not allowed by constraints
 	link_added := false
 	for _, output := range outputs {
 		found := false
@@ -634,7 +746,7 @@ func (g *Genome) mutateConnectSensors(pop *Population, context *neat.NeatContext
 			}
 		}
 
-		if !found {
+		if !found && linkAllowed(&context.Constraints, sensor, output, false, true, g.Genes) {
 			var new_gene *Gene
 			// Check to see if this innovation already occurred in the population
 			innovation_found := false
@@ -701,9 +813,14 @@ func (g *Genome) mutateAddLink(pop *Population, context *neat.NeatContext) (bool
 
 	nodes_len := len(g.Nodes)
 
+	// No room for new link if the limit of enabled genes reached
+	if context.Constraints.MaxEnabledGenes > 0 && countEnabledGenes(g.Genes) >= context.Constraints.MaxEnabledGenes {
+		return false, nil
+	}
+
 	// Decide whether to make link recurrent
 	do_recur := false
-	if rand.Float64() < context.RecurOnlyProb {
+	if rand.Float64() < context.RecurOnlyProb && !context.Constraints.FeedForwardOnly {
 		do_recur = true
 	}
 
@@ -757,6 +874,9 @@ func (g *Genome) mutateAddLink(pop *Population, context *neat.NeatContext) (bool
 		if node_2.IsSensor() {
 			// Don't allow SENSORS to get input
 			link_exists = true
+		} else if !linkAllowed(&context.Constraints, node_1, node_2, do_recur, true, g.Genes) {
+			// The link is not allowed by constraints, thus treated as existing
+			link_exists = true
 		} else {
 			for _, gene := range g.Genes {
 				if gene.Link.InNode.Id == node_1.Id &&
@@ -866,6 +986,12 @@ func (g *Genome) mutateAddNode(pop *Population, context *neat.NeatContext) (bool
 	if len(g.Genes) == 0 {
 		return false, nil // it's possible to have such a network without any link
 	}
+	// The new node replaces one enabled gene with two, thus check that there is room for both
+	constraints := &context.Constraints
+	if (constraints.MaxHiddenNodes > 0 && countHiddenNodes(g.Nodes) >= constraints.MaxHiddenNodes) ||
+		(constraints.MaxEnabledGenes > 0 && countEnabledGenes(g.Genes) >= constraints.MaxEnabledGenes) {
+		return false, nil
+	}
 
 	// First, find a random gene already in the genome
 	found := false
@@ -898,6 +1024,11 @@ func (g *Genome) mutateAddNode(pop *Population, context *neat.NeatContext) (bool
 		// Failed to find appropriate gene
 		return false, nil
 	}
+	if constraints.IsLinkBanned(byte(gene.Link.InNode.NeuronType), byte(network.HiddenNeuron)) ||
+		constraints.IsLinkBanned(byte(network.HiddenNeuron), byte(gene.Link.OutNode.NeuronType)) {
+		// The links to and from the new node are not allowed by constraints
+		return false, nil
+	}
 
 	gene.IsEnabled = false;
 
@@ -1055,6 +1186,58 @@ func (g *Genome) mutateLinkWeights(power, rate float64, mutation_type mutatorTyp
 	return true, nil
 }
 
+// Adds Gaussian noise to the bias values of the neuron nodes (the sensors has no bias). Each neuron is mutated with
+// given rate probability and once in a while the bias will be replaced with entirely new random value, similar to the
+// cold gaussian mutation of the link weights.
+func (g *Genome) mutateNodeBias(power, rate float64) (bool, error) {
+	if len(g.Nodes) == 0 {
+		return false, errors.New("Genome has no nodes")
+	}
+
+	mutated := false
+	for _, node := range g.Nodes {
+		if node.IsSensor() || rand.Float64() > rate {
+			continue
+		}
+		rand_val := float64(neat.RandPosNeg()) * rand.Float64() * power
+		if rand.Float64() < 0.1 {
+			// cold mutation - replace bias with new value
+			node.Bias = rand_val
+		} else {
+			node.Bias += rand_val
+		}
+		mutated = true
+	}
+	return mutated, nil
+}
+
+// Adds Gaussian noise to the time constants of the neuron nodes used by CTRNN activation. Each neuron is mutated with
+// given rate probability and the resulting time constant is clamped to the [min, max] range, because zero or negative
+// time constant makes CTRNN integration unstable.
+func (g *Genome) mutateTimeConstants(power, rate, min, max float64) (bool, error) {
+	if len(g.Nodes) == 0 {
+		return false, errors.New("Genome has no nodes")
+	}
+	if min <= 0 || max < min {
+		return false, errors.New(fmt.Sprintf("Wrong time constant range: [%f, %f]", min, max))
+	}
+
+	mutated := false
+	for _, node := range g.Nodes {
+		if node.IsSensor() || rand.Float64() > rate {
+			continue
+		}
+		node.TimeConstant += float64(neat.RandPosNeg()) * rand.Float64() * power
+		if node.TimeConstant < min {
+			node.TimeConstant = min
+		} else if node.TimeConstant > max {
+			node.TimeConstant = max
+		}
+		mutated = true
+	}
+	return mutated, nil
+}
+
 // Perturb params in one trait
 func (g *Genome) mutateRandomTrait(context *neat.NeatContext) (bool, error) {
 	if len(g.Traits) == 0 {
@@ -1106,8 +1289,9 @@ func (g *Genome) mutateNodeTrait(times int) (bool, error) {
 	return true, nil
 }
 
-// Toggle genes from enable on to enable off or vice versa.  Do it specified number of times.
-func (g *Genome) mutateToggleEnable(times int) (bool, error) {
+// Toggle genes from enable on to enable off or vice versa.  Do it specified number of times. The disabled gene is
+// enabled only if allowed by given constraints.
+func (g *Genome) mutateToggleEnable(times int, constraints *neat.Constraints) (bool, error) {
 	if len(g.Genes) == 0 {
 		return false, errors.New("Genome has no genes to toggle")
 	}
@@ -1126,20 +1310,21 @@ func (g *Genome) mutateToggleEnable(times int) (bool, error) {
 					break
 				}
 			}
-		} else {
+		} else if linkAllowed(constraints, gene.Link.InNode, gene.Link.OutNode, gene.Link.IsRecurrent, true, g.Genes) {
 			gene.IsEnabled = true
 		}
 
 	}
 	return true, nil
 }
-// Finds first disabled gene and enable it
-func (g *Genome) mutateGeneReenable() (bool, error) {
+// Finds first disabled gene allowed to be enabled by given constraints and enable it
+func (g *Genome) mutateGeneReenable(constraints *neat.Constraints) (bool, error) {
 	if len(g.Genes) == 0 {
 		return false, errors.New("Genome has no genes to re-enable")
 	}
 	for _, gene := range g.Genes {
-		if !gene.IsEnabled {
+		if !gene.IsEnabled &&
+			linkAllowed(constraints, gene.Link.InNode, gene.Link.OutNode, gene.Link.IsRecurrent, true, g.Genes) {
 			gene.IsEnabled = true
 			break
 		}
@@ -1171,14 +1356,25 @@ func (g *Genome) mutateAllNonstructural(context *neat.NeatContext) (bool, error)
 		res, err = g.mutateLinkWeights(context.WeightMutPower, 1.0, gaussianMutator)
 	}
 
+	if err == nil && rand.Float64() < context.MutateNodeBiasProb {
+		// mutate node bias
+		res, err = g.mutateNodeBias(context.NodeBiasMutPower, 1.0)
+	}
+
+	if err == nil && rand.Float64() < context.MutateTimeConstantProb {
+		// mutate CTRNN time constants
+		res, err = g.mutateTimeConstants(context.TimeConstantMutPower, 1.0,
+			context.TimeConstantMin, context.TimeConstantMax)
+	}
+
 	if err == nil && rand.Float64() < context.MutateToggleEnableProb {
 		// mutate toggle enable
-		res, err = g.mutateToggleEnable(1)
+		res, err = g.mutateToggleEnable(1, &context.Constraints)
 	}
 
 	if err == nil && rand.Float64() < context.MutateGeneReenableProb {
 		// mutate gene reenable
-		res, err = g.mutateGeneReenable();
+		res, err = g.mutateGeneReenable(&context.Constraints);
 	}
 	return res, err
 }
@@ -1188,8 +1384,8 @@ func (g *Genome) mutateAllNonstructural(context *neat.NeatContext) (bool, error)
 // This method mates this Genome with another Genome g. For every point in each Genome, where each Genome shares
 // the innovation number, the Gene is chosen randomly from either parent.  If one parent has an innovation absent in
 // the other, the baby may inherit the innovation if it is from the more fit parent.
-// The new Genome is given the id in the genomeid argument.
-func (gen *Genome) mateMultipoint(og *Genome, genomeid int, fitness1, fitness2 float64) (*Genome, error) {
+// The new Genome is given the id in the genomeid argument. The genes violating constraints of the context are not inherited.
+func (gen *Genome) mateMultipoint(og *Genome, genomeid int, fitness1, fitness2 float64, context *neat.NeatContext) (*Genome, error) {
 	// Check if genomes has equal number of traits
 	if len(gen.Traits) != len(og.Traits) {
 		return nil, errors.New(fmt.Sprintf("Genomes has different traits count, %d != %d", len(gen.Traits), len(og.Traits)))
@@ -1299,6 +1495,11 @@ func (gen *Genome) mateMultipoint(og *Genome, genomeid int, fitness1, fitness2 f
 			}
 		}
 
+		// Skip the gene which would violate constraints of the offspring
+		if !skip && !inheritanceAllowed(&context.Constraints, chosen_gene, !disable, new_genes, new_nodes) {
+			skip = true
+		}
+
 		// Now add the chosen gene to the baby
 		if (!skip) {
 			// Check for the nodes, add them if not in the baby Genome already
@@ -1365,7 +1566,7 @@ func (gen *Genome) mateMultipoint(og *Genome, genomeid int, fitness1, fitness2 f
 
 // This method mates like multipoint but instead of selecting one or the other when the innovation numbers match,
 // it averages their weights.
-func (gen *Genome) mateMultipointAvg(og *Genome, genomeid int, fitness1, fitness2 float64) (*Genome, error) {
+func (gen *Genome) mateMultipointAvg(og *Genome, genomeid int, fitness1, fitness2 float64, context *neat.NeatContext) (*Genome, error) {
 	// Check if genomes has equal number of traits
 	if len(gen.Traits) != len(og.Traits) {
 		return nil, errors.New(fmt.Sprintf("Genomes has different traits count, %d != %d", len(gen.Traits), len(og.Traits)))
@@ -1500,6 +1701,11 @@ func (gen *Genome) mateMultipointAvg(og *Genome, genomeid int, fitness1, fitness
 			}
 		}
 
+		// Skip the gene which would violate constraints of the offspring
+		if !skip && !inheritanceAllowed(&context.Constraints, chosen_gene, true, new_genes, new_nodes) {
+			skip = true
+		}
+
 		if (!skip) {
 			// Now add the chosen gene to the baby
 
@@ -1564,7 +1770,7 @@ func (gen *Genome) mateMultipointAvg(og *Genome, genomeid int, fitness1, fitness
 // This method is similar to a standard single point CROSSOVER operator. Traits are averaged as in the previous two
 // mating methods. A Gene is chosen in the smaller Genome for splitting. When the Gene is reached, it is averaged with
 // the matching Gene from the larger Genome, if one exists. Then every other Gene is taken from the larger Genome.
-func (gen *Genome) mateSinglepoint(og *Genome, genomeid int) (*Genome, error) {
+func (gen *Genome) mateSinglepoint(og *Genome, genomeid int, context *neat.NeatContext) (*Genome, error) {
 	// Check if genomes has equal number of traits
 	if len(gen.Traits) != len(og.Traits) {
 		return nil, errors.New(fmt.Sprintf("Genomes has different traits count, %d != %d", len(gen.Traits), len(og.Traits)))
@@ -1709,6 +1915,11 @@ func (gen *Genome) mateSinglepoint(og *Genome, genomeid int) (*Genome, error) {
 			}
 		}
 
+		// Skip the gene which would violate constraints of the offspring
+		if !skip && !inheritanceAllowed(&context.Constraints, chosen_gene, true, new_genes, new_nodes) {
+			skip = true
+		}
+
 		//Now add the chosen gene to the baby
 		if (!skip) {
 			// Check for the nodes, add them if not in the baby Genome already
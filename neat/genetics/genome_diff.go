@@ -0,0 +1,290 @@
+package genetics
+
+import (
+	"io"
+	"fmt"
+	"math"
+	"bytes"
+	"encoding/json"
+	"github.com/yaricom/goNEAT/neat"
+)
+
+// The gene present only in one of the compared genomes
+type GeneRef struct {
+	// The innovation number of the gene
+	InnovationNum int64   `json:"innovation_num"`
+	// The IDs of nodes connected by the gene's link
+	InNodeId      int     `json:"in_node_id"`
+	OutNodeId     int     `json:"out_node_id"`
+	// The weight of the gene's link
+	Weight        float64 `json:"weight"`
+	// If true the gene is enabled
+	Enabled       bool    `json:"enabled"`
+}
+
+// The gene with the same innovation number found in both compared genomes
+type MatchingGene struct {
+	// The innovation number of the gene
+	InnovationNum int64   `json:"innovation_num"`
+	// The IDs of nodes connected by the gene's link
+	InNodeId      int     `json:"in_node_id"`
+	OutNodeId     int     `json:"out_node_id"`
+	// The weights of the gene's link in the first and second genomes
+	Weight1       float64 `json:"weight1"`
+	Weight2       float64 `json:"weight2"`
+	// The difference of weights: Weight2 - Weight1
+	WeightDelta   float64 `json:"weight_delta"`
+	// The absolute difference of mutation numbers which contributes into compatibility distance
+	MutationDiff  float64 `json:"mutation_diff"`
+	// The enabled flags of gene in the first and second genomes
+	Enabled1      bool    `json:"enabled1"`
+	Enabled2      bool    `json:"enabled2"`
+}
+
+// The difference of trait parameters between compared genomes. The params are nil if trait is absent in the genome.
+type TraitDiff struct {
+	// The trait ID
+	TraitId int       `json:"trait_id"`
+	// The trait parameters in the first and second genomes
+	Params1 []float64 `json:"params1"`
+	Params2 []float64 `json:"params2"`
+}
+
+// The structured difference between two genomes which explains the compatibility distance used for speciation.
+// The disjoint genes are the ones not matching in the middle of genomes, while the excess genes are the ones found
+// after the end of other genome (i.e. having innovation number greater than the maximal one of other genome).
+type GenomeDiff struct {
+	// The IDs of compared genomes
+	GenomeId1       int            `json:"genome_id1"`
+	GenomeId2       int            `json:"genome_id2"`
+
+	// The genes found in both genomes
+	Matching        []MatchingGene `json:"matching"`
+	// The disjoint genes found only in the first and second genome respectively
+	Disjoint1       []GeneRef      `json:"disjoint1"`
+	Disjoint2       []GeneRef      `json:"disjoint2"`
+	// The excess genes found only in the first and second genome respectively
+	Excess1         []GeneRef      `json:"excess1"`
+	Excess2         []GeneRef      `json:"excess2"`
+
+	// The IDs of nodes found only in the first and second genome respectively
+	NodesAdded1     []int          `json:"nodes_added1"`
+	NodesAdded2     []int          `json:"nodes_added2"`
+
+	// The traits which differ between genomes
+	Traits          []TraitDiff    `json:"traits"`
+
+	// The average difference of mutation numbers among matching genes
+	AvgMutationDiff float64        `json:"avg_mutation_diff"`
+	// The compatibility distance between genomes, calculated only if context provided
+	Compatibility   float64        `json:"compatibility"`
+	// The compatibility threshold of context, zero if context was not provided
+	CompatThreshold float64        `json:"compat_threshold"`
+}
+
+// Finds the structured difference between this genome and the other one. If context is not nil, the compatibility
+// distance between genomes will be calculated the same way as it done for speciation.
+func (g *Genome) Diff(og *Genome, context *neat.NeatContext) *GenomeDiff {
+	diff := GenomeDiff{
+		GenomeId1:g.Id,
+		GenomeId2:og.Id,
+		Matching:make([]MatchingGene, 0),
+		Disjoint1:make([]GeneRef, 0),
+		Disjoint2:make([]GeneRef, 0),
+		Excess1:make([]GeneRef, 0),
+		Excess2:make([]GeneRef, 0),
+		NodesAdded1:make([]int, 0),
+		NodesAdded2:make([]int, 0),
+		Traits:make([]TraitDiff, 0),
+	}
+
+	// genes are ordered by innovation number - merge them
+	size1, size2 := len(g.Genes), len(og.Genes)
+	mut_diff_total := 0.0
+	for i1, i2 := 0, 0; i1 < size1 || i2 < size2; {
+		if i1 >= size1 {
+			diff.Excess2 = append(diff.Excess2, newGeneRef(og.Genes[i2]))
+			i2++
+		} else if i2 >= size2 {
+			diff.Excess1 = append(diff.Excess1, newGeneRef(g.Genes[i1]))
+			i1++
+		} else {
+			gene1, gene2 := g.Genes[i1], og.Genes[i2]
+			if gene1.InnovationNum == gene2.InnovationNum {
+				mg := MatchingGene{
+					InnovationNum:gene1.InnovationNum,
+					InNodeId:gene1.Link.InNode.Id,
+					OutNodeId:gene1.Link.OutNode.Id,
+					Weight1:gene1.Link.Weight,
+					Weight2:gene2.Link.Weight,
+					WeightDelta:gene2.Link.Weight - gene1.Link.Weight,
+					MutationDiff:math.Abs(gene1.MutationNum - gene2.MutationNum),
+					Enabled1:gene1.IsEnabled,
+					Enabled2:gene2.IsEnabled,
+				}
+				mut_diff_total += mg.MutationDiff
+				diff.Matching = append(diff.Matching, mg)
+				i1++
+				i2++
+			} else if gene1.InnovationNum < gene2.InnovationNum {
+				diff.Disjoint1 = append(diff.Disjoint1, newGeneRef(gene1))
+				i1++
+			} else {
+				diff.Disjoint2 = append(diff.Disjoint2, newGeneRef(gene2))
+				i2++
+			}
+		}
+	}
+	if len(diff.Matching) > 0 {
+		diff.AvgMutationDiff = mut_diff_total / float64(len(diff.Matching))
+	}
+
+	// find nodes present only in one of genomes
+	nodes1, nodes2 := make(map[int]bool), make(map[int]bool)
+	for _, n := range g.Nodes {
+		nodes1[n.Id] = true
+	}
+	for _, n := range og.Nodes {
+		nodes2[n.Id] = true
+		if !nodes1[n.Id] {
+			diff.NodesAdded2 = append(diff.NodesAdded2, n.Id)
+		}
+	}
+	for _, n := range g.Nodes {
+		if !nodes2[n.Id] {
+			diff.NodesAdded1 = append(diff.NodesAdded1, n.Id)
+		}
+	}
+
+	// find different traits
+	traits2 := make(map[int]*neat.Trait)
+	for _, tr := range og.Traits {
+		traits2[tr.Id] = tr
+	}
+	for _, tr1 := range g.Traits {
+		tr2, found := traits2[tr1.Id]
+		if !found {
+			diff.Traits = append(diff.Traits, TraitDiff{TraitId:tr1.Id, Params1:tr1.Params})
+		} else if !equalParams(tr1.Params, tr2.Params) {
+			diff.Traits = append(diff.Traits, TraitDiff{TraitId:tr1.Id, Params1:tr1.Params, Params2:tr2.Params})
+		}
+		delete(traits2, tr1.Id)
+	}
+	for _, tr2 := range og.Traits {
+		if _, found := traits2[tr2.Id]; found {
+			diff.Traits = append(diff.Traits, TraitDiff{TraitId:tr2.Id, Params2:tr2.Params})
+		}
+	}
+
+	if context != nil {
+		diff.Compatibility = g.compatibility(og, context)
+		diff.CompatThreshold = context.CompatThreshold
+	}
+	return &diff
+}
+
+// Returns true if genomes are identical
+func (d *GenomeDiff) IsEmpty() bool {
+	if len(d.Disjoint1) + len(d.Disjoint2) + len(d.Excess1) + len(d.Excess2) > 0 ||
+		len(d.NodesAdded1) + len(d.NodesAdded2) + len(d.Traits) > 0 {
+		return false
+	}
+	for _, mg := range d.Matching {
+		if mg.WeightDelta != 0 || mg.MutationDiff != 0 || mg.Enabled1 != mg.Enabled2 {
+			return false
+		}
+	}
+	return true
+}
+
+// Writes the genome difference as human readable text
+func (d *GenomeDiff) WriteText(w io.Writer) error {
+	b := bytes.NewBufferString("")
+	fmt.Fprintf(b, "GENOME DIFF: #%d vs #%d\n", d.GenomeId1, d.GenomeId2)
+	fmt.Fprintf(b, "Matching genes: %d, disjoint: %d/%d, excess: %d/%d, avg mutation diff: %.3f\n",
+		len(d.Matching), len(d.Disjoint1), len(d.Disjoint2), len(d.Excess1), len(d.Excess2), d.AvgMutationDiff)
+	if d.CompatThreshold > 0 {
+		same := "same species"
+		if d.Compatibility >= d.CompatThreshold {
+			same = "different species"
+		}
+		fmt.Fprintf(b, "Compatibility: %.3f, threshold: %.3f (%s)\n", d.Compatibility, d.CompatThreshold, same)
+	}
+
+	fmt.Fprintln(b, "Matching genes:")
+	for _, mg := range d.Matching {
+		enabled := ""
+		if mg.Enabled1 != mg.Enabled2 {
+			enabled = fmt.Sprintf(" enabled: %t -> %t", mg.Enabled1, mg.Enabled2)
+		}
+		fmt.Fprintf(b, "\tINNOV %4d (%3d -> %3d) weight: % .3f -> % .3f (delta % .3f) mutation diff: %.3f%s\n",
+			mg.InnovationNum, mg.InNodeId, mg.OutNodeId, mg.Weight1, mg.Weight2, mg.WeightDelta, mg.MutationDiff,
+			enabled)
+	}
+	writeGeneRefs(b, "Disjoint genes of first genome:", d.Disjoint1)
+	writeGeneRefs(b, "Disjoint genes of second genome:", d.Disjoint2)
+	writeGeneRefs(b, "Excess genes of first genome:", d.Excess1)
+	writeGeneRefs(b, "Excess genes of second genome:", d.Excess2)
+
+	if len(d.NodesAdded1) > 0 {
+		fmt.Fprintf(b, "Nodes only in first genome: %v\n", d.NodesAdded1)
+	}
+	if len(d.NodesAdded2) > 0 {
+		fmt.Fprintf(b, "Nodes only in second genome: %v\n", d.NodesAdded2)
+	}
+	if len(d.Traits) > 0 {
+		fmt.Fprintln(b, "Different traits:")
+		for _, td := range d.Traits {
+			fmt.Fprintf(b, "\tTRAIT %d: %v -> %v\n", td.TraitId, td.Params1, td.Params2)
+		}
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// Writes the genome difference as JSON
+func (d *GenomeDiff) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// Stringer
+func (d *GenomeDiff) String() string {
+	b := bytes.NewBufferString("")
+	d.WriteText(b)
+	return b.String()
+}
+
+func newGeneRef(g *Gene) GeneRef {
+	return GeneRef{
+		InnovationNum:g.InnovationNum,
+		InNodeId:g.Link.InNode.Id,
+		OutNodeId:g.Link.OutNode.Id,
+		Weight:g.Link.Weight,
+		Enabled:g.IsEnabled,
+	}
+}
+
+func writeGeneRefs(w io.Writer, title string, refs []GeneRef) {
+	if len(refs) == 0 {
+		return
+	}
+	fmt.Fprintln(w, title)
+	for _, ref := range refs {
+		fmt.Fprintf(w, "\tINNOV %4d (%3d -> %3d) weight: % .3f enabled: %t\n",
+			ref.InnovationNum, ref.InNodeId, ref.OutNodeId, ref.Weight, ref.Enabled)
+	}
+}
+
+func equalParams(p1, p2 []float64) bool {
+	if len(p1) != len(p2) {
+		return false
+	}
+	for i := range p1 {
+		if p1[i] != p2[i] {
+			return false
+		}
+	}
+	return true
+}
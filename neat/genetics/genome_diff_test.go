@@ -0,0 +1,84 @@
+package genetics
+
+import (
+	"testing"
+	"strings"
+	"bytes"
+	"encoding/json"
+	"github.com/yaricom/goNEAT/neat"
+	"github.com/yaricom/goNEAT/neat/network"
+)
+
+func TestGenome_Diff(t *testing.T) {
+	gnome1 := buildTestGenome(1)
+	gnome2 := buildTestGenome(2)
+
+	// the same genomes
+	diff := gnome1.Diff(gnome2, nil)
+	if !diff.IsEmpty() {
+		t.Error("Empty diff expected for the same genomes", diff)
+	}
+
+	// change weight, disable gene, add node and gene, change trait
+	gnome2.Genes[1].Link.Weight = 3.0
+	gnome2.Genes[1].MutationNum = 0.5
+	gnome2.Genes[2].IsEnabled = false
+	node := network.NewNNode(5, network.HiddenNeuron)
+	gnome2.Nodes = append(gnome2.Nodes, node)
+	gnome2.Genes = append(gnome2.Genes, NewGene(1.0, gnome2.Nodes[0], node, false, 5, 0))
+	gnome2.Traits[0].Params[1] = 0.5
+	// add gene with innovation in the middle to the first genome
+	gnome1.Genes = append(gnome1.Genes, NewGene(1.0, gnome1.Nodes[1], gnome1.Nodes[3], false, 4, 0))
+
+	context := neat.NeatContext{DisjointCoeff:1.0, ExcessCoeff:1.0, MutdiffCoeff:0.4, CompatThreshold:3.0}
+	diff = gnome1.Diff(gnome2, &context)
+	if diff.IsEmpty() {
+		t.Error("Not empty diff expected")
+	}
+	if len(diff.Matching) != 3 {
+		t.Error("Wrong number of matching genes", 3, len(diff.Matching))
+	}
+	if diff.Matching[1].WeightDelta != 0.5 || diff.Matching[1].MutationDiff != 0.5 {
+		t.Error("Wrong matching gene difference", diff.Matching[1])
+	}
+	if !diff.Matching[2].Enabled1 || diff.Matching[2].Enabled2 {
+		t.Error("Wrong enabled flags", diff.Matching[2])
+	}
+	if len(diff.Disjoint1) != 1 || diff.Disjoint1[0].InnovationNum != 4 {
+		t.Error("Wrong disjoint genes of first genome", diff.Disjoint1)
+	}
+	if len(diff.Excess2) != 1 || diff.Excess2[0].InnovationNum != 5 {
+		t.Error("Wrong excess genes of second genome", diff.Excess2)
+	}
+	if len(diff.Disjoint2) != 0 || len(diff.Excess1) != 0 {
+		t.Error("Unexpected disjoint/excess genes", diff.Disjoint2, diff.Excess1)
+	}
+	if len(diff.NodesAdded2) != 1 || diff.NodesAdded2[0] != 5 || len(diff.NodesAdded1) != 0 {
+		t.Error("Wrong added nodes", diff.NodesAdded1, diff.NodesAdded2)
+	}
+	if len(diff.Traits) != 1 || diff.Traits[0].TraitId != 1 {
+		t.Error("Wrong traits difference", diff.Traits)
+	}
+	if diff.Compatibility != gnome1.compatibility(gnome2, &context) {
+		t.Error("Wrong compatibility", gnome1.compatibility(gnome2, &context), diff.Compatibility)
+	}
+
+	// check text output
+	text := diff.String()
+	if !strings.Contains(text, "Matching genes: 3, disjoint: 1/0, excess: 0/1") {
+		t.Error("Wrong text output", text)
+	}
+
+	// check JSON output
+	out_buf := bytes.NewBufferString("")
+	if err := diff.WriteJSON(out_buf); err != nil {
+		t.Error(err)
+	}
+	var ddiff GenomeDiff
+	if err := json.Unmarshal(out_buf.Bytes(), &ddiff); err != nil {
+		t.Error(err)
+	}
+	if len(ddiff.Matching) != 3 || ddiff.Compatibility != diff.Compatibility {
+		t.Error("Wrong diff decoded from JSON", ddiff)
+	}
+}
@@ -570,6 +570,121 @@ func TestGenome_mutateLinkWeights(t *testing.T) {
 	}
 }
 
+func TestGenome_mutateNodeBias(t *testing.T) {
+	rand.Seed(42)
+	gnome1 := buildTestGenome(1)
+
+	res, err := gnome1.mutateNodeBias(0.5, 1.0)
+	if !res || err != nil {
+		t.Error("Failed to mutate node bias")
+	}
+	for _, nd := range gnome1.Nodes {
+		if nd.IsSensor() && nd.Bias != 0 {
+			t.Error("Sensor bias should not be mutated", nd)
+		} else if nd.IsNeuron() && nd.Bias == 0 {
+			t.Error("Found not mutated neuron bias", nd)
+		}
+	}
+
+	// check that bias propagated to the phenotype
+	net := gnome1.genesis(1)
+	for i, nd := range net.AllNodes() {
+		if nd.Bias != gnome1.Nodes[i].Bias {
+			t.Error("Bias was not propagated into phenotype", gnome1.Nodes[i].Bias, nd.Bias)
+		}
+	}
+}
+
+func TestGenome_mutateTimeConstants(t *testing.T) {
+	rand.Seed(42)
+	gnome1 := buildTestGenome(1)
+
+	res, err := gnome1.mutateTimeConstants(5.0, 1.0, 0.1, 2.0)
+	if !res || err != nil {
+		t.Error("Failed to mutate time constants", err)
+	}
+	for _, nd := range gnome1.Nodes {
+		if nd.IsSensor() && nd.TimeConstant != network.DefaultTimeConstant {
+			t.Error("Sensor time constant should not be mutated", nd)
+		} else if nd.TimeConstant < 0.1 || nd.TimeConstant > 2.0 {
+			t.Error("Time constant out of range", nd.TimeConstant)
+		}
+	}
+
+	// check wrong range
+	if _, err = gnome1.mutateTimeConstants(5.0, 1.0, 0.0, 2.0); err == nil {
+		t.Error("Error expected for zero min time constant")
+	}
+}
+
+func TestGenome_Simplify(t *testing.T) {
+	gnome_str := "genomestart 1\n" +
+		"trait 1 0.1 0 0 0 0 0 0 0\n" +
+		"node 1 0 1 1\n" +
+		"node 2 0 1 1\n" +
+		"node 3 0 1 3\n" +
+		"node 4 0 0 2\n" +
+		"node 5 0 0 0\n" +
+		"node 6 0 0 0\n" +
+		"node 7 0 0 0\n" +
+		"node 8 0 0 0\n" +
+		"node 9 0 0 0\n" +
+		"gene 1 1 5 1.5 false 1 0 true\n" +
+		"gene 1 2 5 -2.0 false 2 0 true\n" +
+		"gene 1 5 6 0.7 false 3 0 true\n" +
+		"gene 1 6 5 0.3 true 4 0 true\n" +
+		"gene 1 6 4 1.2 false 5 0 true\n" +
+		"gene 1 3 4 -0.5 false 6 0 true\n" +
+		"gene 1 1 8 1.0 false 7 0 true\n" +
+		"gene 1 2 4 1.0 false 8 0 false\n" +
+		"gene 1 1 4 0.0001 false 9 0 true\n" +
+		"gene 1 9 4 3.0 false 10 0 true\n" +
+		"genomeend 1"
+	gnome, err := ReadGenome(strings.NewReader(gnome_str), 1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	inputs := [][]float64{{0, 0, 1}, {0, 1, 1}, {1, 0, 1}, {1, 1, 1}}
+
+	simple, err := gnome.Simplify(0.001, inputs, 0.001)
+	if err != nil {
+		t.Error("Failed to simplify genome", err)
+		return
+	}
+	if len(simple.Nodes) != 6 {
+		t.Error("Wrong number of nodes after simplification", 6, len(simple.Nodes))
+	}
+	for _, nd := range simple.Nodes {
+		if nd.Id > 6 {
+			t.Error("Dead node was not removed", nd)
+		}
+	}
+	if len(simple.Genes) != 6 {
+		t.Error("Wrong number of genes after simplification", 6, len(simple.Genes))
+	}
+	for _, gn := range simple.Genes {
+		if gn.InnovationNum > 6 {
+			t.Error("Dead gene was not removed", gn)
+		}
+	}
+	// check that original genome is intact
+	if len(gnome.Nodes) != 9 || len(gnome.Genes) != 10 {
+		t.Error("Original genome was changed", len(gnome.Nodes), len(gnome.Genes))
+	}
+	// check that simplified genome can be written and read back
+	out_buf := bytes.NewBufferString("")
+	simple.Write(out_buf)
+	if _, err = ReadGenome(strings.NewReader(out_buf.String()), 1); err != nil {
+		t.Error("Failed to read simplified genome", err)
+	}
+
+	// the removal of significant links should be detected
+	if _, err = gnome.Simplify(1.0, inputs, 0.001); err == nil {
+		t.Error("Error expected when outputs of simplified genome changed")
+	}
+}
+
 func TestGenome_mutateRandomTrait(t *testing.T) {
 	rand.Seed(42)
 	gnome1 := buildTestGenome(1)
@@ -656,7 +771,7 @@ func TestGenome_mutateToggleEnable(t *testing.T) {
 	gnome1.Genes = append(gnome1.Genes, ReadGene(strings.NewReader("3 3 4 5.5 false 4 0 true"),
 		gnome1.Traits, gnome1.Nodes))
 
-	res, err := gnome1.mutateToggleEnable(5)
+	res, err := gnome1.mutateToggleEnable(5, &neat.Constraints{})
 	if !res || err != nil {
 		t.Error("Failed to mutate toggle genes")
 	}
@@ -678,7 +793,7 @@ func TestGenome_mutateGeneReenable(t *testing.T) {
 		gnome1.Traits, gnome1.Nodes))
 
 	gnome1.Genes[1].IsEnabled = false
-	res, err := gnome1.mutateGeneReenable()
+	res, err := gnome1.mutateGeneReenable(&neat.Constraints{})
 	if !res || err != nil {
 		t.Error("Failed to mutate toggle genes")
 	}
@@ -698,7 +813,7 @@ func TestGenome_mateMultipoint(t *testing.T) {
 	genomeid := 3
 	fitness1, fitness2 := 1.0, 2.3
 
-	gnome_child, err := gnome1.mateMultipoint(gnome2, genomeid, fitness1, fitness2)
+	gnome_child, err := gnome1.mateMultipoint(gnome2, genomeid, fitness1, fitness2, &neat.NeatContext{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -720,7 +835,7 @@ func TestGenome_mateMultipoint(t *testing.T) {
 	gnome1.Genes = append(gnome1.Genes, ReadGene(strings.NewReader("3 3 4 5.5 false 4 0 false"),
 		gnome1.Traits, gnome1.Nodes))
 	fitness1, fitness2 = 15.0, 2.3
-	gnome_child, err = gnome1.mateMultipoint(gnome2, genomeid, fitness1, fitness2)
+	gnome_child, err = gnome1.mateMultipoint(gnome2, genomeid, fitness1, fitness2, &neat.NeatContext{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -745,7 +860,7 @@ func TestGenome_mateMultipointAvg(t *testing.T) {
 
 	genomeid := 3
 	fitness1, fitness2 := 1.0, 2.3
-	gnome_child, err := gnome1.mateMultipointAvg(gnome2, genomeid, fitness1, fitness2)
+	gnome_child, err := gnome1.mateMultipointAvg(gnome2, genomeid, fitness1, fitness2, &neat.NeatContext{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -768,7 +883,7 @@ func TestGenome_mateMultipointAvg(t *testing.T) {
 	gnome2.Genes = append(gnome2.Genes, ReadGene(strings.NewReader("3 2 4 5.5 true 4 0 false"),
 		gnome1.Traits, gnome1.Nodes))
 	fitness1, fitness2 = 15.0, 2.3
-	gnome_child, err = gnome1.mateMultipointAvg(gnome2, genomeid, fitness1, fitness2)
+	gnome_child, err = gnome1.mateMultipointAvg(gnome2, genomeid, fitness1, fitness2, &neat.NeatContext{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -792,7 +907,7 @@ func TestGenome_mateSinglepoint(t *testing.T) {
 	gnome2 := buildTestGenome(2)
 
 	genomeid := 3
-	gnome_child, err := gnome1.mateSinglepoint(gnome2, genomeid)
+	gnome_child, err := gnome1.mateSinglepoint(gnome2, genomeid, &neat.NeatContext{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -812,7 +927,7 @@ func TestGenome_mateSinglepoint(t *testing.T) {
 	// check not equal gene pools
 	gnome1.Genes = append(gnome1.Genes, ReadGene(strings.NewReader("3 3 4 5.5 false 4 0 false"),
 		gnome1.Traits, gnome1.Nodes))
-	gnome_child, err = gnome1.mateSinglepoint(gnome2, genomeid)
+	gnome_child, err = gnome1.mateSinglepoint(gnome2, genomeid, &neat.NeatContext{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -833,7 +948,7 @@ func TestGenome_mateSinglepoint(t *testing.T) {
 		gnome1.Traits, gnome1.Nodes))
 	gnome2.Genes = append(gnome2.Genes, ReadGene(strings.NewReader("3 2 4 5.5 true 4 0 false"),
 		gnome1.Traits, gnome1.Nodes))
-	gnome_child, err = gnome1.mateSinglepoint(gnome2, genomeid)
+	gnome_child, err = gnome1.mateSinglepoint(gnome2, genomeid, &neat.NeatContext{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -0,0 +1,88 @@
+package genetics
+
+import "sort"
+
+// The member of hall of fame
+type HallOfFameMember struct {
+	// The copy of genome of the famous organism
+	Genome     *Genome
+	// The fitness of the famous organism
+	Fitness    float64
+	// The generation where organism was evaluated
+	Generation int
+	// The ID of species of the famous organism
+	SpeciesId  int
+}
+
+// The hall of fame keeping genomes of the most fit organisms found during evolution. It outlives populations, thus
+// stagnated population can be reseeded from the best genomes found so far.
+type HallOfFame struct {
+	// The maximal number of members
+	Size    int
+	// The members ordered by fitness, the most fit first
+	Members []*HallOfFameMember
+}
+
+// Creates new empty hall of fame with given maximal number of members
+func NewHallOfFame(size int) *HallOfFame {
+	return &HallOfFame{
+		Size:size,
+		Members:make([]*HallOfFameMember, 0, size),
+	}
+}
+
+// Considers the champion of each species of evaluated population to be added to the hall of fame. The hall keeps at
+// most one member per species to stay diverse, thus the champion replaces the member of its species if more fit.
+// Otherwise, it replaces the least fit member if the hall is full and the champion is more fit.
+func (h *HallOfFame) Update(pop *Population, generation int) {
+	for _, sp := range pop.Species {
+		var champion *Organism
+		for _, org := range sp.Organisms {
+			if champion == nil || org.Fitness > champion.Fitness {
+				champion = org
+			}
+		}
+		if champion == nil {
+			continue
+		}
+		if i := h.indexOfSpecies(sp.Id); i >= 0 {
+			if champion.Fitness <= h.Members[i].Fitness {
+				continue
+			}
+			h.Members = append(h.Members[:i], h.Members[i + 1:]...)
+		} else if len(h.Members) >= h.Size {
+			if h.Size <= 0 || champion.Fitness <= h.Members[len(h.Members) - 1].Fitness {
+				continue
+			}
+			h.Members = h.Members[:len(h.Members) - 1]
+		}
+		h.Members = append(h.Members, &HallOfFameMember{
+			Genome:champion.Genotype.duplicate(champion.Genotype.Id),
+			Fitness:champion.Fitness,
+			Generation:generation,
+			SpeciesId:sp.Id,
+		})
+		sort.SliceStable(h.Members, func(i, j int) bool {
+			return h.Members[i].Fitness > h.Members[j].Fitness
+		})
+	}
+}
+
+// Returns genomes of members ordered by fitness, the most fit first
+func (h *HallOfFame) Genomes() []*Genome {
+	genomes := make([]*Genome, len(h.Members))
+	for i, m := range h.Members {
+		genomes[i] = m.Genome
+	}
+	return genomes
+}
+
+// Returns index of the member from species with given ID or -1 if not found
+func (h *HallOfFame) indexOfSpecies(species_id int) int {
+	for i, m := range h.Members {
+		if m.SpeciesId == species_id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,60 @@
+package genetics
+
+import (
+	"testing"
+	"github.com/yaricom/goNEAT/neat"
+)
+
+func TestHallOfFame_Update(t *testing.T) {
+	conf := neat.NeatContext{
+		CompatThreshold:0.5,
+		PopSize:6,
+	}
+	pop, err := NewPopulation(buildTestGenome(1), &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// put organisms into three species with known champions
+	pop.Species = make([]*Species, 0)
+	for i := 0; i < 3; i++ {
+		sp := NewSpecies(i + 1)
+		for j := 0; j < 2; j++ {
+			org := pop.Organisms[i * 2 + j]
+			org.Fitness = float64(i * 10 + j)
+			sp.addOrganism(org)
+		}
+		pop.Species = append(pop.Species, sp)
+	}
+
+	hof := NewHallOfFame(2)
+	hof.Update(pop, 0)
+	if len(hof.Members) != 2 {
+		t.Fatal("Wrong number of members", len(hof.Members))
+	}
+	if hof.Members[0].Fitness != 21 || hof.Members[1].Fitness != 11 {
+		t.Error("Wrong members", hof.Members[0].Fitness, hof.Members[1].Fitness)
+	}
+	if hof.Members[0].Genome == pop.Organisms[5].Genotype {
+		t.Error("Member genome should be copied")
+	}
+
+	// the champion of the first species evicts the least fit member, while the others are kept
+	pop.Organisms[0].Fitness = 15
+	hof.Update(pop, 1)
+	if hof.Members[0].Fitness != 21 || hof.Members[0].Generation != 0 || hof.Members[0].SpeciesId != 3 {
+		t.Error("Wrong the best member after update", hof.Members[0])
+	}
+	if hof.Members[1].Fitness != 15 || hof.Members[1].Generation != 1 || hof.Members[1].SpeciesId != 1 {
+		t.Error("Wrong the second member after update", hof.Members[1])
+	}
+
+	// the improved champion of species replaces its member
+	pop.Organisms[0].Fitness = 16
+	hof.Update(pop, 2)
+	if len(hof.Members) != 2 || hof.Members[1].Fitness != 16 || hof.Members[1].Generation != 2 {
+		t.Error("Wrong member of improved species", hof.Members[1])
+	}
+	if genomes := hof.Genomes(); len(genomes) != 2 || genomes[0] != hof.Members[0].Genome {
+		t.Error("Wrong genomes", genomes)
+	}
+}
@@ -0,0 +1,106 @@
+package genetics
+
+import (
+	"fmt"
+	"sort"
+	"errors"
+	"github.com/yaricom/goNEAT/neat"
+)
+
+// Returns the innovation number to be assigned to the next new gene in this population
+func (p *Population) NextInnovationNumber() int64 {
+	return p.currInnovNum
+}
+
+// Returns the ID to be assigned to the next new node in this population
+func (p *Population) NextNodeId() int {
+	return p.currNodeId
+}
+
+// Advances the innovation number and node ID counters of this population to be not less than given values. It
+// allows several populations exchanging organisms (e.g. islands) to keep innovation numbers and node IDs unique among
+// all of them.
+func (p *Population) AdvanceCounters(innovation int64, nodeId int) {
+	if p.currInnovNum < innovation {
+		p.currInnovNum = innovation
+	}
+	if p.currNodeId < nodeId {
+		p.currNodeId = nodeId
+	}
+}
+
+// Returns the given number of the most fit organisms of this population ordered by fitness, the best first
+func (p *Population) Elite(count int) []*Organism {
+	sorted := make(Organisms, len(p.Organisms))
+	copy(sorted, p.Organisms)
+	sort.Sort(sort.Reverse(sorted))
+	if count > len(sorted) {
+		count = len(sorted)
+	}
+	return sorted[:count]
+}
+
+// Replaces the worst organisms of this population with copies of the migrants from other population. The copies keep
+// fitness of the migrants and are placed into the most compatible species or into the new ones. The method should be
+// invoked for the evaluated population before Epoch, thus the migrants will take part in the reproduction. The
+// innovation numbers and node IDs of the migrants should be reconciled with this population beforehand (see
+// AdvanceCounters).
+func (p *Population) Immigrate(migrants []*Organism, generation int, context *neat.NeatContext) error {
+	if len(migrants) >= len(p.Organisms) {
+		return errors.New(fmt.Sprintf("POPULATION: Too many migrants: %d, population size: %d",
+			len(migrants), len(p.Organisms)))
+	}
+	sorted := make(Organisms, len(p.Organisms))
+	copy(sorted, p.Organisms)
+	sort.Sort(sorted)
+
+	for i, m := range migrants {
+		// replace the worst organism keeping its genome ID to avoid duplicates
+		worst := sorted[i]
+		if err := p.removeOrganism(worst); err != nil {
+			return err
+		}
+		genome := m.Genotype.duplicateWithEnabledFlags()
+		genome.Id = worst.Genotype.Id
+		org := NewOrganism(m.Fitness, genome, m.Generation)
+		org.Error = m.Error
+		org.Ancestry = &Ancestry{
+			BirthGeneration:generation,
+			ParentIds:make([]int, 0),
+			ParentFitness:make([]float64, 0),
+			Mutation:MigrationOperator,
+		}
+		p.Organisms = append(p.Organisms, org)
+		if err := addToSpecies(p, org, nil, context); err != nil {
+			return err
+		}
+		neat.DebugLog(fmt.Sprintf("POPULATION: Migrant organism [%d] with fitness %f replaced organism with fitness %f",
+			org.Genotype.Id, org.Fitness, worst.Fitness))
+	}
+
+	// remove species left without organisms
+	species_to_keep := make([]*Species, 0, len(p.Species))
+	for _, sp := range p.Species {
+		if len(sp.Organisms) > 0 {
+			species_to_keep = append(species_to_keep, sp)
+		}
+	}
+	p.Species = species_to_keep
+	return nil
+}
+
+// Removes organism from population and its species
+func (p *Population) removeOrganism(org *Organism) error {
+	if org.Species != nil {
+		if _, err := org.Species.removeOrganism(org); err != nil {
+			return err
+		}
+	}
+	for i, o := range p.Organisms {
+		if o == org {
+			p.Organisms = append(p.Organisms[:i], p.Organisms[i + 1:]...)
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("POPULATION: Attempt to remove nonexistent organism [%d]", org.Genotype.Id))
+}
@@ -0,0 +1,100 @@
+package genetics
+
+import (
+	"testing"
+	"math/rand"
+	"github.com/yaricom/goNEAT/neat"
+)
+
+func TestPopulation_Immigrate(t *testing.T) {
+	rand.Seed(42)
+	conf := neat.NeatContext{
+		CompatThreshold:0.5,
+		PopSize:10,
+	}
+	source, err := NewPopulation(buildTestGenome(1), &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := NewPopulation(buildTestGenome(1), &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, org := range source.Organisms {
+		org.Fitness = float64(100 + i)
+	}
+	for i, org := range target.Organisms {
+		org.Fitness = float64(i)
+	}
+
+	elite := source.Elite(3)
+	if len(elite) != 3 || elite[0].Fitness != 109 || elite[2].Fitness != 107 {
+		t.Fatal("Wrong elite organisms", elite)
+	}
+
+	err = target.Immigrate(elite, 5, &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(target.Organisms) != conf.PopSize {
+		t.Error("Wrong population size", len(target.Organisms))
+	}
+	migrants, species_orgs := 0, 0
+	ids := make(map[int]bool)
+	for _, org := range target.Organisms {
+		if org.Fitness < 3 {
+			t.Error("The worst organism was not replaced", org.Fitness)
+		}
+		if org.Ancestry != nil && org.Ancestry.Mutation == MigrationOperator {
+			migrants++
+			if org.Fitness < 107 || org.Ancestry.BirthGeneration != 5 {
+				t.Error("Wrong migrant", org.Fitness, org.Ancestry)
+			}
+			if org.Species == nil || org.Phenotype == nil {
+				t.Error("Migrant is not ready for reproduction")
+			}
+		}
+		if ids[org.Genotype.Id] {
+			t.Error("Duplicate genome ID", org.Genotype.Id)
+		}
+		ids[org.Genotype.Id] = true
+	}
+	for _, sp := range target.Species {
+		if len(sp.Organisms) == 0 {
+			t.Error("Empty species left in population", sp.Id)
+		}
+		species_orgs += len(sp.Organisms)
+	}
+	if migrants != 3 || species_orgs != conf.PopSize {
+		t.Error("Wrong number of migrants or species members", migrants, species_orgs)
+	}
+
+	// the source organisms should not be affected
+	if elite[0].Ancestry.Mutation != InitialOperator {
+		t.Error("Source organism changed", elite[0].Ancestry)
+	}
+
+	if err = target.Immigrate(source.Organisms, 5, &conf); err == nil {
+		t.Error("Error expected when migrants outnumber population")
+	}
+}
+
+func TestPopulation_AdvanceCounters(t *testing.T) {
+	conf := neat.NeatContext{
+		CompatThreshold:0.5,
+		PopSize:10,
+	}
+	pop, err := NewPopulation(buildTestGenome(1), &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	innov, node_id := pop.NextInnovationNumber(), pop.NextNodeId()
+	pop.AdvanceCounters(innov - 1, node_id - 1)
+	if pop.NextInnovationNumber() != innov || pop.NextNodeId() != node_id {
+		t.Error("Counters should not go back")
+	}
+	pop.AdvanceCounters(innov + 10, node_id + 5)
+	if pop.NextInnovationNumber() != innov + 10 || pop.NextNodeId() != node_id + 5 {
+		t.Error("Counters not advanced", pop.NextInnovationNumber(), pop.NextNodeId())
+	}
+}
@@ -0,0 +1,214 @@
+package genetics
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"github.com/yaricom/goNEAT/neat"
+)
+
+// The default number of generations after DropOffAge of population stagnation when delta coding performed
+const defaultDeltaCodingWindow = 5
+
+// The default share of population given to the best species by delta coding
+const defaultDeltaCodingSplit = 0.5
+
+// The default shares of stolen babies given to the top three species
+var defaultBabiesStolenShares = [3]float64{0.2, 0.2, 0.1}
+
+// The default maximal number of stolen babies given to each species after the top three
+const defaultBabiesStolenBlock = 3
+
+// The type of offspring reallocation event
+type OffspringEventType string
+
+// The types of offspring reallocation events
+const (
+	// The population stagnated and its offspring was split between the best species
+	DeltaCodingEvent OffspringEventType = "delta_coding"
+	// The offspring was taken away from the worst species and given to the champions of the best ones
+	BabyStealingEvent OffspringEventType = "baby_stealing"
+)
+
+// The event of reallocation of expected offspring among species during epoch
+type OffspringEvent struct {
+	// The type of event
+	Type       OffspringEventType `json:"type"`
+	// The generation produced by the epoch where event happened
+	Generation int                `json:"generation"`
+	// The number of expected offspring reallocated
+	Offspring  int                `json:"offspring"`
+	// The IDs of species received reallocated offspring
+	Species    []int              `json:"species"`
+	// The number of offspring received by each species
+	Received   []int              `json:"received"`
+}
+
+func (e *OffspringEvent) String() string {
+	return fmt.Sprintf("%s in generation %d: %d offspring given to species %v as %v", e.Type, e.Generation,
+		e.Offspring, e.Species, e.Received)
+}
+
+// Records that species received given number of reallocated offspring
+func (e *OffspringEvent) received(sp *Species, offspring int) {
+	for i, id := range e.Species {
+		if id == sp.Id {
+			e.Received[i] += offspring
+			return
+		}
+	}
+	e.Species = append(e.Species, sp.Id)
+	e.Received = append(e.Received, offspring)
+}
+
+// The policy to reallocate the expected offspring among species after it was computed from the shared fitness
+type OffspringAllocator interface {
+	// Reallocates the expected offspring of population species, which are sorted by original fitness of their
+	// champions, the best first. The given generation is the one to be produced. Returns events describing the
+	// reallocation done, if any.
+	Allocate(pop *Population, sorted_species []*Species, generation int, context *neat.NeatContext) []*OffspringEvent
+}
+
+// The standard NEAT offspring allocator. When population stagnated for DropOffAge plus DeltaCodingWindow generations,
+// it performs delta coding: the offspring is split between two the best species. Otherwise, it steals BabiesStolen
+// offspring from the worst species and gives them to the champions of the best species in blocks.
+type StandardOffspringAllocator struct{}
+
+func (a StandardOffspringAllocator) Allocate(pop *Population, sorted_species []*Species, generation int, context *neat.NeatContext) []*OffspringEvent {
+	window := context.DeltaCodingWindow
+	if window == 0 {
+		window = defaultDeltaCodingWindow
+	}
+	if window > 0 && pop.HighestLastChanged >= context.DropOffAge + window {
+		pop.HighestLastChanged = 0
+		return []*OffspringEvent{a.deltaCoding(sorted_species, generation, context)}
+	} else if context.BabiesStolen > 0 {
+		if event := a.stealBabies(sorted_species, generation, context); event != nil {
+			return []*OffspringEvent{event}
+		}
+	}
+	return nil
+}
+
+// Assigns the whole offspring of population to the champions of two the best species
+func (a StandardOffspringAllocator) deltaCoding(sorted_species []*Species, generation int, context *neat.NeatContext) *OffspringEvent {
+	neat.DebugLog("POPULATION: PERFORMING DELTA CODING")
+	event := &OffspringEvent{Type:DeltaCodingEvent, Generation:generation, Offspring:context.PopSize}
+
+	split := context.DeltaCodingSplit
+	if split <= 0 || split > 1 {
+		split = defaultDeltaCodingSplit
+	}
+	first_part := int(math.Floor(float64(context.PopSize) * split + 1e-9))
+	neat.DebugLog(fmt.Sprintf("first_part: [%d] (pop_size - first_part): [%d]\n",
+		first_part, context.PopSize - first_part))
+
+	if len(sorted_species) > 1 {
+		// Assign population to first two species
+		assignSuperChampOffspring(sorted_species[0], first_part, event)
+		// NOTE: PopSize can be odd. That's why we use subtraction below
+		assignSuperChampOffspring(sorted_species[1], context.PopSize - first_part, event)
+
+		// Get rid of all species after the first two
+		for i := 2; i < len(sorted_species); i++ {
+			sorted_species[i].ExpectedOffspring = 0
+		}
+	} else {
+		assignSuperChampOffspring(sorted_species[0], context.PopSize, event)
+	}
+	return event
+}
+
+// Sets the expected offspring of species to be produced by its champion and resets its stagnation
+func assignSuperChampOffspring(sp *Species, offspring int, event *OffspringEvent) {
+	sp.Organisms[0].superChampOffspring = offspring
+	sp.ExpectedOffspring = offspring
+	sp.AgeOfLastImprovement = sp.Age
+	event.received(sp, offspring)
+}
+
+// Takes away offspring from the worst species and gives it to the best ones. Returns nil if nothing was stolen.
+func (a StandardOffspringAllocator) stealBabies(sorted_species []*Species, generation int, context *neat.NeatContext) *OffspringEvent {
+	// STOLEN BABIES: The system can take expected offspring away from worse species and give them
+	// to superior species depending on the system parameter BabiesStolen (when BabiesStolen > 0)
+	stolen_babies := 0 // Babies taken from the bad species and given to the champs
+
+	// Take away a constant number of expected offspring from the worst few species
+	for i := len(sorted_species) - 1; i >= 0 && stolen_babies < context.BabiesStolen; i-- {
+		curr_species := sorted_species[i]
+		if curr_species.Age > 5 && curr_species.ExpectedOffspring > 2 {
+			if curr_species.ExpectedOffspring - 1 >= context.BabiesStolen - stolen_babies {
+				// This species has enough to finish off the stolen pool
+				curr_species.ExpectedOffspring -= context.BabiesStolen - stolen_babies
+				stolen_babies = context.BabiesStolen
+			} else {
+				// Not enough here to complete the pool of stolen
+				stolen_babies += curr_species.ExpectedOffspring - 1
+				curr_species.ExpectedOffspring = 1
+			}
+		}
+	}
+
+	neat.DebugLog(fmt.Sprintf("POPULATION: STOLEN BABIES: %d\n", stolen_babies))
+	if stolen_babies == 0 {
+		return nil
+	}
+	event := &OffspringEvent{Type:BabyStealingEvent, Generation:generation, Offspring:stolen_babies}
+
+	// Mark the best champions of the top species to be the super champs who will take on the extra
+	// offspring for cloning or mutant cloning.
+	// Determine the exact number that will be given to the top three according to their shares of
+	// the stolen babies, 1/5 1/5 and 1/10 by default. The zero share gives nothing to the champion.
+	stolen_blocks := make([]int, len(defaultBabiesStolenShares))
+	for i, share := range context.BabiesStolenShares {
+		if share < 0 {
+			share = defaultBabiesStolenShares[i]
+		}
+		stolen_blocks[i] = int(math.Floor(float64(context.BabiesStolen) * share + 1e-9))
+	}
+	rest_block := context.BabiesStolenBlock
+	if rest_block <= 0 {
+		rest_block = defaultBabiesStolenBlock
+	}
+	block_index := 0
+	for _, curr_species := range sorted_species {
+		if curr_species.lastImproved() > context.DropOffAge {
+			// Don't give a chance to dying species even if they are champs
+			continue
+		}
+
+		if block_index < len(stolen_blocks) && stolen_blocks[block_index] > 0 && stolen_babies >= stolen_blocks[block_index] {
+			// Give stolen babies to the top three in their shares
+			curr_species.Organisms[0].superChampOffspring = stolen_blocks[block_index]
+			curr_species.ExpectedOffspring += stolen_blocks[block_index]
+			stolen_babies -= stolen_blocks[block_index]
+			event.received(curr_species, stolen_blocks[block_index])
+		} else if block_index >= len(stolen_blocks) {
+			// Give stolen to the rest in random ratios
+			if rand.Float64() > 0.1 {
+				// Randomize a little which species get boosted by a super champ
+				given := stolen_babies
+				if given > rest_block {
+					given = rest_block
+				}
+				curr_species.Organisms[0].superChampOffspring = given
+				curr_species.ExpectedOffspring += given
+				stolen_babies -= given
+				event.received(curr_species, given)
+			}
+		}
+
+		if stolen_babies <= 0 {
+			break
+		}
+		block_index++
+	}
+	// If any stolen babies aren't taken, give them to species #1's champ
+	if stolen_babies > 0 {
+		curr_species := sorted_species[0]
+		curr_species.Organisms[0].superChampOffspring += stolen_babies
+		curr_species.ExpectedOffspring += stolen_babies
+		event.received(curr_species, stolen_babies)
+	}
+	return event
+}
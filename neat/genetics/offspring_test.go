@@ -0,0 +1,110 @@
+package genetics
+
+import (
+	"testing"
+	"reflect"
+	"github.com/yaricom/goNEAT/neat"
+)
+
+// Builds species sorted by fitness with given expected offspring each, the best first
+func buildSortedSpecies(expected_offspring ...int) []*Species {
+	sorted_species := make([]*Species, len(expected_offspring))
+	for i, offspring := range expected_offspring {
+		sp := buildSpeciesWithOrganisms(i + 1)
+		sp.Age, sp.AgeOfLastImprovement = 10, 10
+		sp.ExpectedOffspring = offspring
+		sorted_species[i] = sp
+	}
+	return sorted_species
+}
+
+func TestStandardOffspringAllocator_DeltaCoding(t *testing.T) {
+	context := &neat.NeatContext{PopSize:10, DropOffAge:15, DeltaCodingSplit:0.7}
+	pop := &Population{HighestLastChanged:20}
+	sorted_species := buildSortedSpecies(4, 3, 3)
+	sorted_species[1].AgeOfLastImprovement = 2
+
+	events := StandardOffspringAllocator{}.Allocate(pop, sorted_species, 7, context)
+	if len(events) != 1 {
+		t.Fatal("Delta coding should be performed", events)
+	}
+	expected := &OffspringEvent{Type:DeltaCodingEvent, Generation:7, Offspring:10,
+		Species:[]int{1, 2}, Received:[]int{7, 3}}
+	if !reflect.DeepEqual(events[0], expected) {
+		t.Error("Wrong delta coding event", events[0])
+	}
+	if pop.HighestLastChanged != 0 {
+		t.Error("Population stagnation should be reset", pop.HighestLastChanged)
+	}
+	for i, offspring := range []int{7, 3, 0} {
+		if sorted_species[i].ExpectedOffspring != offspring {
+			t.Error("Wrong expected offspring", i, sorted_species[i].ExpectedOffspring)
+		}
+	}
+	if sorted_species[0].Organisms[0].superChampOffspring != 7 || sorted_species[1].lastImproved() != 0 {
+		t.Error("Wrong super champion", sorted_species[0].Organisms[0].superChampOffspring, sorted_species[1].lastImproved())
+	}
+}
+
+func TestStandardOffspringAllocator_DeltaCodingDisabled(t *testing.T) {
+	context := &neat.NeatContext{PopSize:10, DropOffAge:15, DeltaCodingWindow:-1}
+	pop := &Population{HighestLastChanged:100}
+	sorted_species := buildSortedSpecies(4, 3, 3)
+
+	if events := (StandardOffspringAllocator{}).Allocate(pop, sorted_species, 7, context); events != nil {
+		t.Error("Offspring should not be reallocated", events)
+	}
+	if pop.HighestLastChanged != 100 {
+		t.Error("Population stagnation should not be reset", pop.HighestLastChanged)
+	}
+	for i, offspring := range []int{4, 3, 3} {
+		if sorted_species[i].ExpectedOffspring != offspring {
+			t.Error("Wrong expected offspring", i, sorted_species[i].ExpectedOffspring)
+		}
+	}
+}
+
+func TestStandardOffspringAllocator_BabyStealing(t *testing.T) {
+	context := &neat.NeatContext{PopSize:30, DropOffAge:15, BabiesStolen:10,
+		BabiesStolenShares:[3]float64{0.5, 0.3, 0.2}}
+	pop := &Population{HighestLastChanged:2}
+	sorted_species := buildSortedSpecies(5, 5, 5, 15)
+
+	events := StandardOffspringAllocator{}.Allocate(pop, sorted_species, 3, context)
+	if len(events) != 1 {
+		t.Fatal("Babies should be stolen", events)
+	}
+	expected := &OffspringEvent{Type:BabyStealingEvent, Generation:3, Offspring:10,
+		Species:[]int{1, 2, 3}, Received:[]int{5, 3, 2}}
+	if !reflect.DeepEqual(events[0], expected) {
+		t.Error("Wrong baby stealing event", events[0])
+	}
+	for i, offspring := range []int{10, 8, 7, 5} {
+		if sorted_species[i].ExpectedOffspring != offspring {
+			t.Error("Wrong expected offspring", i, sorted_species[i].ExpectedOffspring)
+		}
+	}
+}
+
+func TestStandardOffspringAllocator_BabyStealingZeroShare(t *testing.T) {
+	// the champion of the best species given nothing
+	context := &neat.NeatContext{PopSize:30, DropOffAge:15, BabiesStolen:10,
+		BabiesStolenShares:[3]float64{0, 0.5, 0.5}}
+	pop := &Population{HighestLastChanged:2}
+	sorted_species := buildSortedSpecies(5, 5, 5, 15)
+
+	events := StandardOffspringAllocator{}.Allocate(pop, sorted_species, 3, context)
+	if len(events) != 1 {
+		t.Fatal("Babies should be stolen", events)
+	}
+	expected := &OffspringEvent{Type:BabyStealingEvent, Generation:3, Offspring:10,
+		Species:[]int{2, 3}, Received:[]int{5, 5}}
+	if !reflect.DeepEqual(events[0], expected) {
+		t.Error("Wrong baby stealing event", events[0])
+	}
+	for i, offspring := range []int{5, 10, 10, 5} {
+		if sorted_species[i].ExpectedOffspring != offspring {
+			t.Error("Wrong expected offspring", i, sorted_species[i].ExpectedOffspring)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package genetics
+
+import (
+	"fmt"
+	"github.com/yaricom/goNEAT/neat"
+)
+
+// The default parameters of reproduction operators probabilities adaptation
+const (
+	defaultAdaptiveOperatorsRate = 0.1
+	defaultAdaptiveOperatorsMin = 0.02
+)
+
+// The adapter of reproduction operators probabilities based on the adaptive pursuit method (D. Thierens, 2005). The
+// operators are grouped by the choices made during reproduction: mutation only vs. mating, the type of structural
+// mutation and the type of crossover. Within each group the probability of the operator with the best offspring
+// success rate is pursued towards the ceiling while probabilities of the others are pursued towards the floor.
+//
+// The adapter has no state of its own, the current probabilities are taken from the NEAT context and the adapted ones
+// are written back into it. The operators with zero probability in the context considered disabled and never adapted.
+type OperatorAdapter struct {
+	// The learning rate of the pursuit
+	Rate     float64
+	// The floor of each operator probability
+	MinProb  float64
+	// The ceiling of each operator probability, if zero the maximal one allowed by floor will be used
+	MaxProb  float64
+}
+
+// Creates new adapter with parameters from given context. The defaults are used for not set parameters.
+func NewOperatorAdapter(context *neat.NeatContext) *OperatorAdapter {
+	a := OperatorAdapter{
+		Rate:context.AdaptiveOperatorsRate,
+		MinProb:context.AdaptiveOperatorsMin,
+		MaxProb:context.AdaptiveOperatorsMax,
+	}
+	if a.Rate <= 0 {
+		a.Rate = defaultAdaptiveOperatorsRate
+	}
+	if a.MinProb <= 0 {
+		a.MinProb = defaultAdaptiveOperatorsMin
+	}
+	return &a
+}
+
+// Adapts the mutation and mating probabilities of the context according to the operators statistics of evaluated
+// organisms. The groups where no offspring were produced left unchanged.
+func (a *OperatorAdapter) Adapt(stats *OperatorStatistics, context *neat.NeatContext) {
+	// mutation only vs. mating
+	probs := []float64{context.MutateOnlyProb, 1.0 - context.MutateOnlyProb}
+	counts := []OperatorCounts{stats.MutationOnly, stats.Mating()}
+	if a.pursue(probs, counts) {
+		context.MutateOnlyProb = probs[0]
+	}
+
+	// the type of mutation: add node, add link or non-structural ones
+	p_node := context.MutateAddNodeProb
+	p_link := (1.0 - p_node) * context.MutateAddLinkProb
+	probs = []float64{p_node, p_link, 1.0 - p_node - p_link}
+	counts = []OperatorCounts{
+		stats.Counts(MutateAddNodeOperator),
+		stats.Counts(MutateAddLinkOperator),
+		stats.Counts(MutateNonstructuralOperator),
+	}
+	if a.pursue(probs, counts) {
+		context.MutateAddNodeProb = probs[0]
+		if probs[0] < 1.0 {
+			context.MutateAddLinkProb = probs[1] / (1.0 - probs[0])
+		}
+	}
+
+	// the type of crossover: multipoint, multipoint averaging or singlepoint
+	p_multi := context.MateMultipointProb
+	p_avg, p_single := 0.0, 0.0
+	if sum := context.MateMultipointAvgProb + context.MateSinglepointProb; sum > 0 {
+		p_avg = (1.0 - p_multi) * context.MateMultipointAvgProb / sum
+		p_single = (1.0 - p_multi) * context.MateSinglepointProb / sum
+	}
+	probs = []float64{p_multi, p_avg, p_single}
+	counts = []OperatorCounts{
+		stats.Counts(MateMultipointOperator),
+		stats.Counts(MateMultipointAvgOperator),
+		stats.Counts(MateSinglepointOperator),
+	}
+	if a.pursue(probs, counts) {
+		context.MateMultipointProb, context.MateMultipointAvgProb, context.MateSinglepointProb = probs[0], probs[1], probs[2]
+	}
+}
+
+// Performs one step of the adaptive pursuit over probabilities of the group of operators. Returns false if group was
+// not adapted, i.e. when less than two operators enabled or no offspring were produced by enabled operators.
+func (a *OperatorAdapter) pursue(probs []float64, counts []OperatorCounts) bool {
+	enabled, best, offspring := 0, -1, 0
+	for i, p := range probs {
+		if p <= 0 {
+			continue
+		}
+		enabled++
+		offspring += counts[i].Offspring
+		if best < 0 || counts[i].SuccessRate() > counts[best].SuccessRate() {
+			best = i
+		}
+	}
+	if enabled < 2 || offspring == 0 {
+		return false
+	}
+
+	p_min := a.MinProb
+	p_max := 1.0 - float64(enabled - 1) * p_min
+	if a.MaxProb > 0 && a.MaxProb < p_max {
+		p_max = a.MaxProb
+	}
+	total := 0.0
+	for i, p := range probs {
+		if p <= 0 {
+			continue
+		}
+		if i == best {
+			p += a.Rate * (p_max - p)
+		} else {
+			p += a.Rate * (p_min - p)
+		}
+		probs[i] = p
+		total += p
+	}
+	// keep the sum of probabilities when the ceiling is below the one allowed by floor
+	for i := range probs {
+		probs[i] /= total
+	}
+	return true
+}
+
+// Returns string with current reproduction operators probabilities of the context
+func OperatorProbabilities(context *neat.NeatContext) string {
+	return fmt.Sprintf("mutate_only: %.3f, add_node: %.3f, add_link: %.3f, mate_multipoint: %.3f, " +
+		"mate_multipoint_avg: %.3f, mate_singlepoint: %.3f", context.MutateOnlyProb, context.MutateAddNodeProb,
+		context.MutateAddLinkProb, context.MateMultipointProb, context.MateMultipointAvgProb,
+		context.MateSinglepointProb)
+}
@@ -0,0 +1,101 @@
+package genetics
+
+import (
+	"testing"
+	"math"
+	"github.com/yaricom/goNEAT/neat"
+)
+
+func TestOperatorAdapter_Adapt(t *testing.T) {
+	context := neat.NeatContext{
+		MutateOnlyProb:0.25,
+		MutateAddNodeProb:0.03,
+		MutateAddLinkProb:0.08,
+		MateMultipointProb:0.3,
+		MateMultipointAvgProb:0.3,
+		MateSinglepointProb:0.3,
+		AdaptiveOperatorsRate:0.5,
+		AdaptiveOperatorsMin:0.05,
+	}
+	adapter := NewOperatorAdapter(&context)
+
+	stats := NewOperatorStatistics()
+	// mating is more successful than mutation only
+	stats.MutationOnly = OperatorCounts{Offspring:10, Improved:1}
+	// add node is the best mutation
+	stats.Operators[MutateAddNodeOperator] = OperatorCounts{Offspring:5, Improved:4}
+	stats.Operators[MutateAddLinkOperator] = OperatorCounts{Offspring:5, Improved:1}
+	stats.Operators[MutateNonstructuralOperator] = OperatorCounts{Offspring:20, Improved:2}
+	// single point is the best crossover
+	stats.Operators[MateMultipointOperator] = OperatorCounts{Offspring:10, Improved:1}
+	stats.Operators[MateMultipointAvgOperator] = OperatorCounts{Offspring:10, Improved:2}
+	stats.Operators[MateSinglepointOperator] = OperatorCounts{Offspring:10, Improved:5}
+
+	adapter.Adapt(stats, &context)
+
+	// pursuit towards floor
+	if !equalFloat(context.MutateOnlyProb, 0.15) {
+		t.Error("Wrong mutate only probability", context.MutateOnlyProb)
+	}
+	// pursuit towards ceiling: 0.03 + 0.5 * (0.9 - 0.03)
+	if !equalFloat(context.MutateAddNodeProb, 0.465) {
+		t.Error("Wrong add node probability", context.MutateAddNodeProb)
+	}
+	// the link probability is conditional: (0.97 * 0.08 + 0.5 * (0.05 - 0.97 * 0.08)) / (1 - 0.465)
+	if !equalFloat(context.MutateAddLinkProb, (0.0776 + 0.5 * (0.05 - 0.0776)) / 0.535) {
+		t.Error("Wrong add link probability", context.MutateAddLinkProb)
+	}
+	if context.MateSinglepointProb <= context.MateMultipointAvgProb ||
+		context.MateMultipointProb >= 0.3 {
+		t.Error("Wrong crossover probabilities", OperatorProbabilities(&context))
+	}
+	if sum := context.MateMultipointProb + context.MateMultipointAvgProb + context.MateSinglepointProb; !equalFloat(sum, 1.0) {
+		t.Error("Crossover probabilities should sum to one", sum)
+	}
+
+	// the repeated adaptation converges to the floor/ceiling limits
+	for i := 0; i < 50; i++ {
+		adapter.Adapt(stats, &context)
+	}
+	if !equalFloat(context.MutateOnlyProb, 0.05) {
+		t.Error("Mutate only probability should reach floor", context.MutateOnlyProb)
+	}
+	if !equalFloat(context.MutateAddNodeProb, 0.9) {
+		t.Error("Add node probability should reach ceiling", context.MutateAddNodeProb)
+	}
+}
+
+func TestOperatorAdapter_AdaptDisabled(t *testing.T) {
+	context := neat.NeatContext{
+		MutateOnlyProb:0.25,
+		MutateAddNodeProb:0.03,
+		MutateAddLinkProb:0.0,
+		MateMultipointProb:1.0,
+		AdaptiveOperatorsMax:0.6,
+	}
+	stats := NewOperatorStatistics()
+	stats.Operators[MutateAddNodeOperator] = OperatorCounts{Offspring:5, Improved:4}
+	stats.Operators[MutateNonstructuralOperator] = OperatorCounts{Offspring:20, Improved:2}
+	stats.Operators[MateMultipointOperator] = OperatorCounts{Offspring:10, Improved:1}
+
+	NewOperatorAdapter(&context).Adapt(stats, &context)
+
+	// disabled operators stay disabled
+	if context.MutateAddLinkProb != 0 {
+		t.Error("Disabled add link operator adapted", context.MutateAddLinkProb)
+	}
+	if context.MateMultipointProb != 1.0 || context.MateMultipointAvgProb != 0 || context.MateSinglepointProb != 0 {
+		t.Error("Single crossover operator adapted", OperatorProbabilities(&context))
+	}
+	if context.MutateAddNodeProb <= 0.03 || context.MutateAddNodeProb > 0.6 {
+		t.Error("Wrong add node probability", context.MutateAddNodeProb)
+	}
+	// no mutation only offspring, the mating ones are better
+	if context.MutateOnlyProb >= 0.25 {
+		t.Error("Wrong mutate only probability", context.MutateOnlyProb)
+	}
+}
+
+func equalFloat(a, b float64) bool {
+	return math.Abs(a - b) < 1e-9
+}
@@ -0,0 +1,153 @@
+package genetics
+
+import (
+	"fmt"
+	"bytes"
+	"sort"
+)
+
+// The number of offspring produced by reproduction operator and how many of them were better than their parents
+type OperatorCounts struct {
+	// The number of offspring produced
+	Offspring int `json:"offspring"`
+	// The number of offspring which fitness exceeded the fitness of the best parent
+	Improved  int `json:"improved"`
+}
+
+// Returns the fraction of offspring which beat their parents
+func (c OperatorCounts) SuccessRate() float64 {
+	if c.Offspring == 0 {
+		return 0
+	}
+	return float64(c.Improved) / float64(c.Offspring)
+}
+
+// The statistics of reproduction operators usage collected from the evaluated organisms. Each offspring counted for its
+// crossover operator (if any) and for its mutation operator (if any), i.e. mutated crossover offspring contributes
+// to both.
+type OperatorStatistics struct {
+	// The counts per reproduction operator
+	Operators    map[ReproductionOperator]OperatorCounts
+	// The counts of the offspring produced by interspecies mating
+	Interspecies OperatorCounts
+	// The counts of the offspring produced by mutation without mating (champion clones excluded)
+	MutationOnly OperatorCounts
+}
+
+// Creates new empty operator statistics
+func NewOperatorStatistics() *OperatorStatistics {
+	return &OperatorStatistics{
+		Operators:make(map[ReproductionOperator]OperatorCounts),
+	}
+}
+
+// Collects the operator statistics from the evaluated organisms. The organism considered improved if its fitness is
+// greater than fitness of the best parent. The organisms of the initial population and the ones without ancestry are
+// skipped.
+func CollectOperatorStatistics(organisms []*Organism) *OperatorStatistics {
+	stats := NewOperatorStatistics()
+	for _, o := range organisms {
+		a := o.Ancestry
+		if a == nil || len(a.ParentIds) == 0 {
+			continue
+		}
+		improved := o.Fitness > a.BestParentFitness()
+		if a.Crossover != NoOperator {
+			stats.add(a.Crossover, improved)
+			if a.Interspecies {
+				stats.Interspecies = addCount(stats.Interspecies, improved)
+			}
+		}
+		if a.Mutation != NoOperator {
+			stats.add(a.Mutation, improved)
+		}
+		if a.Crossover == NoOperator && a.Mutation != CloneOperator {
+			stats.MutationOnly = addCount(stats.MutationOnly, improved)
+		}
+	}
+	return stats
+}
+
+// Returns the counts of given reproduction operator
+func (s *OperatorStatistics) Counts(op ReproductionOperator) OperatorCounts {
+	return s.Operators[op]
+}
+
+// Returns the total number of offspring counted
+func (s *OperatorStatistics) Offspring() int {
+	total := 0
+	for _, c := range s.Operators {
+		total += c.Offspring
+	}
+	return total
+}
+
+// Accumulates the counts of other statistics into this one
+func (s *OperatorStatistics) Merge(other *OperatorStatistics) {
+	if other == nil {
+		return
+	}
+	for op, c := range other.Operators {
+		curr := s.Operators[op]
+		curr.Offspring += c.Offspring
+		curr.Improved += c.Improved
+		s.Operators[op] = curr
+	}
+	s.Interspecies.Offspring += other.Interspecies.Offspring
+	s.Interspecies.Improved += other.Interspecies.Improved
+	s.MutationOnly.Offspring += other.MutationOnly.Offspring
+	s.MutationOnly.Improved += other.MutationOnly.Improved
+}
+
+// Returns the counts of all crossover offspring
+func (s *OperatorStatistics) Mating() OperatorCounts {
+	mating := OperatorCounts{}
+	for op, c := range s.Operators {
+		if op.IsCrossover() {
+			mating.Offspring += c.Offspring
+			mating.Improved += c.Improved
+		}
+	}
+	return mating
+}
+
+// Returns the operators with non zero offspring count in order of their declaration
+func (s *OperatorStatistics) SortedOperators() []ReproductionOperator {
+	ops := make([]ReproductionOperator, 0, len(s.Operators))
+	for op, c := range s.Operators {
+		if c.Offspring > 0 {
+			ops = append(ops, op)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i] < ops[j]
+	})
+	return ops
+}
+
+// Returns string representation of the statistics as table with one operator per line
+func (s *OperatorStatistics) String() string {
+	b := bytes.NewBufferString("")
+	for _, op := range s.SortedOperators() {
+		c := s.Operators[op]
+		fmt.Fprintf(b, "\t%-24s offspring: %6d, improved: %6d (%.1f%%)\n", ReproductionOperatorName(op),
+			c.Offspring, c.Improved, c.SuccessRate() * 100.0)
+	}
+	if s.Interspecies.Offspring > 0 {
+		fmt.Fprintf(b, "\t%-24s offspring: %6d, improved: %6d (%.1f%%)\n", "interspecies_mate",
+			s.Interspecies.Offspring, s.Interspecies.Improved, s.Interspecies.SuccessRate() * 100.0)
+	}
+	return b.String()
+}
+
+func (s *OperatorStatistics) add(op ReproductionOperator, improved bool) {
+	s.Operators[op] = addCount(s.Operators[op], improved)
+}
+
+func addCount(c OperatorCounts, improved bool) OperatorCounts {
+	c.Offspring++
+	if improved {
+		c.Improved++
+	}
+	return c
+}
@@ -0,0 +1,70 @@
+package genetics
+
+import (
+	"testing"
+	"strings"
+)
+
+func TestCollectOperatorStatistics(t *testing.T) {
+	orgs := []*Organism{
+		// initial organism - skipped
+		{Fitness:1.0, Ancestry:newInitialAncestry()},
+		// mutated crossover offspring better than both parents
+		{Fitness:1.0, Ancestry:&Ancestry{ParentIds:[]int{1, 2}, ParentFitness:[]float64{0.5, 0.7},
+			Crossover:MateMultipointOperator, Mutation:MutateAddNodeOperator, Interspecies:true}},
+		// crossover offspring better than mother but not than father
+		{Fitness:0.6, Ancestry:&Ancestry{ParentIds:[]int{1, 2}, ParentFitness:[]float64{0.5, 0.7},
+			Crossover:MateMultipointOperator}},
+		// mutation offspring worse than parent
+		{Fitness:0.1, Ancestry:&Ancestry{ParentIds:[]int{3}, ParentFitness:[]float64{0.5},
+			Mutation:MutateAddNodeOperator}},
+		// clone with the same fitness
+		{Fitness:0.5, Ancestry:&Ancestry{ParentIds:[]int{3}, ParentFitness:[]float64{0.5},
+			Mutation:CloneOperator}},
+		// no ancestry - skipped
+		{Fitness:0.5},
+	}
+	stats := CollectOperatorStatistics(orgs)
+
+	if c := stats.Counts(MateMultipointOperator); c.Offspring != 2 || c.Improved != 1 {
+		t.Error("Wrong multipoint crossover counts", c)
+	}
+	if c := stats.Counts(MutateAddNodeOperator); c.Offspring != 2 || c.Improved != 1 || c.SuccessRate() != 0.5 {
+		t.Error("Wrong add node counts", c)
+	}
+	if c := stats.Counts(CloneOperator); c.Offspring != 1 || c.Improved != 0 {
+		t.Error("Wrong clone counts", c)
+	}
+	if c := stats.Counts(MateSinglepointOperator); c.Offspring != 0 || c.SuccessRate() != 0 {
+		t.Error("Wrong counts of unused operator", c)
+	}
+	if stats.Interspecies.Offspring != 1 || stats.Interspecies.Improved != 1 {
+		t.Error("Wrong interspecies counts", stats.Interspecies)
+	}
+	if stats.MutationOnly.Offspring != 1 || stats.MutationOnly.Improved != 0 {
+		t.Error("Wrong mutation only counts", stats.MutationOnly)
+	}
+	if c := stats.Mating(); c.Offspring != 2 || c.Improved != 1 {
+		t.Error("Wrong mating counts", c)
+	}
+	if stats.Offspring() != 5 {
+		t.Error("Wrong total offspring", stats.Offspring())
+	}
+
+	total := NewOperatorStatistics()
+	total.Merge(stats)
+	total.Merge(stats)
+	total.Merge(nil)
+	if c := total.Counts(MutateAddNodeOperator); c.Offspring != 4 || c.Improved != 2 {
+		t.Error("Wrong merged counts", c)
+	}
+	ops := total.SortedOperators()
+	if len(ops) != 3 || ops[0] != CloneOperator || ops[1] != MutateAddNodeOperator || ops[2] != MateMultipointOperator {
+		t.Error("Wrong sorted operators", ops)
+	}
+
+	str := total.String()
+	if !strings.Contains(str, "mutate_add_node") || !strings.Contains(str, "interspecies_mate") {
+		t.Error("Wrong string representation", str)
+	}
+}
@@ -36,6 +36,9 @@ type Organism struct {
 	// Tells which generation this Organism is from
 	Generation                int
 
+	// The origin of the organism: parents and reproduction operators, nil if unknown
+	Ancestry                  *Ancestry
+
 	// The utility data transfer object to be used by different GA implementations to hold additional data.
 	// Implemented as ANY to allow implementation specific objects.
 	Data                      *OrganismData
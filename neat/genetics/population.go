@@ -35,12 +35,20 @@ type Population struct {
 	HighestFitness     float64
 	// If too high, leads to delta coding
 	HighestLastChanged int
+	// The policy to reallocate expected offspring among species, the StandardOffspringAllocator if not set
+	OffspringAllocator OffspringAllocator
+	// The offspring reallocation events happened during the last epoch
+	OffspringEvents    []*OffspringEvent
 
 	/* Fitness Statistics */
 	MeanFitness        float64
 	Variance           float64
 	StandardDev        float64
 
+	// The genealogy of evaluated organisms. If set, each generation will be recorded into it when epoch starts.
+	Genealogy          *Genealogy
+	// The history of species. If set, species of each generation will be recorded into it when epoch starts.
+	SpeciesHistory     *SpeciesHistory
 
 	// The current innovation number for population
 	currInnovNum       int64
@@ -51,7 +59,7 @@ type Population struct {
 	sync.Mutex
 }
 
-// Construct off of a single spawning Genome
+// Construct off of a single spawning Genome. Returns error if genome violates the genome constraints of the context.
 func NewPopulation(g *Genome, context *neat.NeatContext) (*Population, error) {
 	if context.PopSize <= 0 {
 		return nil, errors.New(
@@ -78,7 +86,9 @@ func NewPopulationRandom(in, out, nmax int, recurrent bool, link_prob float64, c
 	pop := newPopulation()
 	for count := 0; count < context.PopSize; count++ {
 		gen := NewGenomeRand(count, in, out, rand.Intn(nmax), nmax, recurrent, link_prob)
-		pop.Organisms = append(pop.Organisms, NewOrganism(0.0, gen, 1))
+		org := NewOrganism(0.0, gen, 1)
+		org.Ancestry = newInitialAncestry()
+		pop.Organisms = append(pop.Organisms, org)
 	}
 	pop.currNodeId = in + out + nmax + 1
 	pop.currInnovNum = int64((in + out + nmax) * (in + out + nmax) + 1)
@@ -121,6 +131,7 @@ func ReadPopulation(ir io.Reader, context *neat.NeatContext) (pop *Population, e
 			}
 			// add new organism for read genome
 			new_organism := NewOrganism(0.0, new_genome, 1)
+			new_organism.Ancestry = newInitialAncestry()
 			pop.Organisms = append(pop.Organisms, new_organism)
 
 			if last_node_id, err := new_genome.getLastNodeId(); err == nil {
@@ -203,6 +214,9 @@ func (p *Population) getCurrentNodeIdAndIncrement() int {
 // Create a population of size size off of Genome g. The new Population will have the same topology as g
 // with link weights slightly perturbed from g's
 func (p *Population) spawn(g *Genome, context *neat.NeatContext) error {
+	if err := g.checkConstraints(&context.Constraints); err != nil {
+		return err
+	}
 	var new_genome *Genome
 	for count := 0; count < context.PopSize; count++ {
 		new_genome = g.duplicate(count)
@@ -211,6 +225,7 @@ func (p *Population) spawn(g *Genome, context *neat.NeatContext) error {
 			return err
 		}
 		new_organism := NewOrganism(0.0, new_genome, 1)
+		new_organism.Ancestry = newInitialAncestry()
 		p.Organisms = append(p.Organisms, new_organism)
 	}
 	//Keep a record of the innovation and node number we are on
@@ -228,6 +243,53 @@ func (p *Population) spawn(g *Genome, context *neat.NeatContext) error {
 	return err
 }
 
+// Replaces all organisms and species of this population with the new ones spawned from given genomes, which are used
+// in turn until population size reached. The first copy of each genome kept intact, while the others have link
+// weights perturbed. The innovation number and node ID counters are kept, thus the genes of reseeded organisms stay
+// comparable with the ones evolved earlier. The new species get IDs not used before by this population. Returns error
+// if any of genomes violates the genome constraints of the context.
+func (p *Population) Reseed(genomes []*Genome, context *neat.NeatContext) error {
+	if len(genomes) == 0 {
+		return errors.New("There is no genomes to reseed population from")
+	}
+	for _, g := range genomes {
+		if err := g.checkConstraints(&context.Constraints); err != nil {
+			return err
+		}
+	}
+	p.Organisms = make([]*Organism, 0, context.PopSize)
+	p.Species = make([]*Species, 0)
+	p.Innovations = make([]*Innovation, 0)
+	for count := 0; count < context.PopSize; count++ {
+		new_genome := genomes[count % len(genomes)].duplicate(count)
+		if count >= len(genomes) {
+			if _, err := new_genome.mutateLinkWeights(1.0, 1.0, gaussianMutator); err != nil {
+				return err
+			}
+		}
+		new_organism := NewOrganism(0.0, new_genome, 1)
+		new_organism.Ancestry = newInitialAncestry()
+		p.Organisms = append(p.Organisms, new_organism)
+
+		// the seed genomes may be not known to this population, e.g. the start genome of fresh population
+		node_id, err := new_genome.getLastNodeId()
+		if err != nil {
+			return err
+		}
+		innovation, err := new_genome.getLastGeneInnovNum()
+		if err != nil {
+			return err
+		}
+		p.AdvanceCounters(innovation, node_id)
+	}
+	p.HighestFitness, p.HighestLastChanged = 0.0, 0
+	p.OffspringEvents = nil
+
+	// continue numbering of species
+	p.LastSpecies++
+	return p.speciate(context)
+}
+
 // Speciate separates the organisms into species by checking compatibilities against a threshold.
 // Any organism that does is not compatible with the first organism in any existing species becomes a new species.
 func (p *Population) speciate(context *neat.NeatContext) error {
@@ -235,8 +297,8 @@ func (p *Population) speciate(context *neat.NeatContext) error {
 		return errors.New("There is no organisms to speciate from")
 	}
 
-	// Species counter
-	species_counter := 0
+	// Species counter, which is zero for the new population
+	species_counter := p.LastSpecies
 	// Step through all known organisms
 	for _, curr_org := range p.Organisms {
 		if len(p.Species) == 0 {
@@ -304,6 +366,20 @@ type reproductionResult struct {
 // Turnover the population to a new generation using fitness
 // The generation argument is the next generation
 func (p *Population) Epoch(generation int, context *neat.NeatContext) (bool, error) {
+	// Store the evaluated generation before fitness adjustment
+	if p.Genealogy != nil {
+		p.Genealogy.RecordGeneration(p.Organisms, generation - 1)
+	}
+	if p.SpeciesHistory != nil {
+		p.SpeciesHistory.RecordGeneration(p.Species, generation - 1)
+	}
+	// Adapt reproduction operators probabilities to the success of offspring in the evaluated generation
+	if context.AdaptiveOperators {
+		NewOperatorAdapter(context).Adapt(CollectOperatorStatistics(p.Organisms), context)
+		neat.InfoLog(fmt.Sprintf("POPULATION: Generation %d: reproduction operators probabilities: %s",
+			generation, OperatorProbabilities(context)))
+	}
+
 	// Use Species' ages to modify the objective fitness of organisms in other words, make it more fair for younger
 	// species so they have a chance to take hold and also penalize stagnant species. Then adjust the fitness using
 	// the species size to "share" fitness within a species. Then, within each Species, mark for death those below
@@ -421,106 +497,14 @@ func (p *Population) Epoch(generation int, context *neat.NeatContext) (bool, err
 		neat.DebugLog(fmt.Sprintf(" generations since last population fitness record: %f\n", p.HighestFitness))
 	}
 
-	// Check for stagnation - if there is stagnation, perform delta-coding
-	if p.HighestLastChanged >= context.DropOffAge + 5 {
-		neat.DebugLog("POPULATION: PERFORMING DELTA CODING")
-
-		p.HighestLastChanged = 0
-		half_pop := context.PopSize / 2
-
-		neat.DebugLog(fmt.Sprintf("half_pop: [%d] (pop_size - halfpop): [%d]\n",
-			half_pop, context.PopSize - half_pop))
-
-		if len(sorted_species) > 1 {
-			// Assign population to first two species
-			curr_species.Organisms[0].superChampOffspring = half_pop
-			curr_species.ExpectedOffspring = half_pop
-			curr_species.AgeOfLastImprovement = curr_species.Age
-
-			// process the second species
-			curr_species = sorted_species[1]
-			// NOTE: PopSize can be odd. That's why we use subtraction below
-			curr_species.Organisms[0].superChampOffspring = context.PopSize - half_pop
-			curr_species.ExpectedOffspring = context.PopSize - half_pop
-			curr_species.AgeOfLastImprovement = curr_species.Age
-
-			// Get rid of all species after the first two
-			for i := 2; i < len(sorted_species); i++ {
-				sorted_species[i].ExpectedOffspring = 0
-			}
-		} else {
-			curr_species = sorted_species[0]
-			curr_species.Organisms[0].superChampOffspring = context.PopSize
-			curr_species.ExpectedOffspring = context.PopSize
-			curr_species.AgeOfLastImprovement = curr_species.Age
-		}
-	} else if context.BabiesStolen > 0 {
-		// STOLEN BABIES: The system can take expected offspring away from worse species and give them
-		// to superior species depending on the system parameter BabiesStolen (when BabiesStolen > 0)
-		stolen_babies := 0 // Babies taken from the bad species and given to the champs
-
-		// Take away a constant number of expected offspring from the worst few species
-		for i := len(sorted_species) - 1; i >= 0 && stolen_babies < context.BabiesStolen; i-- {
-			curr_species = sorted_species[i]
-			if curr_species.Age > 5 && curr_species.ExpectedOffspring > 2 {
-				if curr_species.ExpectedOffspring - 1 >= context.BabiesStolen - stolen_babies {
-					// This species has enough to finish off the stolen pool
-					curr_species.ExpectedOffspring -= context.BabiesStolen - stolen_babies
-					stolen_babies = context.BabiesStolen
-				} else {
-					// Not enough here to complete the pool of stolen
-					stolen_babies += curr_species.ExpectedOffspring - 1
-					curr_species.ExpectedOffspring = 1
-				}
-			}
-		}
-
-		neat.DebugLog(fmt.Sprintf("POPULATION: STOLEN BABIES: %d\n", stolen_babies))
-
-		// Mark the best champions of the top species to be the super champs who will take on the extra
-		// offspring for cloning or mutant cloning.
-		// Determine the exact number that will be given to the top three.
-		// They will get, in order, 1/5 1/5 and 1/10 of the stolen babies
-		stolen_blocks := []int{context.BabiesStolen / 5, context.BabiesStolen / 5, context.BabiesStolen / 10}
-		block_index := 0
-		for _, curr_species = range sorted_species {
-			if curr_species.lastImproved() > context.DropOffAge {
-				// Don't give a chance to dying species even if they are champs
-				continue
-			}
-
-			if block_index < 3 && stolen_babies >= stolen_blocks[block_index] {
-				// Give stolen babies to the top three in 1/5 1/5 and 1/10 ratios
-				curr_species.Organisms[0].superChampOffspring = stolen_blocks[block_index]
-				curr_species.ExpectedOffspring += stolen_blocks[block_index]
-				stolen_babies -= stolen_blocks[block_index]
-			} else if block_index >= 3 {
-				// Give stolen to the rest in random ratios
-				if rand.Float64() > 0.1 {
-					// Randomize a little which species get boosted by a super champ
-					if stolen_babies > 3 {
-						curr_species.Organisms[0].superChampOffspring = 3
-						curr_species.ExpectedOffspring += 3
-						stolen_babies -= 3
-					} else {
-						curr_species.Organisms[0].superChampOffspring = stolen_babies
-						curr_species.ExpectedOffspring += stolen_babies
-						stolen_babies = 0
-					}
-				}
-			}
-
-			if stolen_babies <= 0 {
-				break
-			}
-			block_index++
-		}
-		// If any stolen babies aren't taken, give them to species #1's champ
-		if stolen_babies > 0 {
-			curr_species = sorted_species[0]
-			curr_species.Organisms[0].superChampOffspring += stolen_babies
-			curr_species.ExpectedOffspring += stolen_babies
-		}
+	// Reallocate expected offspring among species, e.g. perform delta-coding if there is stagnation
+	allocator := p.OffspringAllocator
+	if allocator == nil {
+		allocator = StandardOffspringAllocator{}
+	}
+	p.OffspringEvents = allocator.Allocate(p, sorted_species, generation, context)
+	for _, event := range p.OffspringEvents {
+		neat.DebugLog(fmt.Sprintf("POPULATION: %s", event))
 	}
 
 	// Kill off all Organisms marked for death. The remainder will be allowed to reproduce.
@@ -282,3 +282,49 @@ func TestPopulation_epoch(t *testing.T) {
 	}
 
 }
+
+func TestPopulation_Reseed(t *testing.T) {
+	rand.Seed(42)
+	conf := neat.NeatContext{
+		CompatThreshold:0.5,
+		PopSize:10,
+	}
+	pop, err := NewPopulation(buildTestGenome(1), &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pop.AdvanceCounters(pop.NextInnovationNumber() + 10, pop.NextNodeId() + 5)
+	innov, node_id := pop.NextInnovationNumber(), pop.NextNodeId()
+	last_species := pop.LastSpecies
+	old_species := make(map[*Species]bool)
+	for _, sp := range pop.Species {
+		old_species[sp] = true
+	}
+
+	seeds := []*Genome{buildTestGenome(2), buildTestGenome(3)}
+	seeds[1].Genes[0].Link.Weight = 5.0
+	if err = pop.Reseed(seeds, &conf); err != nil {
+		t.Fatal(err)
+	}
+	if len(pop.Organisms) != conf.PopSize {
+		t.Error("Wrong population size", len(pop.Organisms))
+	}
+	if pop.NextInnovationNumber() != innov || pop.NextNodeId() != node_id {
+		t.Error("Counters should be kept", pop.NextInnovationNumber(), pop.NextNodeId())
+	}
+	if pop.Organisms[1].Genotype.Genes[0].Link.Weight != 5.0 {
+		t.Error("The first copy of seed genome should be intact")
+	}
+	if len(pop.Species) == 0 {
+		t.Fatal("Population not speciated")
+	}
+	for _, sp := range pop.Species {
+		if old_species[sp] || sp.Id <= last_species {
+			t.Error("Species ID reused", sp.Id, last_species)
+		}
+	}
+
+	if err = pop.Reseed(nil, &conf); err == nil {
+		t.Error("Reseed without genomes should fail")
+	}
+}
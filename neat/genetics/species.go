@@ -24,6 +24,9 @@ type Species struct {
 
 	// Is it novel
 	IsNovel              bool
+	// The ID of species where the founder organism of this species was born or -1 if this species was created by
+	// speciation of initial population or by migrant
+	ParentId             int
 
 	// The organisms in the Species
 	Organisms            Organisms
@@ -53,6 +56,7 @@ func newSpecies(id int) *Species {
 	return &Species{
 		Id:id,
 		Age:1,
+		ParentId:-1,
 		Organisms:make([]*Organism, 0),
 	}
 }
@@ -262,6 +266,9 @@ func (s *Species) reproduce(generation int, pop *Population, sorted_species []*S
 			count, s.ExpectedOffspring, s.Id))
 
 		mut_struct_baby, mate_baby := false, false
+		// The reproduction operators applied to produce baby
+		crossover, mutation := NoOperator, NoOperator
+		var parents []*Organism
 
 		// Debug Trap
 		if s.ExpectedOffspring > context.PopSize {
@@ -280,10 +287,12 @@ func (s *Species) reproduce(generation int, pop *Population, sorted_species []*S
 			// The last offspring will be an exact duplicate of this super_champ
 			// Note: Superchamp offspring only occur with stolen babies!
 			//      Settings used for published experiments did not use this
+			mutation = CloneOperator
 			if the_champ.superChampOffspring > 1 {
 				if rand.Float64() < 0.8 || context.MutateAddLinkProb == 0.0 {
 					// Make sure no links get added when the system has link adding disabled
 					new_genome.mutateLinkWeights(context.WeightMutPower, 1.0, gaussianMutator)
+					mutation = MutateLinkWeightsOperator
 				} else {
 					// Sometimes we add a link to a superchamp
 					new_genome.genesis(generation)
@@ -292,8 +301,10 @@ func (s *Species) reproduce(generation int, pop *Population, sorted_species []*S
 						return false, err
 					}
 					mut_struct_baby = true;
+					mutation = MutateAddLinkOperator
 				}
 			}
+			parents = []*Organism{mom}
 
 			// Create the new baby organism
 			baby = NewOrganism(0.0, new_genome, generation)
@@ -314,6 +325,8 @@ func (s *Species) reproduce(generation int, pop *Population, sorted_species []*S
 			new_genome := mom.Genotype.duplicate(count)
 			// Baby is just like mommy
 			champ_clone_done = true
+			mutation = CloneOperator
+			parents = []*Organism{mom}
 
 			// Create the new baby organism
 			baby = NewOrganism(0.0, new_genome, generation)
@@ -336,6 +349,7 @@ func (s *Species) reproduce(generation int, pop *Population, sorted_species []*S
 					return false, err
 				}
 				mut_struct_baby = true
+				mutation = MutateAddNodeOperator
 			} else if rand.Float64() < context.MutateAddLinkProb {
 				neat.DebugLog("SPECIES: ---> mutateAddLink")
 
@@ -346,6 +360,7 @@ func (s *Species) reproduce(generation int, pop *Population, sorted_species []*S
 					return false, err
 				}
 				mut_struct_baby = true
+				mutation = MutateAddLinkOperator
 			} else if rand.Float64() < context.MutateConnectSensors {
 				neat.DebugLog("SPECIES: ---> mutateConnectSensors")
 				link_added, err := new_genome.mutateConnectSensors(pop, context)
@@ -353,6 +368,7 @@ func (s *Species) reproduce(generation int, pop *Population, sorted_species []*S
 					return false, err
 				}
 				mut_struct_baby = link_added
+				mutation = MutateConnectSensorsOperator
 			}
 
 			if !mut_struct_baby {
@@ -363,7 +379,9 @@ func (s *Species) reproduce(generation int, pop *Population, sorted_species []*S
 				if err != nil {
 					return false, err
 				}
+				mutation = MutateNonstructuralOperator
 			}
+			parents = []*Organism{mom}
 
 			// Create the new baby organism
 			baby = NewOrganism(0.0, new_genome, generation);
@@ -409,28 +427,32 @@ func (s *Species) reproduce(generation int, pop *Population, sorted_species []*S
 				neat.DebugLog("SPECIES: ------> mateMultipoint")
 
 				// mate multipoint baby
-				new_genome, err = mom.Genotype.mateMultipoint(dad.Genotype, count, mom.OriginalFitness, dad.OriginalFitness)
+				new_genome, err = mom.Genotype.mateMultipoint(dad.Genotype, count, mom.OriginalFitness, dad.OriginalFitness, context)
 				if err != nil {
 					return false, err
 				}
+				crossover = MateMultipointOperator
 			} else if rand.Float64() < context.MateMultipointAvgProb / (context.MateMultipointAvgProb + context.MateSinglepointProb) {
 				neat.DebugLog("SPECIES: ------> mateMultipointAvg")
 
 				// mate multipoint_avg baby
-				new_genome, err = mom.Genotype.mateMultipointAvg(dad.Genotype, count, mom.OriginalFitness, dad.OriginalFitness)
+				new_genome, err = mom.Genotype.mateMultipointAvg(dad.Genotype, count, mom.OriginalFitness, dad.OriginalFitness, context)
 				if err != nil {
 					return false, err
 				}
+				crossover = MateMultipointAvgOperator
 			} else {
 				neat.DebugLog("SPECIES: ------> mateSinglepoint")
 
-				new_genome, err = mom.Genotype.mateSinglepoint(dad.Genotype, count)
+				new_genome, err = mom.Genotype.mateSinglepoint(dad.Genotype, count, context)
 				if err != nil {
 					return false, err
 				}
+				crossover = MateSinglepointOperator
 			}
 
 			mate_baby = true
+			parents = []*Organism{mom, dad}
 
 			// Determine whether to mutate the baby's Genome
 			// This is done randomly or if the mom and dad are the same organism
@@ -449,6 +471,7 @@ func (s *Species) reproduce(generation int, pop *Population, sorted_species []*S
 						return false, err
 					}
 					mut_struct_baby = true
+					mutation = MutateAddNodeOperator
 				} else if rand.Float64() < context.MutateAddLinkProb {
 					neat.DebugLog("SPECIES: ---------> mutateAddLink")
 
@@ -459,6 +482,7 @@ func (s *Species) reproduce(generation int, pop *Population, sorted_species []*S
 						return false, err
 					}
 					mut_struct_baby = true
+					mutation = MutateAddLinkOperator
 				} else if rand.Float64() < context.MutateConnectSensors {
 					neat.DebugLog("SPECIES: ---> mutateConnectSensors")
 					link_added, err := new_genome.mutateConnectSensors(pop, context)
@@ -466,6 +490,7 @@ func (s *Species) reproduce(generation int, pop *Population, sorted_species []*S
 						return false, err
 					}
 					mut_struct_baby = link_added
+					mutation = MutateConnectSensorsOperator
 				}
 
 				if !mut_struct_baby {
@@ -476,6 +501,7 @@ func (s *Species) reproduce(generation int, pop *Population, sorted_species []*S
 					if err != nil {
 						return false, err
 					}
+					mutation = MutateNonstructuralOperator
 				}
 			}
 			// Create the new baby organism
@@ -486,59 +512,71 @@ func (s *Species) reproduce(generation int, pop *Population, sorted_species []*S
 		// If it doesn't fit a Species, create a new one
 		baby.mutationStructBaby = mut_struct_baby
 		baby.mateBaby = mate_baby
+		baby.Ancestry = newAncestry(generation, crossover, mutation, parents...)
 
-		if len(pop.Species) == 0 {
-			// Create the first species
-			createFirstSpecies(pop, baby)
-		} else {
-			if context.CompatThreshold == 0 {
-				return false, errors.New("SPECIES: compatibility thershold is set to ZERO. " +
-					"Will not find any compatible species.")
-			}
+		if err := addToSpecies(pop, baby, s, context); err != nil {
+			return false, err
+		}
+	} // end for count := 0
+	return true, nil
+}
 
-			found := false
-			var best_compatible *Species // the best compatible species
-			best_compat_value := math.MaxFloat64
-			for _, _specie := range pop.Species {
-				// point _species
-				if len(_specie.Organisms) > 0 {
-					// point to first organism of this _specie
-					compare_org := _specie.Organisms[0]
-					// compare baby organism with first organism in current specie
-					curr_compat := baby.Genotype.compatibility(compare_org.Genotype, context)
-
-					if curr_compat < context.CompatThreshold && curr_compat < best_compat_value {
-						best_compatible = _specie
-						best_compat_value = curr_compat
-						found = true
-					}
-				}
-			}
+// Adds organism to the most compatible species of population. If it doesn't fit any species, creates a new one with
+// given parent species, which is nil if organism was not born in this population.
+func addToSpecies(pop *Population, org *Organism, parent *Species, context *neat.NeatContext) error {
+	if len(pop.Species) == 0 {
+		// Create the first species
+		createFirstSpecies(pop, org, parent)
+	} else {
+		if context.CompatThreshold == 0 {
+			return errors.New("SPECIES: compatibility thershold is set to ZERO. " +
+				"Will not find any compatible species.")
+		}
 
-			if found {
-				neat.DebugLog(fmt.Sprintf("SPECIES: Compatible species [%d] found for baby organism [%d]",
-					best_compatible.Id, baby.Genotype.Id))
-				// Found compatible species, so add this baby to it
-				best_compatible.addOrganism(baby);
-				// update in baby pointer to its species
-				baby.Species = best_compatible
+		found := false
+		var best_compatible *Species // the best compatible species
+		best_compat_value := math.MaxFloat64
+		for _, _specie := range pop.Species {
+			// point _species
+			if len(_specie.Organisms) > 0 {
+				// point to first organism of this _specie
+				compare_org := _specie.Organisms[0]
+				// compare organism with first organism in current specie
+				curr_compat := org.Genotype.compatibility(compare_org.Genotype, context)
+
+				if curr_compat < context.CompatThreshold && curr_compat < best_compat_value {
+					best_compatible = _specie
+					best_compat_value = curr_compat
+					found = true
+				}
 			}
+		}
 
-			// If match was not found, create a new species
-			if !found {
-				createFirstSpecies(pop, baby)
-			}
+		if found {
+			neat.DebugLog(fmt.Sprintf("SPECIES: Compatible species [%d] found for organism [%d]",
+				best_compatible.Id, org.Genotype.Id))
+			// Found compatible species, so add this organism to it
+			best_compatible.addOrganism(org);
+			// update in organism pointer to its species
+			org.Species = best_compatible
 		}
 
-	} // end for count := 0
-	return true, nil
+		// If match was not found, create a new species
+		if !found {
+			createFirstSpecies(pop, org, parent)
+		}
+	}
+	return nil
 }
 
-func createFirstSpecies(pop *Population, baby *Organism) {
+func createFirstSpecies(pop *Population, baby *Organism, parent *Species) {
 	neat.DebugLog(fmt.Sprintf("SPECIES: Create first species for baby organism [%d]", baby.Genotype.Id))
 
 	pop.LastSpecies++
 	new_species := NewSpeciesNovel(pop.LastSpecies, true)
+	if parent != nil {
+		new_species.ParentId = parent.Id
+	}
 	pop.Species = append(pop.Species, new_species)
 	new_species.addOrganism(baby) // Add the baby
 	baby.Species = new_species // Point baby to its species
@@ -0,0 +1,174 @@
+package genetics
+
+import (
+	"io"
+	"fmt"
+	"sort"
+	"encoding/csv"
+	"encoding/json"
+)
+
+// The record about species of evaluated population
+type SpeciesRecord struct {
+	// The generation where species was evaluated
+	Generation     int     `json:"generation"`
+	// The ID of species
+	SpeciesId      int     `json:"species_id"`
+	// The ID of species where the founder of this species was born or -1 if there is no parent species
+	ParentId       int     `json:"parent_id"`
+	// The age of species
+	Age            int     `json:"age"`
+	// The number of organisms in species
+	Size           int     `json:"size"`
+	// The best fitness among organisms of species
+	BestFitness    float64 `json:"best_fitness"`
+	// The average fitness of organisms of species
+	AverageFitness float64 `json:"average_fitness"`
+	// The number of generations since the last improvement of species' fitness
+	Stagnation     int     `json:"stagnation"`
+}
+
+// The lifespan of species collected from history records
+type SpeciesLifespan struct {
+	// The ID of species
+	SpeciesId int  `json:"species_id"`
+	// The ID of parent species or -1 if there is no parent species
+	ParentId  int  `json:"parent_id"`
+	// The first generation where species was recorded
+	Born      int  `json:"born"`
+	// The last generation where species was recorded
+	LastSeen  int  `json:"last_seen"`
+	// The flag to indicate whether species went extinct, i.e. it is not present in the last recorded generation
+	Extinct   bool `json:"extinct"`
+	// The maximal size of species
+	MaxSize   int  `json:"max_size"`
+}
+
+// The history of species of the population per generation. It allows to trace when species were born from which
+// parent species, how they grew, stagnated and went extinct.
+type SpeciesHistory struct {
+	// The records of species in order of recording
+	Records []*SpeciesRecord
+}
+
+// Creates new empty species history
+func NewSpeciesHistory() *SpeciesHistory {
+	return &SpeciesHistory{
+		Records:make([]*SpeciesRecord, 0),
+	}
+}
+
+// Records species of the evaluated population of the given generation
+func (h *SpeciesHistory) RecordGeneration(species []*Species, generation int) {
+	for _, sp := range species {
+		best, avg := sp.ComputeMaxAndAvgFitness()
+		h.Records = append(h.Records, &SpeciesRecord{
+			Generation:generation,
+			SpeciesId:sp.Id,
+			ParentId:sp.ParentId,
+			Age:sp.Age,
+			Size:len(sp.Organisms),
+			BestFitness:best,
+			AverageFitness:avg,
+			Stagnation:sp.lastImproved(),
+		})
+	}
+}
+
+// Returns the last recorded generation or -1 if history is empty
+func (h *SpeciesHistory) LastGeneration() int {
+	last := -1
+	for _, r := range h.Records {
+		if r.Generation > last {
+			last = r.Generation
+		}
+	}
+	return last
+}
+
+// Returns the records of species evaluated in the given generation
+func (h *SpeciesHistory) Generation(generation int) []*SpeciesRecord {
+	records := make([]*SpeciesRecord, 0)
+	for _, r := range h.Records {
+		if r.Generation == generation {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// Returns the lifespans of all recorded species ordered by birth generation and species ID
+func (h *SpeciesHistory) Lifespans() []*SpeciesLifespan {
+	last := h.LastGeneration()
+	index := make(map[int]*SpeciesLifespan)
+	lifespans := make([]*SpeciesLifespan, 0)
+	for _, r := range h.Records {
+		ls, ok := index[r.SpeciesId]
+		if !ok {
+			ls = &SpeciesLifespan{
+				SpeciesId:r.SpeciesId,
+				ParentId:r.ParentId,
+				Born:r.Generation,
+				LastSeen:r.Generation,
+			}
+			index[r.SpeciesId] = ls
+			lifespans = append(lifespans, ls)
+		}
+		if r.Generation < ls.Born {
+			ls.Born = r.Generation
+		}
+		if r.Generation > ls.LastSeen {
+			ls.LastSeen = r.Generation
+		}
+		if r.Size > ls.MaxSize {
+			ls.MaxSize = r.Size
+		}
+	}
+	for _, ls := range lifespans {
+		ls.Extinct = ls.LastSeen < last
+	}
+	sort.SliceStable(lifespans, func(i, j int) bool {
+		if lifespans[i].Born == lifespans[j].Born {
+			return lifespans[i].SpeciesId < lifespans[j].SpeciesId
+		}
+		return lifespans[i].Born < lifespans[j].Born
+	})
+	return lifespans
+}
+
+// Writes all records of this history as CSV with header
+func (h *SpeciesHistory) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"generation", "species_id", "parent_id", "age", "size", "best_fitness",
+		"average_fitness", "stagnation"})
+	if err != nil {
+		return err
+	}
+	for _, r := range h.Records {
+		err = cw.Write([]string{
+			fmt.Sprintf("%d", r.Generation),
+			fmt.Sprintf("%d", r.SpeciesId),
+			fmt.Sprintf("%d", r.ParentId),
+			fmt.Sprintf("%d", r.Age),
+			fmt.Sprintf("%d", r.Size),
+			fmt.Sprintf("%g", r.BestFitness),
+			fmt.Sprintf("%g", r.AverageFitness),
+			fmt.Sprintf("%d", r.Stagnation),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes records and lifespans of species of this history as indented JSON
+func (h *SpeciesHistory) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Records []*SpeciesRecord   `json:"records"`
+		Species []*SpeciesLifespan `json:"species"`
+	}{h.Records, h.Lifespans()})
+}
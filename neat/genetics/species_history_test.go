@@ -0,0 +1,134 @@
+package genetics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"math/rand"
+	"encoding/json"
+	"github.com/yaricom/goNEAT/neat"
+)
+
+func buildTestSpeciesHistory() *SpeciesHistory {
+	h := NewSpeciesHistory()
+	h.Records = []*SpeciesRecord{
+		{Generation:0, SpeciesId:1, ParentId:-1, Age:1, Size:10, BestFitness:0.5, AverageFitness:0.2},
+		{Generation:1, SpeciesId:1, ParentId:-1, Age:2, Size:6, BestFitness:0.6, AverageFitness:0.3},
+		{Generation:1, SpeciesId:2, ParentId:1, Age:1, Size:4, BestFitness:0.7, AverageFitness:0.4},
+		{Generation:2, SpeciesId:2, ParentId:1, Age:2, Size:10, BestFitness:0.8, AverageFitness:0.5, Stagnation:1},
+	}
+	return h
+}
+
+func TestSpeciesHistory_Lifespans(t *testing.T) {
+	lifespans := buildTestSpeciesHistory().Lifespans()
+	if len(lifespans) != 2 {
+		t.Fatal("Wrong number of species", len(lifespans))
+	}
+	first, second := lifespans[0], lifespans[1]
+	if first.SpeciesId != 1 || first.Born != 0 || first.LastSeen != 1 || !first.Extinct || first.MaxSize != 10 {
+		t.Error("Wrong lifespan of the first species", first)
+	}
+	if second.SpeciesId != 2 || second.ParentId != 1 || second.Born != 1 || second.Extinct || second.MaxSize != 10 {
+		t.Error("Wrong lifespan of the second species", second)
+	}
+}
+
+func TestSpeciesHistory_WriteCSV(t *testing.T) {
+	var buff bytes.Buffer
+	if err := buildTestSpeciesHistory().WriteCSV(&buff); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatal("Wrong number of lines", len(lines))
+	}
+	if lines[3] != "1,2,1,1,4,0.7,0.4,0" {
+		t.Error("Wrong record", lines[3])
+	}
+}
+
+func TestSpeciesHistory_WriteJSON(t *testing.T) {
+	var buff bytes.Buffer
+	if err := buildTestSpeciesHistory().WriteJSON(&buff); err != nil {
+		t.Fatal(err)
+	}
+	data := struct {
+		Records []SpeciesRecord
+		Species []SpeciesLifespan
+	}{}
+	if err := json.Unmarshal(buff.Bytes(), &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Records) != 4 || len(data.Species) != 2 || data.Species[1].ParentId != 1 {
+		t.Error("Wrong JSON data", data)
+	}
+}
+
+func TestPopulation_EpochSpeciesHistory(t *testing.T) {
+	rand.Seed(42)
+	conf := neat.NeatContext{
+		CompatThreshold:1.0,
+		DisjointCoeff:1.0,
+		ExcessCoeff:1.0,
+		MutdiffCoeff:0.4,
+		DropOffAge:1,
+		PopSize: 30,
+		MutateOnlyProb:0.25,
+		MutateAddNodeProb:0.1,
+		MutateAddLinkProb:0.1,
+		MateMultipointProb:0.6,
+		MateMultipointAvgProb:0.4,
+		InterspeciesMateRate:0.1,
+		RecurOnlyProb:0.2,
+		NewLinkTries:20,
+		WeightMutPower:1.0,
+	}
+	gen := NewGenomeRand(1, 3, 2, 3, 15, false, 0.8)
+	pop, err := NewPopulation(gen, &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pop.SpeciesHistory = NewSpeciesHistory()
+	generations := 5
+	ids := make(map[int]bool)
+	for i := 1; i <= generations; i++ {
+		for _, o := range pop.Organisms {
+			o.Fitness = rand.Float64()
+		}
+		for _, sp := range pop.Species {
+			ids[sp.Id] = true
+		}
+		if _, err = pop.Epoch(i, &conf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if last := pop.SpeciesHistory.LastGeneration(); last != generations - 1 {
+		t.Error("Wrong last generation", last)
+	}
+	for g := 0; g < generations; g++ {
+		size := 0
+		for _, r := range pop.SpeciesHistory.Generation(g) {
+			size += r.Size
+		}
+		if size != conf.PopSize {
+			t.Error("Wrong total size of species", g, size)
+		}
+	}
+	children := 0
+	for _, ls := range pop.SpeciesHistory.Lifespans() {
+		if ls.ParentId >= 0 {
+			children++
+		}
+		if ls.Born == 0 && ls.ParentId != -1 {
+			t.Error("Initial species with parent", ls)
+		}
+		if ls.ParentId >= 0 && !ids[ls.ParentId] {
+			t.Error("Unknown parent species", ls)
+		}
+	}
+	if children == 0 {
+		t.Error("No species born from the parent ones")
+	}
+}
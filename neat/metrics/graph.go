@@ -0,0 +1,246 @@
+package metrics
+
+// The directed graph of genome's expressed structure, where vertices are indexes of the genome nodes and edges are
+// enabled genes (self loops included)
+type graph struct {
+	// The number of vertices
+	size int
+	// The outgoing adjacency lists
+	out  [][]int
+	// The incoming adjacency lists
+	in   [][]int
+}
+
+func newGraph(size int) *graph {
+	return &graph{
+		size:size,
+		out:make([][]int, size),
+		in:make([][]int, size),
+	}
+}
+
+// Adds directed edge between two vertices
+func (g *graph) addEdge(from, to int) {
+	g.out[from] = append(g.out[from], to)
+	g.in[to] = append(g.in[to], from)
+}
+
+// Finds strongly connected components of the graph using Tarjan's algorithm. Returns the component index for each
+// vertex and the number of components found. The components are numbered in reverse topological order of the
+// condensation graph, i.e. if there is an edge from component a to component b then a > b.
+func (g *graph) stronglyConnected() (comp []int, count int) {
+	index, low := make([]int, g.size), make([]int, g.size)
+	on_stack := make([]bool, g.size)
+	comp = make([]int, g.size)
+	for i := range index {
+		index[i] = -1
+	}
+	stack := make([]int, 0, g.size)
+	next_index := 0
+
+	var connect func(v int)
+	connect = func(v int) {
+		index[v], low[v] = next_index, next_index
+		next_index++
+		stack = append(stack, v)
+		on_stack[v] = true
+
+		for _, w := range g.out[v] {
+			if index[w] < 0 {
+				connect(w)
+				if low[w] < low[v] {
+					low[v] = low[w]
+				}
+			} else if on_stack[w] && index[w] < low[v] {
+				low[v] = index[w]
+			}
+		}
+
+		// v is the root of component - pop it from stack
+		if low[v] == index[v] {
+			for {
+				w := stack[len(stack) - 1]
+				stack = stack[:len(stack) - 1]
+				on_stack[w] = false
+				comp[w] = count
+				if w == v {
+					break
+				}
+			}
+			count++
+		}
+	}
+
+	for v := 0; v < g.size; v++ {
+		if index[v] < 0 {
+			connect(v)
+		}
+	}
+	return comp, count
+}
+
+// Finds the length of the longest path (in edges) from any of the sources to each vertex in the condensation of the
+// graph, i.e. every strongly connected component considered as single vertex. Returns -1 for vertices unreachable
+// from the sources.
+func (g *graph) condensedDepth(sources []int) []int {
+	comp, count := g.stronglyConnected()
+
+	comp_depth := make([]int, count)
+	for i := range comp_depth {
+		comp_depth[i] = -1
+	}
+	for _, s := range sources {
+		comp_depth[comp[s]] = 0
+	}
+	// collect vertices of each component
+	members := make([][]int, count)
+	for v, c := range comp {
+		members[c] = append(members[c], v)
+	}
+	// components numbered in reverse topological order - relax edges starting from the last one
+	for c := count - 1; c >= 0; c-- {
+		if comp_depth[c] < 0 {
+			continue
+		}
+		for _, v := range members[c] {
+			for _, w := range g.out[v] {
+				if comp[w] != c && comp_depth[c] + 1 > comp_depth[comp[w]] {
+					comp_depth[comp[w]] = comp_depth[c] + 1
+				}
+			}
+		}
+	}
+
+	depth := make([]int, g.size)
+	for v := range depth {
+		depth[v] = comp_depth[comp[v]]
+	}
+	return depth
+}
+
+// Returns the number of cycles in the graph estimated as the number of strongly connected components with more than
+// one vertex plus the number of self loops
+func (g *graph) cyclesCount() int {
+	comp, count := g.stronglyConnected()
+	sizes := make([]int, count)
+	for _, c := range comp {
+		sizes[c]++
+	}
+	cycles := 0
+	for _, s := range sizes {
+		if s > 1 {
+			cycles++
+		}
+	}
+	for v := 0; v < g.size; v++ {
+		for _, w := range g.out[v] {
+			if w == v {
+				cycles++
+			}
+		}
+	}
+	return cycles
+}
+
+// Finds the vertices from which at least one of the targets can be reached
+func (g *graph) reachesAny(targets []int) []bool {
+	reaches := make([]bool, g.size)
+	queue := make([]int, 0, g.size)
+	for _, t := range targets {
+		if !reaches[t] {
+			reaches[t] = true
+			queue = append(queue, t)
+		}
+	}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range g.in[v] {
+			if !reaches[w] {
+				reaches[w] = true
+				queue = append(queue, w)
+			}
+		}
+	}
+	return reaches
+}
+
+// Calculates the modularity score Q of the graph considered as undirected using greedy agglomerative algorithm of
+// Clauset, Newman and Moore: starting from each vertex in its own community, the pair of communities which gives
+// the largest increase of modularity is merged until no merge increases it. The self loops are ignored.
+// Returns the modularity of the best partition found and the number of communities in it. The edge weights between
+// communities are kept sparse, thus each merge takes time proportional to the number of edges rather than the square
+// of the number of vertices.
+func (g *graph) modularity() (float64, int) {
+	// build symmetric edge weights between adjacent communities, counting parallel edges once
+	e := make([]map[int]float64, g.size)
+	for i := range e {
+		e[i] = make(map[int]float64)
+	}
+	edges := 0.0
+	for v := 0; v < g.size; v++ {
+		for _, w := range g.out[v] {
+			if _, found := e[v][w]; v != w && !found {
+				e[v][w], e[w][v] = 1, 1
+				edges++
+			}
+		}
+	}
+	if edges == 0 {
+		return 0, g.size
+	}
+
+	// convert to fractions of edge ends: e[i][j] - half the fraction of edges between i and j, a[i] - fraction of
+	// edge ends attached to community i
+	a := make([]float64, g.size)
+	for i := range e {
+		for j := range e[i] {
+			e[i][j] /= 2 * edges
+			a[i] += e[i][j]
+		}
+	}
+
+	// each vertex in its own community initially, thus there are no edges within communities
+	q := 0.0
+	for i := range a {
+		q -= a[i] * a[i]
+	}
+
+	communities := g.size
+	for {
+		// find the best pair of adjacent communities, the ties are resolved in favor of the lowest indexes to keep
+		// the result independent of the order of iteration over edges
+		best_dq, best_i, best_j := 0.0, -1, -1
+		for i := 0; i < g.size; i++ {
+			for j, e_ij := range e[i] {
+				if j <= i {
+					continue
+				}
+				dq := 2 * (e_ij - a[i] * a[j])
+				if dq > best_dq || (dq == best_dq && best_i == i && j < best_j) {
+					best_dq, best_i, best_j = dq, i, j
+				}
+			}
+		}
+		if best_i < 0 {
+			break
+		}
+		// merge community j into i, the modularity change accounts both for new edges within community and for
+		// increased fraction of edge ends attached to it
+		q += best_dq
+		for k, e_jk := range e[best_j] {
+			delete(e[k], best_j)
+			if k == best_i {
+				continue
+			}
+			e[best_i][k] += e_jk
+			e[k][best_i] = e[best_i][k]
+		}
+		delete(e[best_i], best_j)
+		e[best_j] = nil
+		a[best_i] += a[best_j]
+		a[best_j] = 0
+		communities--
+	}
+	return q, communities
+}
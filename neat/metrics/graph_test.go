@@ -0,0 +1,164 @@
+package metrics
+
+import (
+	"testing"
+	"math"
+	"math/rand"
+)
+
+func TestGraph_stronglyConnected(t *testing.T) {
+	// 0 -> 1 -> 2 -> 1, 2 -> 3, 3 -> 3
+	gr := newGraph(4)
+	gr.addEdge(0, 1)
+	gr.addEdge(1, 2)
+	gr.addEdge(2, 1)
+	gr.addEdge(2, 3)
+	gr.addEdge(3, 3)
+
+	comp, count := gr.stronglyConnected()
+	if count != 3 {
+		t.Error("Wrong number of components", 3, count)
+	}
+	if comp[1] != comp[2] {
+		t.Error("Nodes 1 and 2 should be in the same component")
+	}
+	// check reverse topological order
+	if !(comp[0] > comp[1] && comp[1] > comp[3]) {
+		t.Error("Wrong components order", comp)
+	}
+
+	if cycles := gr.cyclesCount(); cycles != 2 {
+		t.Error("Wrong number of cycles", 2, cycles)
+	}
+
+	depth := gr.condensedDepth([]int{0})
+	expected := []int{0, 1, 1, 2}
+	for i, d := range depth {
+		if d != expected[i] {
+			t.Error("Wrong depth of node", i, expected[i], d)
+		}
+	}
+}
+
+func TestGraph_modularity(t *testing.T) {
+	// two triangles connected by single edge
+	gr := newGraph(6)
+	gr.addEdge(0, 1)
+	gr.addEdge(1, 2)
+	gr.addEdge(2, 0)
+	gr.addEdge(3, 4)
+	gr.addEdge(4, 5)
+	gr.addEdge(5, 3)
+	gr.addEdge(2, 3)
+
+	q, modules := gr.modularity()
+	expected := 2.0 * (3.0 / 7.0 - 0.25)
+	if math.Abs(q - expected) > 1e-9 {
+		t.Error("Wrong modularity", expected, q)
+	}
+	if modules != 2 {
+		t.Error("Wrong number of modules", 2, modules)
+	}
+
+	// graph without edges
+	q, modules = newGraph(3).modularity()
+	if q != 0 || modules != 3 {
+		t.Error("Wrong modularity of empty graph", q, modules)
+	}
+}
+
+func TestGraph_modularityRandom(t *testing.T) {
+	rand.Seed(42)
+	for n := 2; n < 60; n += 3 {
+		gr := newGraph(n)
+		for i := 0; i < 2 * n; i++ {
+			gr.addEdge(rand.Intn(n), rand.Intn(n))
+		}
+		q, modules := gr.modularity()
+		expected_q, expected_modules := denseModularity(gr)
+		if math.Abs(q - expected_q) > 1e-9 || modules != expected_modules {
+			t.Error("Wrong modularity of random graph", n, expected_q, q, expected_modules, modules)
+		}
+	}
+}
+
+// The reference implementation of modularity using dense matrix of edge weights between communities
+func denseModularity(g *graph) (float64, int) {
+	// build symmetric edge weights matrix between communities, counting parallel edges once
+	e := make([][]float64, g.size)
+	for i := range e {
+		e[i] = make([]float64, g.size)
+	}
+	edges := 0.0
+	for v := 0; v < g.size; v++ {
+		for _, w := range g.out[v] {
+			if v != w && e[v][w] == 0 {
+				e[v][w], e[w][v] = 1, 1
+				edges++
+			}
+		}
+	}
+	if edges == 0 {
+		return 0, g.size
+	}
+
+	// convert to fractions of edge ends: e[i][j] - half the fraction of edges between i and j, a[i] - fraction of
+	// edge ends attached to community i
+	a := make([]float64, g.size)
+	for i := range e {
+		for j := range e[i] {
+			e[i][j] /= 2 * edges
+			a[i] += e[i][j]
+		}
+	}
+
+	// each vertex in its own community initially, thus there are no edges within communities
+	q := 0.0
+	for i := range a {
+		q -= a[i] * a[i]
+	}
+
+	alive := make([]bool, g.size)
+	for i := range alive {
+		alive[i] = true
+	}
+	communities := g.size
+	for {
+		best_dq, best_i, best_j := 0.0, -1, -1
+		for i := 0; i < g.size; i++ {
+			if !alive[i] {
+				continue
+			}
+			for j := i + 1; j < g.size; j++ {
+				if !alive[j] || e[i][j] == 0 {
+					continue
+				}
+				dq := 2 * (e[i][j] - a[i] * a[j])
+				if dq > best_dq {
+					best_dq, best_i, best_j = dq, i, j
+				}
+			}
+		}
+		if best_i < 0 {
+			break
+		}
+		// merge community j into i, the modularity change accounts both for new edges within community and for
+		// increased fraction of edge ends attached to it
+		q += best_dq
+		for k := 0; k < g.size; k++ {
+			if k == best_i || k == best_j {
+				continue
+			}
+			e[best_i][k] += e[best_j][k]
+			e[k][best_i] = e[best_i][k]
+			e[best_j][k], e[k][best_j] = 0, 0
+		}
+		e[best_i][best_i] += e[best_j][best_j] + 2 * e[best_i][best_j]
+		e[best_i][best_j], e[best_j][best_i] = 0, 0
+		a[best_i] += a[best_j]
+		a[best_j] = 0
+		alive[best_j] = false
+		communities--
+	}
+	return q, communities
+}
@@ -0,0 +1,247 @@
+// Package metrics provides complexity metrics of the genomes and their expressed network structure which can be used
+// to study the growth of solutions complexity (bloat) during evolution.
+package metrics
+
+import (
+	"github.com/yaricom/goNEAT/neat/genetics"
+	"github.com/yaricom/goNEAT/neat/network"
+	"fmt"
+)
+
+// The distribution of node degrees, where index is the degree (the number of links) and value is the number of nodes
+// with such degree
+type Distribution []int
+
+// Returns the mean degree of nodes
+func (d Distribution) Mean() float64 {
+	total, count := 0, 0
+	for degree, nodes := range d {
+		total += degree * nodes
+		count += nodes
+	}
+	if count == 0 {
+		return 0
+	}
+	return float64(total) / float64(count)
+}
+
+// Returns the maximal degree of nodes
+func (d Distribution) Max() int {
+	for degree := len(d) - 1; degree >= 0; degree-- {
+		if d[degree] > 0 {
+			return degree
+		}
+	}
+	return 0
+}
+
+// Adds node with given degree to the distribution
+func (d Distribution) add(degree int) Distribution {
+	for len(d) <= degree {
+		d = append(d, 0)
+	}
+	d[degree]++
+	return d
+}
+
+// The complexity metrics of the genome. All structural metrics are calculated over the expressed structure, i.e.
+// over the enabled genes only.
+type GenomeMetrics struct {
+	// The number of nodes in the genome
+	Nodes          int
+	// The number of hidden nodes in the genome
+	HiddenNodes    int
+	// The number of enabled genes
+	EnabledGenes   int
+	// The number of disabled genes
+	DisabledGenes  int
+	// The number of enabled recurrent links
+	RecurrentLinks int
+
+	// The distribution of the neurons fan-in (number of incoming links)
+	FanIn          Distribution
+	// The distribution of the nodes fan-out (number of outgoing links)
+	FanOut         Distribution
+
+	// The depth of the network: the maximal number of links along the path from any sensor to any output where each
+	// loop (strongly connected component) is collapsed into single node. Equals to the Network.MaxDepth for the
+	// networks without loops.
+	Depth          int
+	// The number of loops in the network: strongly connected components with more than one node and self loops
+	Cycles         int
+
+	// The modularity score Q of the network partition found by greedy modularity optimization
+	Modularity     float64
+	// The number of modules (communities) in the partition found
+	Modules        int
+
+	// The number of hidden nodes without any enabled links
+	IsolatedHidden int
+	// The number of hidden nodes which have links but their signal never reach any output
+	DeadEndHidden  int
+}
+
+// Calculates complexity metrics of the given genome
+func NewGenomeMetrics(g *genetics.Genome) *GenomeMetrics {
+	m := GenomeMetrics{
+		Nodes:len(g.Nodes),
+		FanIn:make(Distribution, 0),
+		FanOut:make(Distribution, 0),
+	}
+
+	// map node IDs to the graph vertices
+	indexes := make(map[int]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		indexes[n.Id] = i
+	}
+	gr := newGraph(len(g.Nodes))
+	for _, gene := range g.Genes {
+		if !gene.IsEnabled {
+			m.DisabledGenes++
+			continue
+		}
+		m.EnabledGenes++
+		if gene.Link.IsRecurrent {
+			m.RecurrentLinks++
+		}
+		in, in_found := indexes[gene.Link.InNode.Id]
+		out, out_found := indexes[gene.Link.OutNode.Id]
+		if in_found && out_found {
+			gr.addEdge(in, out)
+		}
+	}
+
+	sensors, outputs := make([]int, 0), make([]int, 0)
+	for i, n := range g.Nodes {
+		if n.IsSensor() {
+			sensors = append(sensors, i)
+		} else {
+			m.FanIn = m.FanIn.add(len(gr.in[i]))
+		}
+		if n.NeuronType == network.OutputNeuron {
+			outputs = append(outputs, i)
+		} else {
+			m.FanOut = m.FanOut.add(len(gr.out[i]))
+		}
+	}
+
+	depth := gr.condensedDepth(sensors)
+	for _, o := range outputs {
+		if depth[o] > m.Depth {
+			m.Depth = depth[o]
+		}
+	}
+	m.Cycles = gr.cyclesCount()
+	m.Modularity, m.Modules = gr.modularity()
+
+	reaches_output := gr.reachesAny(outputs)
+	for i, n := range g.Nodes {
+		if n.NeuronType != network.HiddenNeuron {
+			continue
+		}
+		m.HiddenNodes++
+		if len(gr.in[i]) == 0 && len(gr.out[i]) == 0 {
+			m.IsolatedHidden++
+		} else if !reaches_output[i] {
+			m.DeadEndHidden++
+		}
+	}
+	return &m
+}
+
+// Returns string representation of metrics
+func (m *GenomeMetrics) String() string {
+	return fmt.Sprintf("nodes: %d, hidden: %d, genes enabled/disabled: %d/%d, recurrent: %d, fan-in: %.2f (max %d), " +
+		"fan-out: %.2f (max %d), depth: %d, cycles: %d, modularity: %.3f (%d modules), isolated: %d, dead-end: %d",
+		m.Nodes, m.HiddenNodes, m.EnabledGenes, m.DisabledGenes, m.RecurrentLinks, m.FanIn.Mean(), m.FanIn.Max(),
+		m.FanOut.Mean(), m.FanOut.Max(), m.Depth, m.Cycles, m.Modularity, m.Modules, m.IsolatedHidden,
+		m.DeadEndHidden)
+}
+
+// The average complexity metrics of the organisms in population
+type PopulationMetrics struct {
+	// The number of organisms averaged
+	Organisms      int     `json:"organisms"`
+
+	// The average values of the corresponding genome metrics
+	Nodes          float64 `json:"nodes"`
+	HiddenNodes    float64 `json:"hidden_nodes"`
+	EnabledGenes   float64 `json:"enabled_genes"`
+	DisabledGenes  float64 `json:"disabled_genes"`
+	RecurrentLinks float64 `json:"recurrent_links"`
+	FanIn          float64 `json:"fan_in"`
+	FanOut         float64 `json:"fan_out"`
+	Depth          float64 `json:"depth"`
+	Cycles         float64 `json:"cycles"`
+	Modularity     float64 `json:"modularity"`
+	Modules        float64 `json:"modules"`
+	IsolatedHidden float64 `json:"isolated_hidden"`
+	DeadEndHidden  float64 `json:"dead_end_hidden"`
+
+	// The maximal values of fan-in, fan-out and depth found in population
+	MaxFanIn       int     `json:"max_fan_in"`
+	MaxFanOut      int     `json:"max_fan_out"`
+	MaxDepth       int     `json:"max_depth"`
+}
+
+// Calculates the average complexity metrics of the given organisms
+func NewPopulationMetrics(organisms []*genetics.Organism) *PopulationMetrics {
+	pm := PopulationMetrics{}
+	for _, org := range organisms {
+		if org.Genotype == nil {
+			continue
+		}
+		m := NewGenomeMetrics(org.Genotype)
+		pm.Organisms++
+		pm.Nodes += float64(m.Nodes)
+		pm.HiddenNodes += float64(m.HiddenNodes)
+		pm.EnabledGenes += float64(m.EnabledGenes)
+		pm.DisabledGenes += float64(m.DisabledGenes)
+		pm.RecurrentLinks += float64(m.RecurrentLinks)
+		pm.FanIn += m.FanIn.Mean()
+		pm.FanOut += m.FanOut.Mean()
+		pm.Depth += float64(m.Depth)
+		pm.Cycles += float64(m.Cycles)
+		pm.Modularity += m.Modularity
+		pm.Modules += float64(m.Modules)
+		pm.IsolatedHidden += float64(m.IsolatedHidden)
+		pm.DeadEndHidden += float64(m.DeadEndHidden)
+
+		if max := m.FanIn.Max(); max > pm.MaxFanIn {
+			pm.MaxFanIn = max
+		}
+		if max := m.FanOut.Max(); max > pm.MaxFanOut {
+			pm.MaxFanOut = max
+		}
+		if m.Depth > pm.MaxDepth {
+			pm.MaxDepth = m.Depth
+		}
+	}
+	if pm.Organisms > 0 {
+		count := float64(pm.Organisms)
+		pm.Nodes /= count
+		pm.HiddenNodes /= count
+		pm.EnabledGenes /= count
+		pm.DisabledGenes /= count
+		pm.RecurrentLinks /= count
+		pm.FanIn /= count
+		pm.FanOut /= count
+		pm.Depth /= count
+		pm.Cycles /= count
+		pm.Modularity /= count
+		pm.Modules /= count
+		pm.IsolatedHidden /= count
+		pm.DeadEndHidden /= count
+	}
+	return &pm
+}
+
+// Returns string representation of population metrics
+func (pm *PopulationMetrics) String() string {
+	return fmt.Sprintf("organisms: %d, nodes: %.1f, hidden: %.1f, genes enabled/disabled: %.1f/%.1f, " +
+		"recurrent: %.1f, fan-in: %.2f (max %d), fan-out: %.2f (max %d), depth: %.1f (max %d), cycles: %.1f, " +
+		"modularity: %.3f (%.1f modules), isolated: %.1f, dead-end: %.1f",
+		pm.Organisms, pm.Nodes, pm.HiddenNodes, pm.EnabledGenes, pm.DisabledGenes, pm.RecurrentLinks,
+		pm.FanIn, pm.MaxFanIn, pm.FanOut, pm.MaxFanOut, pm.Depth, pm.MaxDepth, pm.Cycles, pm.Modularity,
+		pm.Modules, pm.IsolatedHidden, pm.DeadEndHidden)
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"testing"
+	"strings"
+	"math"
+	"github.com/yaricom/goNEAT/neat/genetics"
+)
+
+const test_genome_str = "genomestart 1\n" +
+	"trait 1 0.1 0 0 0 0 0 0 0\n" +
+	"node 1 0 1 1\n" +
+	"node 2 0 1 1\n" +
+	"node 3 0 1 3\n" +
+	"node 4 0 0 2\n" +
+	"node 5 0 0 0\n" +
+	"node 6 0 0 0\n" +
+	"node 7 0 0 0\n" +
+	"node 8 0 0 0\n" +
+	"gene 1 1 5 1.0 false 1 0 true\n" +
+	"gene 1 2 5 1.0 false 2 0 true\n" +
+	"gene 1 5 6 1.0 false 3 0 true\n" +
+	"gene 1 6 5 1.0 true 4 0 true\n" +
+	"gene 1 6 4 1.0 false 5 0 true\n" +
+	"gene 1 3 4 1.0 false 6 0 true\n" +
+	"gene 1 6 6 1.0 true 7 0 true\n" +
+	"gene 1 1 8 1.0 false 8 0 true\n" +
+	"gene 1 2 4 1.0 false 9 0 false\n" +
+	"genomeend 1"
+
+func TestNewGenomeMetrics(t *testing.T) {
+	genome, err := genetics.ReadGenome(strings.NewReader(test_genome_str), 1)
+	if err != nil {
+		t.Error("Failed to read genome", err)
+		return
+	}
+
+	m := NewGenomeMetrics(genome)
+	if m.Nodes != 8 || m.HiddenNodes != 4 {
+		t.Error("Wrong nodes count", m.Nodes, m.HiddenNodes)
+	}
+	if m.EnabledGenes != 8 || m.DisabledGenes != 1 {
+		t.Error("Wrong genes count", m.EnabledGenes, m.DisabledGenes)
+	}
+	if m.RecurrentLinks != 2 {
+		t.Error("Wrong recurrent links count", 2, m.RecurrentLinks)
+	}
+	if m.FanIn.Max() != 3 || math.Abs(m.FanIn.Mean() - 1.6) > 1e-9 {
+		t.Error("Wrong fan-in distribution", m.FanIn)
+	}
+	if m.FanOut.Max() != 3 || math.Abs(m.FanOut.Mean() - 8.0 / 7.0) > 1e-9 {
+		t.Error("Wrong fan-out distribution", m.FanOut)
+	}
+	if m.Depth != 2 {
+		t.Error("Wrong depth", 2, m.Depth)
+	}
+	if m.Cycles != 2 {
+		t.Error("Wrong cycles count", 2, m.Cycles)
+	}
+	if m.IsolatedHidden != 1 || m.DeadEndHidden != 1 {
+		t.Error("Wrong isolated/dead-end nodes count", m.IsolatedHidden, m.DeadEndHidden)
+	}
+	if m.Modularity <= 0 || m.Modules < 2 {
+		t.Error("Wrong modularity", m.Modularity, m.Modules)
+	}
+}
+
+func TestNewGenomeMetrics_depthEqualsMaxDepth(t *testing.T) {
+	genome, err := genetics.ReadGenome(strings.NewReader(test_genome_str), 1)
+	if err != nil {
+		t.Error("Failed to read genome", err)
+		return
+	}
+	// remove loops to compare with network depth
+	genes := make([]*genetics.Gene, 0)
+	for _, g := range genome.Genes {
+		if !g.Link.IsRecurrent && g.IsEnabled {
+			genes = append(genes, g)
+		}
+	}
+	genome.Genes = genes
+	org := genetics.NewOrganism(0, genome, 1)
+	max_depth, err := org.Phenotype.MaxDepth()
+	if err != nil {
+		t.Error(err)
+	}
+
+	m := NewGenomeMetrics(genome)
+	if m.Depth != max_depth {
+		t.Error("Depth is not equal to MaxDepth", max_depth, m.Depth)
+	}
+	if m.Cycles != 0 {
+		t.Error("No cycles expected", m.Cycles)
+	}
+}
+
+func TestNewPopulationMetrics(t *testing.T) {
+	organisms := make([]*genetics.Organism, 3)
+	for i := range organisms {
+		genome, err := genetics.ReadGenome(strings.NewReader(test_genome_str), 1)
+		if err != nil {
+			t.Error("Failed to read genome", err)
+			return
+		}
+		organisms[i] = genetics.NewOrganism(0, genome, 1)
+	}
+	pm := NewPopulationMetrics(organisms)
+	if pm.Organisms != 3 {
+		t.Error("Wrong number of organisms", 3, pm.Organisms)
+	}
+	if pm.EnabledGenes != 8 || pm.Depth != 2 || pm.MaxDepth != 2 || pm.MaxFanIn != 3 {
+		t.Error("Wrong average metrics", pm)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
 )
 
 // LoggerLevel type to specify logger output level
@@ -24,6 +25,32 @@ const (
 	LogLevelError
 )
 
+// The policy to restart population of trial when its evolution stagnated
+type RestartPolicy byte
+
+const (
+	// The trial stops when stagnated
+	NoRestart RestartPolicy = iota
+	// The population reseeded from the start genome
+	RestartFromStartGenome
+	// The population reseeded from the genomes kept in the hall of fame
+	RestartFromHallOfFame
+)
+
+// Returns the name of restart policy
+func (p RestartPolicy) String() string {
+	switch p {
+	case NoRestart:
+		return "none"
+	case RestartFromStartGenome:
+		return "start_genome"
+	case RestartFromHallOfFame:
+		return "hall_of_fame"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(p))
+	}
+}
+
 var (
 	// The current log level of the context
 	LogLevel LoggerLevel
@@ -68,6 +95,13 @@ type NeatContext struct {
 	TraitMutationPower     float64
 				       // The power of a linkweight mutation
 	WeightMutPower         float64
+				       // The power of a node bias mutation
+	NodeBiasMutPower       float64
+				       // The power of a CTRNN neuron time constant mutation
+	TimeConstantMutPower   float64
+				       // The range of allowed values for CTRNN neuron time constant
+	TimeConstantMin        float64
+	TimeConstantMax        float64
 
 				       // These 3 global coefficients are used to determine the formula for
 				       // computing the compatibility between 2 genomes.  The formula is:
@@ -102,6 +136,8 @@ type NeatContext struct {
 	MutateAddNodeProb      float64
 	MutateAddLinkProb      float64
 	MutateConnectSensors   float64 // probability of mutation involving disconnected inputs connection
+	MutateNodeBiasProb     float64 // probability of mutating bias values of the neuron nodes
+	MutateTimeConstantProb float64 // probability of mutating CTRNN time constants of the neuron nodes
 
 				       // Probabilities of a mate being outside species
 	InterspeciesMateRate   float64
@@ -113,6 +149,17 @@ type NeatContext struct {
 	MateOnlyProb           float64
 				       // Probability of forcing selection of ONLY links that are naturally recurrent
 	RecurOnlyProb          float64
+				       // The structural constraints of genomes respected by mutations and crossover
+	Constraints            Constraints
+
+				       // If true the mutation and mating probabilities will be adapted every epoch according to
+				       // the success rates of offspring produced by each reproduction operator
+	AdaptiveOperators      bool
+				       // The learning rate of reproduction operators probabilities adaptation
+	AdaptiveOperatorsRate  float64
+				       // The floor and ceiling of the adapted reproduction operators probabilities
+	AdaptiveOperatorsMin   float64
+	AdaptiveOperatorsMax   float64
 
 				       // Size of population
 	PopSize                int
@@ -126,17 +173,45 @@ type NeatContext struct {
 
 				       // The number of babies to stolen off to the champions
 	BabiesStolen           int
+				       // The shares of stolen babies given to the champions of the top three species, negative
+				       // to use the default of 1/5, 1/5 and 1/10 respectively. The zero share gives nothing to
+				       // the champion. LoadContext sets the negative shares unless they are configured.
+	BabiesStolenShares     [3]float64
+				       // The maximal number of stolen babies given to the champion of each species after the
+				       // top three, zero to use the default of 3
+	BabiesStolenBlock      int
+				       // The number of generations of population stagnation after DropOffAge when delta coding
+				       // performed, zero to use the default of 5 and negative to disable delta coding
+	DeltaCodingWindow      int
+				       // The share of population given to the best species by delta coding, the rest goes to the
+				       // second one, zero to split population in halves
+	DeltaCodingSplit       float64
 
 				       // The number of runs to average over in an experiment
 	NumRuns                int
 
 				       // The number of epochs (generations) to execute training
 	NumGenerations         int
+
+				       // The trial stops when the fitness of the best organism reaches this value, zero to ignore
+	FitnessTarget          float64
+				       // The wall-clock time budget of trial in seconds, zero to ignore
+	TimeBudget             float64
+				       // The maximal number of organisms evaluated in trial, zero to ignore
+	EvaluationsBudget      int
+				       // The number of generations without improvement of the best fitness after which trial
+				       // population considered stagnated, zero to ignore
+	StagnationGenerations  int
+				       // The policy to restart stagnated population, the trial stops when stagnated if no restart
+	RestartPolicy          RestartPolicy
+				       // The number of the best genomes kept in the hall of fame of trial
+	HallOfFameSize         int
 }
 
 // Loads context configuration from provided reader
 func LoadContext(r io.Reader) *NeatContext {
-	c := NeatContext{}
+	// the shares of stolen babies are defaulted by negative values, because zero share is valid
+	c := NeatContext{BabiesStolenShares:[3]float64{-1, -1, -1}}
 	// read configuration
 	var name string
 	var param float64;
@@ -152,6 +227,14 @@ func LoadContext(r io.Reader) *NeatContext {
 			c.TraitMutationPower = param
 		case "weight_mut_power":
 			c.WeightMutPower = param
+		case "node_bias_mut_power":
+			c.NodeBiasMutPower = param
+		case "time_constant_mut_power":
+			c.TimeConstantMutPower = param
+		case "time_constant_min":
+			c.TimeConstantMin = param
+		case "time_constant_max":
+			c.TimeConstantMax = param
 		case "disjoint_coeff":
 			c.DisjointCoeff = param
 		case "excess_coeff":
@@ -184,6 +267,10 @@ func LoadContext(r io.Reader) *NeatContext {
 			c.MutateAddLinkProb = param
 		case "mutate_connect_sensors":
 			c.MutateConnectSensors = param
+		case "mutate_node_bias_prob":
+			c.MutateNodeBiasProb = param
+		case "mutate_time_constant_prob":
+			c.MutateTimeConstantProb = param
 		case "interspecies_mate_rate":
 			c.InterspeciesMateRate = param
 		case "mate_multipoint_prob":
@@ -196,6 +283,22 @@ func LoadContext(r io.Reader) *NeatContext {
 			c.MateOnlyProb = param
 		case "recur_only_prob":
 			c.RecurOnlyProb = param
+		case "max_hidden_nodes":
+			c.Constraints.MaxHiddenNodes = int(param)
+		case "max_enabled_genes":
+			c.Constraints.MaxEnabledGenes = int(param)
+		case "max_fan_in":
+			c.Constraints.MaxFanIn = int(param)
+		case "feed_forward_only":
+			c.Constraints.FeedForwardOnly = param > 0
+		case "adaptive_operators":
+			c.AdaptiveOperators = param > 0
+		case "adaptive_operators_rate":
+			c.AdaptiveOperatorsRate = param
+		case "adaptive_operators_min":
+			c.AdaptiveOperatorsMin = param
+		case "adaptive_operators_max":
+			c.AdaptiveOperatorsMax = param
 		case "pop_size":
 			c.PopSize = int(param)
 		case "dropoff_age":
@@ -206,13 +309,46 @@ func LoadContext(r io.Reader) *NeatContext {
 			c.PrintEvery = int(param)
 		case "babies_stolen":
 			c.BabiesStolen = int(param)
+		case "babies_stolen_share_1":
+			c.BabiesStolenShares[0] = param
+		case "babies_stolen_share_2":
+			c.BabiesStolenShares[1] = param
+		case "babies_stolen_share_3":
+			c.BabiesStolenShares[2] = param
+		case "babies_stolen_block":
+			c.BabiesStolenBlock = int(param)
+		case "delta_coding_window":
+			c.DeltaCodingWindow = int(param)
+		case "delta_coding_split":
+			c.DeltaCodingSplit = param
 		case "num_runs":
 			c.NumRuns = int(param)
 		case "num_generations":
 			c.NumGenerations = int(param)
+		case "fitness_target":
+			c.FitnessTarget = param
+		case "time_budget":
+			c.TimeBudget = param
+		case "evaluations_budget":
+			c.EvaluationsBudget = int(param)
+		case "stagnation_generations":
+			c.StagnationGenerations = int(param)
+		case "restart_policy":
+			c.RestartPolicy = RestartPolicy(param)
+		case "hall_of_fame_size":
+			c.HallOfFameSize = int(param)
 		case "log_level":
 			LogLevel = LoggerLevel(param)
 		default:
+			if strings.HasPrefix(name, "ban_") {
+				in_type, out_type, err := parseBannedLink(name)
+				if err == nil {
+					if param > 0 {
+						c.Constraints.BanLinks(in_type, out_type)
+					}
+					continue
+				}
+			}
 			fmt.Printf("WARNING! Unknown configuration parameter found: %s = %f\n", name, param)
 		}
 	}
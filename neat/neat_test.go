@@ -3,6 +3,7 @@ package neat
 import (
 	"testing"
 	"os"
+	"strings"
 )
 
 func TestLoadContext(t *testing.T) {
@@ -104,7 +105,53 @@ func TestLoadContext(t *testing.T) {
 	if nc.BabiesStolen != 0 {
 		t.Error("BabiesStolen")
 	}
+	if nc.BabiesStolenShares != [3]float64{-1, -1, -1} {
+		t.Error("BabiesStolenShares", nc.BabiesStolenShares)
+	}
 	if nc.NumRuns != 100 {
 		t.Error("NumRuns")
 	}
-}
\ No newline at end of file
+}
+func TestLoadContext_Termination(t *testing.T) {
+	config := strings.NewReader("fitness_target 15.5\ntime_budget 60\nevaluations_budget 10000\n" +
+		"stagnation_generations 20\nrestart_policy 2\nhall_of_fame_size 5\n")
+	nc := LoadContext(config)
+	if nc.FitnessTarget != 15.5 || nc.TimeBudget != 60 || nc.EvaluationsBudget != 10000 {
+		t.Error("Wrong termination criteria", nc.FitnessTarget, nc.TimeBudget, nc.EvaluationsBudget)
+	}
+	if nc.StagnationGenerations != 20 || nc.RestartPolicy != RestartFromHallOfFame || nc.HallOfFameSize != 5 {
+		t.Error("Wrong restart policy", nc.StagnationGenerations, nc.RestartPolicy, nc.HallOfFameSize)
+	}
+	if nc.RestartPolicy.String() != "hall_of_fame" {
+		t.Error("Wrong restart policy name", nc.RestartPolicy)
+	}
+}
+
+func TestLoadContext_OffspringAllocation(t *testing.T) {
+	config := strings.NewReader("babies_stolen 20\nbabies_stolen_share_1 0.5\nbabies_stolen_share_2 0.25\n" +
+		"babies_stolen_share_3 0.125\nbabies_stolen_block 2\ndelta_coding_window -1\ndelta_coding_split 0.75\n")
+	nc := LoadContext(config)
+	if nc.BabiesStolen != 20 || nc.BabiesStolenShares != [3]float64{0.5, 0.25, 0.125} || nc.BabiesStolenBlock != 2 {
+		t.Error("Wrong baby stealing", nc.BabiesStolen, nc.BabiesStolenShares, nc.BabiesStolenBlock)
+	}
+	if nc.DeltaCodingWindow != -1 || nc.DeltaCodingSplit != 0.75 {
+		t.Error("Wrong delta coding", nc.DeltaCodingWindow, nc.DeltaCodingSplit)
+	}
+}
+
+func TestLoadContext_Constraints(t *testing.T) {
+	config := strings.NewReader("max_hidden_nodes 10\nmax_enabled_genes 40\nmax_fan_in 4\nfeed_forward_only 1\n" +
+		"ban_input_output 1\nban_hidden_output 0\nban_bias_hidden 1\n")
+	nc := LoadContext(config)
+	c := nc.Constraints
+	if c.MaxHiddenNodes != 10 || c.MaxEnabledGenes != 40 || c.MaxFanIn != 4 || !c.FeedForwardOnly {
+		t.Error("Wrong constraints", c)
+	}
+	if len(c.BannedLinks) != 2 || !c.IsLinkBanned(1, 2) || !c.IsLinkBanned(3, 0) || c.IsLinkBanned(0, 2) {
+		t.Error("Wrong banned links", c.BannedLinks)
+	}
+
+	if _, _, err := parseBannedLink("ban_input_sensor"); err == nil {
+		t.Error("Unknown neuron type accepted")
+	}
+}
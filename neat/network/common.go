@@ -1,7 +1,10 @@
 // The package network provides data holders and utilities to describe Artificial Neural Network
 package network
 
-import "math"
+import (
+	"math"
+	"errors"
+)
 
 // NNodeType defines the type of NNode to create
 type NodeType byte
@@ -26,6 +29,35 @@ func NodeTypeName(ntype NodeType) string {
 	}
 }
 
+var (
+	// The error returned when network outputs was not activated after maximal number of activation attempts, which
+	// is the case when outputs are disconnected from inputs
+	ErrNetActivationAborted = errors.New("Network activation aborted: outputs are not reachable from inputs")
+	// The error returned when network outputs was not settled within maximal number of relaxation iterations
+	ErrNetRelaxationNotConverged = errors.New("Network relaxation not converged: outputs still changing")
+)
+
+// The maximal number of activation attempts to make all outputs active
+const maxActivationAttempts = 20
+
+// Finds maximal absolute difference between corresponding values of two vectors of neurons state, where the neurons
+// not activated yet hold NaN. The difference is infinite if any neuron was activated between the states.
+func statesDelta(prev, curr []float64) float64 {
+	delta := 0.0
+	for i, v := range curr {
+		if math.IsNaN(v) != math.IsNaN(prev[i]) {
+			return math.Inf(1)
+		}
+		if d := math.Abs(v - prev[i]); d > delta {
+			delta = d
+		}
+	}
+	return delta
+}
+
+// The default time constant of the neuron used by continuous-time recurrent neural network (CTRNN) activation
+const DefaultTimeConstant = 1.0
+
 // NeuronType defines the type of neuron to create
 type NeuronType byte
 
@@ -2,7 +2,9 @@ package network
 
 import (
 	"fmt"
+	"math"
 	"bytes"
+	"errors"
 )
 
 // A NETWORK is a LIST of input NODEs and a LIST of output NODEs.
@@ -23,6 +25,9 @@ type Network struct {
 	Inputs    []*NNode
 	// NNodes that output from the network
 	Outputs   []*NNode
+
+	// The optional tracer to record activations of network nodes, nil if tracing disabled
+	Tracer    *ActivationTracer
 }
 
 // Creates new network
@@ -86,10 +91,9 @@ func (n *Network) OutputIsOff() bool {
 	return false
 }
 
-// Activates the net such that all outputs are active
+// Activates the net such that all outputs are active. Returns ErrNetActivationAborted if outputs was not activated
+// after maximal number of attempts, i.e. when inputs disconnected from outputs.
 func (n *Network) Activate() (bool, error) {
-	//For adding to the activesum
-	add_amount := 0.0
 	//Make sure we at least activate once
 	one_time := false
 	//Used in case the output is somehow truncated from the network
@@ -100,52 +104,215 @@ func (n *Network) Activate() (bool, error) {
 	for n.OutputIsOff() || !one_time {
 		abort_count += 1
 
-		if abort_count >= 20 {
-			return false, nil//errors.New("Inputs disconnected from outputs!")
+		if abort_count >= maxActivationAttempts {
+			if n.Tracer != nil {
+				n.Tracer.record(n)
+			}
+			return false, ErrNetActivationAborted
+		}
+		n.activateStep()
+		one_time = true
+	}
+	if n.Tracer != nil {
+		n.Tracer.record(n)
+	}
+	return true, nil
+}
+
+// Loads provided inputs into the sensors and advances the network by exactly one activation tick, i.e. the signal
+// propagates only one link further through the network and recurrent state is updated once. Returns the values of
+// network outputs after the tick. Note, that outputs can be inactive yet if network depth is greater than number of
+// performed steps.
+func (n *Network) Step(inputs []float64) ([]float64, error) {
+	if err := n.checkInputs(inputs); err != nil {
+		return nil, err
+	}
+	n.LoadSensors(inputs)
+	n.activateStep()
+	if n.Tracer != nil {
+		n.Tracer.record(n)
+	}
+	return n.ReadOutputs(), nil
+}
+
+// Flushes the network, loads provided inputs into the sensors and activates it until all outputs are active and
+// activations of all neurons changed less than tolerance between consecutive ticks, but no more than maxIters times.
+// The activations of hidden neurons are compared as well, since the signal can still travel through them while
+// outputs are unchanged. Returns the values of network outputs. If outputs was not activated within maxIters ticks the ErrNetActivationAborted returned;
+// if outputs was activated but not settled the ErrNetRelaxationNotConverged returned along with last output values,
+// which is the case of oscillating recurrent networks.
+func (n *Network) Relax(inputs []float64, maxIters int, tolerance float64) ([]float64, error) {
+	if err := n.checkInputs(inputs); err != nil {
+		return nil, err
+	}
+	if maxIters <= 0 {
+		return nil, errors.New(fmt.Sprintf("Wrong maximal number of relaxation iterations: %d", maxIters))
+	}
+	n.Flush()
+	n.LoadSensors(inputs)
+	if n.Tracer != nil {
+		// record only the final state of relaxed network
+		defer n.Tracer.record(n)
+	}
+
+	var prev []float64
+	for i := 0; i < maxIters; i++ {
+		n.activateStep()
+		if n.OutputIsOff() {
+			continue
 		}
+		state := n.neuronsState()
+		if prev != nil && statesDelta(prev, state) < tolerance {
+			return n.ReadOutputs(), nil
+		}
+		prev = state
+	}
+	if prev == nil {
+		return nil, ErrNetActivationAborted
+	}
+	return n.ReadOutputs(), ErrNetRelaxationNotConverged
+}
 
-		// For each neuron node, compute the sum of its incoming activation
-		for _, np := range n.all_nodes {
-			if np.IsNeuron() {
-				np.ActivationSum = 0.0 // reset activation value
-				np.IsActive = false // flag node disabled
-
-				// For each node's incoming connection, add the activity from the connection to the activesum
-				for _, link := range np.Incoming {
-					// Handle possible time delays
-					if !link.IsTimeDelayed {
-						add_amount = link.Weight * link.InNode.GetActiveOut()
-						//fmt.Printf("%f -> %f\n", link.Weight, (*link.InNode).GetActiveOut())
-						if link.InNode.IsActive || link.InNode.IsSensor() {
-							np.IsActive = true
-						}
-					} else {
-						add_amount = link.Weight * link.InNode.GetActiveOutTd()
+// Returns the activation values of all network neurons, the neurons not activated yet hold NaN
+func (n *Network) neuronsState() []float64 {
+	state := make([]float64, 0, len(n.all_nodes))
+	for _, np := range n.all_nodes {
+		if !np.IsNeuron() {
+			continue
+		}
+		if np.ActivationsCount == 0 {
+			state = append(state, math.NaN())
+		} else {
+			state = append(state, np.Activation)
+		}
+	}
+	return state
+}
+
+// Returns the current activation values of network outputs
+func (n *Network) ReadOutputs() []float64 {
+	outs := make([]float64, len(n.Outputs))
+	for i, o := range n.Outputs {
+		outs[i] = o.Activation
+	}
+	return outs
+}
+
+// Performs one activation tick of the network: computes the activation sum of each neuron from the outputs of its
+// incoming nodes and activates neurons which received signal from active node or sensor.
+func (n *Network) activateStep() {
+	if n.Tracer != nil {
+		n.Tracer.startTick()
+	}
+	// For each neuron node, compute the sum of its incoming activation
+	for _, np := range n.all_nodes {
+		if np.IsNeuron() {
+			np.ActivationSum = np.Bias // reset activation value to the node's bias
+			np.IsActive = false // flag node disabled
+
+			// For each node's incoming connection, add the activity from the connection to the activesum
+			for _, link := range np.Incoming {
+				//For adding to the activesum
+				add_amount := 0.0
+				// Handle possible time delays
+				if !link.IsTimeDelayed {
+					add_amount = link.Weight * link.InNode.GetActiveOut()
+					//fmt.Printf("%f -> %f\n", link.Weight, (*link.InNode).GetActiveOut())
+					if link.InNode.IsActive || link.InNode.IsSensor() {
+						np.IsActive = true
 					}
-					np.ActivationSum += add_amount
-				} // End {for} over incoming links
-			} // End if != SENSOR
-		}  // End {for} over all nodes
-
-		// Now activate all the neuron nodes off their incoming activation
-		for _, np := range n.all_nodes {
-			if np.IsNeuron() {
-				// Only activate if some active input came in
-				if np.IsActive {
-					// Keep a memory of activations for potential time delayed connections
-					np.saveActivations()
-
-					// Now run the net activation through an activation function
-					np.Activation = activate(np)
-
-					// Increment the activation_count
-					// First activation cannot be from nothing!!
-					np.ActivationsCount++
+				} else {
+					add_amount = link.Weight * link.InNode.GetActiveOutTd()
+				}
+				np.ActivationSum += add_amount
+				if n.Tracer != nil {
+					n.Tracer.addContribution(np, link, add_amount)
 				}
-				//fmt.Printf("Node: %s, activation sum: %f, active: %t\n", np, np.ActivationSum, np.IsActive)
+			} // End {for} over incoming links
+		} // End if != SENSOR
+	}  // End {for} over all nodes
+
+	// Now activate all the neuron nodes off their incoming activation
+	for _, np := range n.all_nodes {
+		if np.IsNeuron() {
+			// Only activate if some active input came in
+			if np.IsActive {
+				// Keep a memory of activations for potential time delayed connections
+				np.saveActivations()
+
+				// Now run the net activation through an activation function
+				np.Activation = activate(np)
+
+				// Increment the activation_count
+				// First activation cannot be from nothing!!
+				np.ActivationsCount++
 			}
+			//fmt.Printf("Node: %s, activation sum: %f, active: %t\n", np, np.ActivationSum, np.IsActive)
 		}
-		one_time = true
+	}
+}
+
+// Checks that number of provided inputs is equal to the number of network sensors
+func (n *Network) checkInputs(inputs []float64) error {
+	sensors := 0
+	for _, node := range n.Inputs {
+		if node.IsSensor() {
+			sensors++
+		}
+	}
+	if len(inputs) != sensors {
+		return errors.New(fmt.Sprintf("The number of inputs: %d is not equal to the number of sensors: %d",
+			len(inputs), sensors))
+	}
+	return nil
+}
+
+// Activates the net as continuous-time recurrent neural network (CTRNN) by integrating the state of each neuron over
+// one time step dt using forward Euler method. The state y of each neuron is governed by equation:
+// 	tau * dy/dt = -y + sum(w * o)
+// where tau is the neuron's time constant and o is the output of incoming node. The output of the neuron is computed
+// by applying activation function to the sum of its state and bias. All neurons are updated synchronously, i.e. new
+// states computed from the outputs of previous step.
+func (n *Network) ActivateCTRNN(dt float64) (bool, error) {
+	if dt <= 0 {
+		return false, errors.New(fmt.Sprintf("Wrong CTRNN integration time step: %f", dt))
+	}
+
+	if n.Tracer != nil {
+		n.Tracer.startTick()
+	}
+	// Compute the rate of state change for each neuron from the outputs of previous time step
+	for _, np := range n.all_nodes {
+		if np.IsNeuron() {
+			if np.TimeConstant <= 0 {
+				return false, errors.New(fmt.Sprintf("Wrong time constant of the neuron: %s", np))
+			}
+			input := 0.0
+			for _, link := range np.Incoming {
+				add_amount := link.Weight * link.InNode.GetActiveOut()
+				input += add_amount
+				if n.Tracer != nil {
+					n.Tracer.addContribution(np, link, add_amount)
+				}
+			}
+			np.ActivationSum = (input - np.ctrnnState) / np.TimeConstant
+		}
+	}
+
+	// Integrate the state and activate neurons
+	for _, np := range n.all_nodes {
+		if np.IsNeuron() {
+			np.ctrnnState += dt * np.ActivationSum
+			np.ActivationSum = np.ctrnnState + np.Bias
+
+			// Keep a memory of activations for potential time delayed connections
+			np.saveActivations()
+			np.Activation = activate(np)
+			np.ActivationsCount++
+		}
+	}
+	if n.Tracer != nil {
+		n.Tracer.record(n)
 	}
 	return true, nil
 }
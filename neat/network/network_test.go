@@ -2,6 +2,7 @@ package network
 
 import (
 	"testing"
+	"math"
 )
 
 func buildNetwork() *Network {
@@ -84,6 +85,189 @@ func TestNetwork_Activate(t *testing.T) {
 	}
 }
 
+// Tests that Network Activate returns error when outputs are disconnected from inputs
+func TestNetwork_ActivateDisconnected(t *testing.T) {
+	in := NewNNode(1, InputNeuron)
+	out := NewNNode(2, OutputNeuron)
+	netw := NewNetwork([]*NNode{in}, []*NNode{out}, []*NNode{in, out}, 0)
+
+	netw.LoadSensors([]float64{1.0})
+	res, err := netw.Activate()
+	if res {
+		t.Error("Disconnected network should not be activated")
+	}
+	if err != ErrNetActivationAborted {
+		t.Error("Wrong error returned", err)
+	}
+}
+
+// Tests Network Step
+func TestNetwork_Step(t *testing.T) {
+	netw := buildNetwork()
+
+	inputs := []float64{1.0, 2.0, 0.5}
+	outs, err := netw.Step(inputs)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(outs) != 2 {
+		t.Error("Wrong number of outputs", 2, len(outs))
+	}
+	// only one tick should be done
+	for _, node := range netw.AllNodes() {
+		if node.IsNeuron() && node.ActivationsCount > 1 {
+			t.Error("More than one tick performed", node)
+		}
+	}
+
+	if _, err = netw.Step(inputs[1:]); err == nil {
+		t.Error("Error expected for wrong number of inputs")
+	}
+}
+
+// Tests Network Relax
+func TestNetwork_Relax(t *testing.T) {
+	netw := buildNetwork()
+	inputs := []float64{1.0, 2.0, 0.5}
+
+	outs, err := netw.Relax(inputs, 10, 1e-10)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// compare with outputs of the network activated deeper than its depth
+	expected := buildNetwork()
+	expected.LoadSensors(inputs)
+	for i := 0; i < 5; i++ {
+		expected.Activate()
+	}
+	for i, v := range expected.ReadOutputs() {
+		if outs[i] != v {
+			t.Error("Wrong relaxed output", i, v, outs[i])
+		}
+	}
+
+	// the same results expected for relaxation of already activated network
+	outs2, err := netw.Relax(inputs, 10, 1e-10)
+	if err != nil {
+		t.Error(err)
+	}
+	for i := range outs {
+		if outs[i] != outs2[i] {
+			t.Error("Relaxation depends on the previous network state", outs[i], outs2[i])
+		}
+	}
+
+	// check that relaxation stops when not settled
+	if _, err = netw.Relax(inputs, 1, 1e-10); err != ErrNetActivationAborted && err != ErrNetRelaxationNotConverged {
+		t.Error("Wrong error returned for too short relaxation", err)
+	}
+}
+
+// Tests relaxation of network where the signal travels through chain of three hidden nodes placed out of order, while
+// the output is also directly connected with input, thus output is unchanged for two ticks before signal reaches it
+// through the chain.
+func TestNetwork_RelaxDeepChain(t *testing.T) {
+	all_nodes := []*NNode {
+		NewNNode(1, InputNeuron),
+		NewNNode(2, OutputNeuron),
+		NewNNode(3, HiddenNeuron),
+		NewNNode(4, HiddenNeuron),
+		NewNNode(5, HiddenNeuron),
+	}
+	// INPUT 1 -> HIDDEN 5 -> HIDDEN 4 -> HIDDEN 3 -> OUTPUT 2
+	all_nodes[4].AddIncoming(all_nodes[0], 5.0)
+	all_nodes[3].AddIncoming(all_nodes[4], -10.0)
+	all_nodes[2].AddIncoming(all_nodes[3], 10.0)
+	all_nodes[1].AddIncoming(all_nodes[2], -20.0)
+	// INPUT 1 -> OUTPUT 2
+	all_nodes[1].AddIncoming(all_nodes[0], 1.0)
+	netw := NewNetwork(all_nodes[0:1], all_nodes[1:2], all_nodes, 0)
+	inputs := []float64{1.0}
+
+	outs, err := netw.Relax(inputs, 10, 1e-10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// compare with outputs of the network activated deeper than its depth
+	expected := NewNetwork(all_nodes[0:1], all_nodes[1:2], all_nodes, 0)
+	expected.Flush()
+	expected.LoadSensors(inputs)
+	for i := 0; i < 6; i++ {
+		expected.Step(inputs)
+	}
+	if v := expected.ReadOutputs()[0]; math.Abs(outs[0] - v) > 1e-10 {
+		t.Error("Relaxation stopped before signal reached output through hidden nodes", outs[0], v)
+	}
+}
+
+// Tests that node bias applied during Network Activate
+func TestNetwork_ActivateWithBias(t *testing.T) {
+	in := NewNNode(1, InputNeuron)
+	out := NewNNode(2, OutputNeuron)
+	out.ActivationType = Sigmoid
+	out.Bias = 1.5
+	out.AddIncoming(in, 2.0)
+	netw := NewNetwork([]*NNode{in}, []*NNode{out}, []*NNode{in, out}, 0)
+
+	netw.LoadSensors([]float64{0.5})
+	res, err := netw.Activate()
+	if err != nil {
+		t.Error(err)
+	}
+	if !res {
+		t.Error("Failed to activate")
+	}
+	if out.ActivationSum != 2.5 {
+		t.Error("Bias was not added to the activation sum", 2.5, out.ActivationSum)
+	}
+}
+
+func TestNetwork_ActivateCTRNN(t *testing.T) {
+	in := NewNNode(1, InputNeuron)
+	out := NewNNode(2, OutputNeuron)
+	out.ActivationType = Sigmoid
+	out.TimeConstant = 0.5
+	out.Bias = 0.25
+	out.AddIncoming(in, 2.0)
+	netw := NewNetwork([]*NNode{in}, []*NNode{out}, []*NNode{in, out}, 0)
+
+	// the state should exponentially approach the input of neuron (2.0 * 1.0)
+	netw.LoadSensors([]float64{1.0})
+	dt, state := 0.1, 0.0
+	for i := 0; i < 10; i++ {
+		res, err := netw.ActivateCTRNN(dt)
+		if err != nil || !res {
+			t.Error("Failed to activate CTRNN", err)
+			return
+		}
+		state += dt / 0.5 * (2.0 - state)
+		if math.Abs(out.ActivationSum - (state + 0.25)) > 1e-9 {
+			t.Error("Wrong CTRNN output at step", i, state + 0.25, out.ActivationSum)
+		}
+	}
+
+	// check that state is reset by flush
+	netw.Flush()
+	netw.LoadSensors([]float64{1.0})
+	netw.ActivateCTRNN(dt)
+	if math.Abs(out.ActivationSum - (0.4 + 0.25)) > 1e-9 {
+		t.Error("CTRNN state was not flushed", 0.65, out.ActivationSum)
+	}
+
+	// check wrong parameters
+	if _, err := netw.ActivateCTRNN(0); err == nil {
+		t.Error("Error expected for zero time step")
+	}
+	out.TimeConstant = 0
+	if _, err := netw.ActivateCTRNN(dt); err == nil {
+		t.Error("Error expected for zero time constant")
+	}
+}
+
 // Test Network LoadSensors
 func TestNetwork_LoadSensors(t *testing.T) {
 	netw := buildNetwork()
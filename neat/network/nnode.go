@@ -33,6 +33,10 @@ type NNode struct {
 	ActivationsCount int32
 	// The activation sum
 	ActivationSum    float64
+	// The evolvable bias term added to the activation sum of the neuron (not used by sensors)
+	Bias             float64
+	// The evolvable time constant of the neuron used by continuous-time recurrent neural network (CTRNN) activation
+	TimeConstant     float64
 
 	// The list of all incoming connections
 	Incoming         []*Link
@@ -57,6 +61,9 @@ type NNode struct {
 	// This is necessary for a special recurrent case when the innode of a recurrent link is one time step ahead of the outnode.
 	// The innode then needs to send from TWO time steps ago
 	lastActivation2  float64
+
+	// The state (membrane potential) of the neuron integrated during CTRNN activation
+	ctrnnState       float64
 }
 
 // Creates new node with specified ID and neuron type associated (INPUT, HIDDEN, OUTPUT, BIAS)
@@ -72,16 +79,21 @@ func NewNNodeCopy(n *NNode, t *neat.Trait) *NNode {
 	node := newNode()
 	node.Id = n.Id
 	node.NeuronType = n.NeuronType
+	node.Bias = n.Bias
+	node.TimeConstant = n.TimeConstant
 	node.Trait = t
 	node.deriveTrait(t)
 	return node
 }
 
-// Read a NNode from specified Reader and applies corresponding trait to it from a list of traits provided
+// Read a NNode from specified Reader and applies corresponding trait to it from a list of traits provided.
+// The node bias and time constant are optional and will be read only if present after the neuron type.
 func ReadNNode(r io.Reader, traits []*neat.Trait) *NNode {
 	n := newNode()
 	var trait_id, node_type int
 	fmt.Fscanf(r, "%d %d %d %d ", &n.Id, &trait_id, &node_type, &n.NeuronType)
+	// read optional bias and time constant values
+	fmt.Fscanf(r, "%g %g ", &n.Bias, &n.TimeConstant)
 	if trait_id != 0 && traits != nil {
 		// find corresponding node trait from list
 		for _, t := range traits {
@@ -103,6 +115,7 @@ func newNode() *NNode {
 	return &NNode{
 		NeuronType:HiddenNeuron,
 		ActivationType:SigmoidSteepened,
+		TimeConstant:DefaultTimeConstant,
 		Incoming:make([]*Link, 0),
 		Outgoing:make([]*Link, 0),
 	}
@@ -184,6 +197,7 @@ func (n *NNode) Flushback() {
 	n.Activation = 0
 	n.lastActivation = 0
 	n.lastActivation2 = 0
+	n.ctrnnState = 0
 }
 
 // Verify flushing for debuginh
@@ -210,6 +224,13 @@ func (n *NNode) Write(w io.Writer) {
 		trait_id = n.Trait.Id
 	}
 	fmt.Fprintf(w, "%d %d %d %d", n.Id, trait_id, n.NodeType(), n.NeuronType)
+	if n.TimeConstant != DefaultTimeConstant {
+		// the time constant follows the bias
+		fmt.Fprintf(w, " %g %g", n.Bias, n.TimeConstant)
+	} else if n.Bias != 0 {
+		// write bias only when set to keep genomes without bias compatible with older readers
+		fmt.Fprintf(w, " %g", n.Bias)
+	}
 }
 
 // Find the greatest depth starting from this neuron at depth d
@@ -51,6 +51,50 @@ func TestWriteNNode(t *testing.T) {
 	}
 }
 
+// Tests NNode read/write with optional bias
+func TestNNode_ReadWriteBias(t *testing.T) {
+	node_str := "3 0 0 2 -0.75"
+	node := ReadNNode(strings.NewReader(node_str), nil)
+	if node.Bias != -0.75 {
+		t.Error("Wrong node bias read", -0.75, node.Bias)
+	}
+	if node.NeuronType != OutputNeuron {
+		t.Error("Wrong neuron type read", OutputNeuron, node.NeuronType)
+	}
+
+	out_buffer := bytes.NewBufferString("")
+	node.Write(out_buffer)
+	if out_buffer.String() != node_str {
+		t.Errorf("Node serialization failed. Expected: %s, but found %s", node_str, out_buffer)
+	}
+
+	// check that bias copied
+	copy_node := NewNNodeCopy(node, nil)
+	if copy_node.Bias != node.Bias {
+		t.Error("Bias was not copied", node.Bias, copy_node.Bias)
+	}
+}
+
+func TestNNode_ReadWriteTimeConstant(t *testing.T) {
+	node_str := "3 0 0 2 0 0.05"
+	node := ReadNNode(strings.NewReader(node_str), nil)
+	if node.TimeConstant != 0.05 {
+		t.Error("Wrong node time constant read", 0.05, node.TimeConstant)
+	}
+
+	out_buffer := bytes.NewBufferString("")
+	node.Write(out_buffer)
+	if out_buffer.String() != node_str {
+		t.Errorf("Node serialization failed. Expected: %s, but found %s", node_str, out_buffer)
+	}
+
+	// check that default time constant is set when absent
+	node = ReadNNode(strings.NewReader("3 0 0 2 -0.75"), nil)
+	if node.TimeConstant != DefaultTimeConstant {
+		t.Error("Wrong default time constant", DefaultTimeConstant, node.TimeConstant)
+	}
+}
+
 // Tests NNode SensorLoad
 func TestNNode_SensorLoad(t *testing.T) {
 	node := NewNNode(1, InputNeuron)
@@ -0,0 +1,243 @@
+package network
+
+import (
+	"io"
+	"fmt"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"errors"
+)
+
+// The contribution of one incoming link into the activation sum of the node
+type LinkContribution struct {
+	// The ID of the incoming node
+	InNodeId     int     `json:"in_node_id"`
+	// The weight of the link
+	Weight       float64 `json:"weight"`
+	// The value added to the activation sum, i.e. weight multiplied by the output of incoming node
+	Contribution float64 `json:"contribution"`
+	// The flag to indicate whether link is time delayed
+	TimeDelayed  bool    `json:"time_delayed"`
+}
+
+// The state of one node recorded after network activation
+type NodeTrace struct {
+	// The ID of the node
+	NodeId        int                `json:"node_id"`
+	// The type of the neuron (INPUT, HIDDEN, OUTPUT, BIAS)
+	NeuronType    NeuronType         `json:"neuron_type"`
+	// The activation sum of the node
+	ActivationSum float64            `json:"activation_sum"`
+	// The activation value of the node
+	Activation    float64            `json:"activation"`
+	// The contributions of incoming links
+	Incoming      []LinkContribution `json:"incoming,omitempty"`
+}
+
+// The trace of one network activation
+type ActivationTrace struct {
+	// The episode of activation
+	Episode int         `json:"episode"`
+	// The step of activation within episode
+	Step    int         `json:"step"`
+	// The states of network nodes after activation
+	Nodes   []NodeTrace `json:"nodes"`
+}
+
+// The activation tracer records the state of each network node after every activation call (Activate, Step, Relax and
+// ActivateCTRNN). The tracer should be assigned to the Network.Tracer field for recording to start and it has no
+// overhead when not assigned. The activations are grouped into episodes, e.g. one pole balancing run, and each new
+// episode should be started by StartEpisode call.
+type ActivationTracer struct {
+	// The recorded activation traces
+	Traces        []*ActivationTrace
+	// The maximal number of steps to record per episode, zero means unlimited
+	MaxSteps      int
+
+	// The current episode
+	episode       int
+	// The current step within episode
+	step          int
+	// The incoming link contributions collected during last activation tick
+	contributions map[*NNode][]LinkContribution
+}
+
+// Creates new activation tracer which records no more than maxSteps activations per episode (zero - unlimited)
+func NewActivationTracer(maxSteps int) *ActivationTracer {
+	return &ActivationTracer{
+		Traces:make([]*ActivationTrace, 0),
+		MaxSteps:maxSteps,
+		contributions:make(map[*NNode][]LinkContribution),
+	}
+}
+
+// Starts new episode of activations recording. The contributions collected for nodes of previous episode are dropped,
+// thus the tracer doesn't hold networks of previous episodes.
+func (t *ActivationTracer) StartEpisode() {
+	if len(t.Traces) > 0 || t.step > 0 {
+		t.episode++
+	}
+	t.step = 0
+	t.contributions = make(map[*NNode][]LinkContribution)
+}
+
+// Returns the number of recorded episodes
+func (t *ActivationTracer) EpisodesCount() int {
+	if len(t.Traces) == 0 {
+		return 0
+	}
+	return t.Traces[len(t.Traces) - 1].Episode + 1
+}
+
+// Returns recorded traces of given episode
+func (t *ActivationTracer) Episode(episode int) []*ActivationTrace {
+	traces := make([]*ActivationTrace, 0)
+	for _, tr := range t.Traces {
+		if tr.Episode == episode {
+			traces = append(traces, tr)
+		}
+	}
+	return traces
+}
+
+// Returns the timeline of given episode as map of node ID to the node activations over episode steps
+func (t *ActivationTracer) Timeline(episode int) map[int][]float64 {
+	timeline := make(map[int][]float64)
+	for _, tr := range t.Episode(episode) {
+		for _, nt := range tr.Nodes {
+			timeline[nt.NodeId] = append(timeline[nt.NodeId], nt.Activation)
+		}
+	}
+	return timeline
+}
+
+// Writes the timeline of given episode as CSV table where each row is an activation step and each column holds
+// activations of the particular node
+func (t *ActivationTracer) WriteTimeline(w io.Writer, episode int) error {
+	traces := t.Episode(episode)
+	if len(traces) == 0 {
+		return errors.New(fmt.Sprintf("No traces recorded for episode: %d", episode))
+	}
+	cw := csv.NewWriter(w)
+	header := []string{"step"}
+	for _, nt := range traces[0].Nodes {
+		header = append(header, fmt.Sprintf("node_%d", nt.NodeId))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, tr := range traces {
+		row := []string{strconv.Itoa(tr.Step)}
+		for _, nt := range tr.Nodes {
+			row = append(row, formatFloat(nt.Activation))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes all recorded traces as CSV with one row per incoming link of the node. The nodes without incoming links
+// (sensors) written as single row with empty link columns.
+func (t *ActivationTracer) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"episode", "step", "node_id", "neuron_type", "activation_sum", "activation",
+		"in_node_id", "weight", "contribution", "time_delayed"})
+	if err != nil {
+		return err
+	}
+	for _, tr := range t.Traces {
+		for _, nt := range tr.Nodes {
+			row := []string{strconv.Itoa(tr.Episode), strconv.Itoa(tr.Step), strconv.Itoa(nt.NodeId),
+				NeuronTypeName(nt.NeuronType), formatFloat(nt.ActivationSum), formatFloat(nt.Activation)}
+			if len(nt.Incoming) == 0 {
+				err = cw.Write(append(row, "", "", "", ""))
+			}
+			for _, lc := range nt.Incoming {
+				err = cw.Write(append(row, strconv.Itoa(lc.InNodeId), formatFloat(lc.Weight),
+					formatFloat(lc.Contribution), strconv.FormatBool(lc.TimeDelayed)))
+				if err != nil {
+					break
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Writes all recorded traces as JSON array
+func (t *ActivationTracer) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t.Traces)
+}
+
+// Resets the tracer dropping all recorded traces
+func (t *ActivationTracer) Reset() {
+	t.Traces = make([]*ActivationTrace, 0)
+	t.episode = 0
+	t.step = 0
+	t.contributions = make(map[*NNode][]LinkContribution)
+}
+
+// Stores contribution of incoming link into the activation sum of the node during current activation tick
+func (t *ActivationTracer) addContribution(node *NNode, link *Link, value float64) {
+	if t.isFull() {
+		return
+	}
+	t.contributions[node] = append(t.contributions[node], LinkContribution{
+		InNodeId:link.InNode.Id,
+		Weight:link.Weight,
+		Contribution:value,
+		TimeDelayed:link.IsTimeDelayed,
+	})
+}
+
+// Clears contributions collected during previous activation tick
+func (t *ActivationTracer) startTick() {
+	for node, lc := range t.contributions {
+		t.contributions[node] = lc[:0]
+	}
+}
+
+// Records the state of all network nodes after activation call
+func (t *ActivationTracer) record(n *Network) {
+	if t.isFull() {
+		return
+	}
+	trace := &ActivationTrace{
+		Episode:t.episode,
+		Step:t.step,
+		Nodes:make([]NodeTrace, len(n.all_nodes)),
+	}
+	for i, node := range n.all_nodes {
+		trace.Nodes[i] = NodeTrace{
+			NodeId:node.Id,
+			NeuronType:node.NeuronType,
+			ActivationSum:node.ActivationSum,
+			Activation:node.Activation,
+		}
+		if lc := t.contributions[node]; len(lc) > 0 {
+			trace.Nodes[i].Incoming = make([]LinkContribution, len(lc))
+			copy(trace.Nodes[i].Incoming, lc)
+		}
+	}
+	t.Traces = append(t.Traces, trace)
+	t.step++
+}
+
+// Returns true if the maximal number of steps recorded in current episode
+func (t *ActivationTracer) isFull() bool {
+	return t.MaxSteps > 0 && t.step >= t.MaxSteps
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
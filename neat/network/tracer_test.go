@@ -0,0 +1,133 @@
+package network
+
+import (
+	"testing"
+	"bytes"
+	"strings"
+	"encoding/json"
+)
+
+func TestActivationTracer_Record(t *testing.T) {
+	netw := buildNetwork()
+	tracer := NewActivationTracer(0)
+	netw.Tracer = tracer
+
+	inputs := []float64{1.0, 2.0, 0.5}
+	for episode := 0; episode < 2; episode++ {
+		tracer.StartEpisode()
+		netw.Flush()
+		for i := 0; i < 3; i++ {
+			netw.LoadSensors(inputs)
+			if res, err := netw.Activate(); !res {
+				t.Error("Failed to activate network", err)
+				return
+			}
+		}
+	}
+
+	if len(tracer.Traces) != 6 {
+		t.Error("Wrong number of traces recorded", 6, len(tracer.Traces))
+	}
+	if tracer.EpisodesCount() != 2 {
+		t.Error("Wrong number of episodes", 2, tracer.EpisodesCount())
+	}
+
+	// check recorded values of the last activation
+	last := tracer.Traces[len(tracer.Traces) - 1]
+	if last.Episode != 1 || last.Step != 2 {
+		t.Error("Wrong last trace episode/step", last.Episode, last.Step)
+	}
+	for i, node := range netw.AllNodes() {
+		nt := last.Nodes[i]
+		if nt.NodeId != node.Id || nt.Activation != node.Activation || nt.ActivationSum != node.ActivationSum {
+			t.Error("Wrong node trace", nt, node)
+		}
+		if len(nt.Incoming) != len(node.Incoming) {
+			t.Error("Wrong number of link contributions", len(node.Incoming), len(nt.Incoming))
+		}
+		sum := 0.0
+		for _, lc := range nt.Incoming {
+			sum += lc.Contribution
+		}
+		if node.IsNeuron() && sum != node.ActivationSum {
+			t.Error("Link contributions do not sum to the activation sum", node.ActivationSum, sum)
+		}
+	}
+
+	timeline := tracer.Timeline(0)
+	if len(timeline) != netw.NodeCount() {
+		t.Error("Wrong timeline size", netw.NodeCount(), len(timeline))
+	}
+	for id, acts := range timeline {
+		if len(acts) != 3 {
+			t.Error("Wrong timeline length for node", id, len(acts))
+		}
+	}
+}
+
+func TestActivationTracer_MaxSteps(t *testing.T) {
+	netw := buildNetwork()
+	netw.Tracer = NewActivationTracer(2)
+	netw.LoadSensors([]float64{1.0, 2.0, 0.5})
+	for i := 0; i < 5; i++ {
+		netw.Activate()
+	}
+	if len(netw.Tracer.Traces) != 2 {
+		t.Error("Wrong number of traces recorded", 2, len(netw.Tracer.Traces))
+	}
+	for node, lc := range netw.Tracer.contributions {
+		if len(lc) != 0 {
+			t.Error("Contributions collected after the limit of steps", node.Id, len(lc))
+		}
+	}
+
+	// the nodes of network traced in previous episode are not held
+	netw.Tracer.StartEpisode()
+	if len(netw.Tracer.contributions) != 0 {
+		t.Error("Contributions of previous episode kept", len(netw.Tracer.contributions))
+	}
+}
+
+func TestActivationTracer_Write(t *testing.T) {
+	netw := buildNetwork()
+	netw.Tracer = NewActivationTracer(0)
+	netw.LoadSensors([]float64{1.0, 2.0, 0.5})
+	netw.Activate()
+
+	// CSV - one row per incoming link or per sensor plus header
+	out_buf := bytes.NewBufferString("")
+	if err := netw.Tracer.WriteCSV(out_buf); err != nil {
+		t.Error(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out_buf.String()), "\n")
+	expected := 1 + 3 + netw.LinkCount()
+	if len(lines) != expected {
+		t.Error("Wrong number of CSV lines", expected, len(lines))
+	}
+
+	// JSON
+	out_buf = bytes.NewBufferString("")
+	if err := netw.Tracer.WriteJSON(out_buf); err != nil {
+		t.Error(err)
+	}
+	traces := make([]*ActivationTrace, 0)
+	if err := json.Unmarshal(out_buf.Bytes(), &traces); err != nil {
+		t.Error(err)
+	}
+	if len(traces) != 1 || len(traces[0].Nodes) != netw.NodeCount() {
+		t.Error("Wrong traces decoded from JSON", traces)
+	}
+
+	// Timeline
+	out_buf = bytes.NewBufferString("")
+	if err := netw.Tracer.WriteTimeline(out_buf, 0); err != nil {
+		t.Error(err)
+	}
+	lines = strings.Split(strings.TrimSpace(out_buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "step,node_1") {
+		t.Error("Wrong timeline written", lines)
+	}
+	if err := netw.Tracer.WriteTimeline(out_buf, 10); err == nil {
+		t.Error("Error expected for missing episode")
+	}
+}